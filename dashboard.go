@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"dmxapi-config/internal/api"
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/dashboard"
+	"dmxapi-config/internal/i18n"
+	"dmxapi-config/internal/support"
+)
+
+// runDashboard 执行 dashboard 命令：在全屏面板中查看和修改现有配置，返回进程退出码
+func runDashboard() int {
+	if jsonOutput {
+		// 面板占用标准输出，无法同时输出结果文档
+		err := errors.New(i18n.T("main.dashboard_json"))
+		fmt.Fprintln(os.Stderr, err)
+		result.addError(codeUsage, err)
+		return 2
+	}
+
+	existing := config.NewReader().ReadExistingConfig()
+	var source string
+	if existing != nil {
+		source = existing.URL
+	}
+	// 面板中新添加的模型按最近一次从当前网关刷新的模型目录判断 provider 类型
+	config.UseCatalog(config.LoadCatalog(source))
+	if existing != nil {
+		// 保留现有配置中的自定义请求头，命令行参数优先
+		customHeaders = existing.Headers.Merge(cliHeaders)
+	}
+	outcome, err := dashboard.Run(existing, dashboard.Options{
+		NewTester: func(url, apiKey string) *api.Tester {
+			// 重试提示会打乱全屏界面，由面板的测试状态代替
+			tester := newTester(url, apiKey)
+			tester.SetRetryNotifier(nil)
+			return tester
+		},
+		Force:      *flagForce,
+		Offline:    *flagOffline,
+		OnConflict: onConflict,
+		Headers:    customHeaders,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("main.dashboard_failed", err))
+		result.addError(codeDashboardFailed, err)
+		return 1
+	}
+
+	for _, t := range outcome.Tests {
+		result.addTest(t.Model, t.Result, t.Err)
+	}
+	if outcome.Saved {
+		result.setProviders(outcome.Config)
+		result.ConfigPath = outcome.ConfigPath
+		result.AuthPath = outcome.AuthPath
+		fmt.Println(i18n.T("dash.saved", outcome.ConfigPath))
+		// 与配置向导一致，保存本次结果供 support 命令打包
+		result.finish(0)
+		support.SaveLastRunReport(result)
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/i18n"
+	"dmxapi-config/internal/template"
+	"dmxapi-config/internal/ui"
+)
+
+// defaultExportName export 命令默认写入的模板文件名
+const defaultExportName = "dmxapi-template.yaml"
+
+// runExport 执行 export 命令：把当前 DMXAPI 配置导出为不含 API Key 的模板
+// dest 以 .json 结尾时输出 JSON，否则输出 YAML；dest 为 - 时写到标准输出，返回进程退出码
+func runExport(dest string) int {
+	if dest == "" {
+		dest = defaultExportName
+	}
+	toStdout := dest == "-" && !jsonOutput
+	if toStdout {
+		ui.SetOutput(os.Stderr)
+	} else {
+		ui.PrintBanner()
+	}
+
+	existing := config.NewReader().ReadExistingConfig()
+	if existing == nil {
+		err := errors.New(i18n.T("export.no_config"))
+		ui.PrintError(err.Error())
+		result.addError(codeExportFailed, err)
+		return 1
+	}
+
+	format := template.FormatOf(dest)
+	data, err := template.Export(existing, format)
+	if err != nil {
+		ui.PrintError(i18n.T("export.failed", err))
+		result.addError(codeExportFailed, err)
+		return 1
+	}
+
+	if toStdout {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if dest == "-" {
+		// JSON 输出模式下标准输出留给结果文档，模板写到默认文件
+		dest = defaultExportName
+	}
+	if abs, err := filepath.Abs(dest); err == nil {
+		dest = abs
+	}
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		err = fmt.Errorf(i18n.T("export.write_failed"), dest, err)
+		ui.PrintError(i18n.T("export.failed", err))
+		result.addError(codeExportFailed, err)
+		return 1
+	}
+
+	result.Template = dest
+	result.URL = existing.URL
+	ui.PrintSuccess(i18n.T("export.done", dest, len(existing.Models)))
+	ui.PrintInfo(i18n.T("export.key_removed"))
+	return 0
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/i18n"
+)
+
+// unlimitedQuotaUSD 网关对"无限额度"令牌返回的固定额度值（one-api / new-api 约定）
+const unlimitedQuotaUSD = 100000000
+
+// ErrBalanceUnavailable 网关未提供余额查询接口或查询失败
+var ErrBalanceUnavailable error = i18n.Error("account.balance_unavailable")
+
+// Balance 账户（令牌）额度信息，金额单位为美元
+type Balance struct {
+	TotalUSD     float64   // 总额度
+	UsedUSD      float64   // 已使用额度
+	RemainingUSD float64   // 剩余额度
+	Unlimited    bool      // 是否为无限额度令牌
+	ExpiresAt    time.Time // 令牌过期时间，零值表示永不过期
+}
+
+// lowBalanceUSD 低于该剩余额度时给出充值提醒
+const lowBalanceUSD = 1.0
+
+// IsLow 剩余额度是否过低
+func (b *Balance) IsLow() bool {
+	return !b.Unlimited && b.RemainingUSD < lowBalanceUSD
+}
+
+// String 返回适合在终端展示的额度描述
+func (b *Balance) String() string {
+	if b.Unlimited {
+		return i18n.T("account.unlimited", b.UsedUSD)
+	}
+	s := i18n.T("account.remaining", b.RemainingUSD, b.TotalUSD)
+	if !b.ExpiresAt.IsZero() {
+		s += i18n.T("account.expires", b.ExpiresAt.Format("2006-01-02"))
+	}
+	return s
+}
+
+// AccountClient 账户信息查询客户端
+// 使用 OpenAI 兼容的 /v1/dashboard/billing/* 接口（DMXAPI 网关以令牌维度返回额度）
+type AccountClient struct {
+	baseURL string
+	apiKey  string
+	headers map[string]string // 对所有 provider 生效的自定义请求头
+	client  *http.Client
+}
+
+// NewAccountClient 创建账户信息查询客户端
+func NewAccountClient(baseURL, apiKey string) *AccountClient {
+	return &AccountClient{
+		baseURL: config.NormalizeBaseURL(baseURL),
+		apiKey:  apiKey,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: debuglog.Transport(),
+		},
+	}
+}
+
+// AccountClient 创建使用相同网关地址、API Key 和自定义请求头的账户信息查询客户端
+func (t *Tester) AccountClient() *AccountClient {
+	a := NewAccountClient(t.baseURL, t.apiKey)
+	a.headers = t.headers.Global
+	return a
+}
+
+// subscriptionResponse /v1/dashboard/billing/subscription 响应结构
+type subscriptionResponse struct {
+	HardLimitUSD float64 `json:"hard_limit_usd"`
+	AccessUntil  int64   `json:"access_until"`
+}
+
+// usageResponse /v1/dashboard/billing/usage 响应结构
+type usageResponse struct {
+	TotalUsage float64 `json:"total_usage"` // 单位为美分
+}
+
+// QueryBalance 查询当前 API Key 的额度信息
+// 网关不支持、查询失败或 ctx 被取消时返回包装了 ErrBalanceUnavailable 的错误，调用方应降级处理
+func (a *AccountClient) QueryBalance(ctx context.Context) (*Balance, error) {
+	var sub subscriptionResponse
+	if err := a.getJSON(ctx, "/v1/dashboard/billing/subscription", &sub); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	usagePath := fmt.Sprintf("/v1/dashboard/billing/usage?start_date=%s&end_date=%s",
+		now.AddDate(0, 0, -99).Format("2006-01-02"), now.AddDate(0, 0, 1).Format("2006-01-02"))
+	var usage usageResponse
+	if err := a.getJSON(ctx, usagePath, &usage); err != nil {
+		return nil, err
+	}
+
+	balance := &Balance{
+		TotalUSD:  sub.HardLimitUSD,
+		UsedUSD:   usage.TotalUsage / 100,
+		Unlimited: sub.HardLimitUSD >= unlimitedQuotaUSD,
+	}
+	balance.RemainingUSD = balance.TotalUSD - balance.UsedUSD
+	if balance.RemainingUSD < 0 {
+		balance.RemainingUSD = 0
+	}
+	if sub.AccessUntil > 0 {
+		balance.ExpiresAt = time.Unix(sub.AccessUntil, 0)
+	}
+	return balance, nil
+}
+
+// getJSON 发送 GET 请求并解析 JSON 响应
+func (a *AccountClient) getJSON(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", a.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBalanceUnavailable, err)
+	}
+	setCustomHeaders(req, a.headers)
+	req.Header.Set("Authorization", "Bearer "+a.apiKey)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBalanceUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBalanceUnavailable, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: HTTP %d", ErrBalanceUnavailable, resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("%w: %s", ErrBalanceUnavailable, i18n.T("api.parse_failed_plain"))
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/mockserver"
+)
+
+// newMockAccount 启动模拟网关并返回指向它的 AccountClient
+func newMockAccount(t *testing.T, opts mockserver.Options) *AccountClient {
+	t.Helper()
+	opts.APIKey = testKey
+	srv := httptest.NewServer(mockserver.New(opts))
+	t.Cleanup(srv.Close)
+	return NewAccountClient(srv.URL, testKey)
+}
+
+func TestQueryBalance(t *testing.T) {
+	expires := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		opts      mockserver.Options
+		total     float64
+		used      float64
+		remaining float64
+		unlimited bool
+		low       bool
+	}{
+		{"limited", mockserver.Options{QuotaUSD: 50, UsedUSD: 12.34, ExpiresAt: expires}, 50, 12.34, 37.66, false, false},
+		{"low", mockserver.Options{QuotaUSD: 10, UsedUSD: 9.5}, 10, 9.5, 0.5, false, true},
+		{"overdrawn", mockserver.Options{QuotaUSD: 10, UsedUSD: 10.25}, 10, 10.25, 0, false, true},
+		// 无限额度令牌返回 1e8 美元的固定额度
+		{"unlimited", mockserver.Options{UsedUSD: 3}, 1e8, 3, 1e8 - 3, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := newMockAccount(t, tt.opts).QueryBalance(context.Background())
+			if err != nil {
+				t.Fatalf("QueryBalance error: %v", err)
+			}
+			for _, v := range []struct {
+				name      string
+				got, want float64
+			}{{"TotalUSD", b.TotalUSD, tt.total}, {"UsedUSD", b.UsedUSD, tt.used}, {"RemainingUSD", b.RemainingUSD, tt.remaining}} {
+				if math.Abs(v.got-v.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", v.name, v.got, v.want)
+				}
+			}
+			if b.Unlimited != tt.unlimited || b.IsLow() != tt.low {
+				t.Errorf("Unlimited = %v, IsLow = %v; want %v, %v", b.Unlimited, b.IsLow(), tt.unlimited, tt.low)
+			}
+			if !b.ExpiresAt.Equal(tt.opts.ExpiresAt) {
+				t.Errorf("ExpiresAt = %v, want %v", b.ExpiresAt, tt.opts.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestQueryBalanceErrors(t *testing.T) {
+	tests := []struct {
+		fault  mockserver.Fault
+		detail string
+	}{
+		{mockserver.FaultUnauthorized, "HTTP 401"},
+		{mockserver.FaultServerError, "HTTP 503"},
+		{mockserver.FaultBalance, "HTTP 402"},
+		{mockserver.FaultMalformed, ""},
+		{mockserver.FaultHTML, ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.fault), func(t *testing.T) {
+			_, err := newMockAccount(t, mockserver.Options{Fault: tt.fault}).QueryBalance(context.Background())
+			if !errors.Is(err, ErrBalanceUnavailable) {
+				t.Fatalf("error = %v, want ErrBalanceUnavailable", err)
+			}
+			if !strings.Contains(err.Error(), tt.detail) {
+				t.Errorf("error = %q, want it to mention %q", err, tt.detail)
+			}
+		})
+	}
+
+	// 只在查询用量时失败也要降级
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/dashboard/billing/subscription", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hard_limit_usd": 10}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	if _, err := NewAccountClient(srv.URL, testKey).QueryBalance(context.Background()); !errors.Is(err, ErrBalanceUnavailable) || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("usage 404 error = %v, want ErrBalanceUnavailable with HTTP 404", err)
+	}
+
+	gw := httptest.NewServer(mockserver.New(mockserver.Options{APIKey: testKey}))
+	t.Cleanup(gw.Close)
+	if _, err := NewAccountClient(gw.URL, "sk-wrongkey1234567890").QueryBalance(context.Background()); !errors.Is(err, ErrBalanceUnavailable) || !strings.Contains(err.Error(), "HTTP 401") {
+		t.Errorf("wrong key error = %v, want ErrBalanceUnavailable with HTTP 401", err)
+	}
+	srv.Close()
+	if _, err := NewAccountClient(srv.URL, testKey).QueryBalance(context.Background()); !errors.Is(err, ErrBalanceUnavailable) {
+		t.Errorf("unreachable gateway error = %v, want ErrBalanceUnavailable", err)
+	}
+}
+
+func TestQueryBalanceCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := newMockAccount(t, mockserver.Options{}).QueryBalance(ctx)
+	if !errors.Is(err, ErrBalanceUnavailable) || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Errorf("QueryBalance with cancelled context error = %v, want ErrBalanceUnavailable wrapping %v", err, context.Canceled)
+	}
+}
+
+func TestAccountClientHeaders(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{RequireHeaders: map[string]string{"X-Team": "ops"}})
+	if _, err := tester.AccountClient().QueryBalance(context.Background()); !errors.Is(err, ErrBalanceUnavailable) {
+		t.Fatalf("QueryBalance without the required header error = %v, want ErrBalanceUnavailable", err)
+	}
+
+	var headers config.Headers
+	headers.Set(nil, "X-Team", "ops")
+	tester.SetHeaders(headers)
+	if _, err := tester.AccountClient().QueryBalance(context.Background()); err != nil {
+		t.Errorf("QueryBalance with the tester's headers: %v", err)
+	}
+}
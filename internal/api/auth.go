@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"dmxapi-config/internal/config"
+)
+
+// anthropicVersion @ai-sdk/anthropic 发送的 anthropic-version 请求头取值
+const anthropicVersion = "2023-06-01"
+
+// AuthStyle 请求携带 API Key 的方式
+type AuthStyle int
+
+const (
+	AuthBearer    AuthStyle = iota // Authorization: Bearer（@ai-sdk/openai、@ai-sdk/openai-compatible）
+	AuthAnthropic                  // x-api-key + anthropic-version（@ai-sdk/anthropic）
+	AuthGoogle                     // x-goog-api-key（@ai-sdk/google）
+)
+
+// String 返回认证方式的名称，用于界面和结果文档
+func (s AuthStyle) String() string {
+	switch s {
+	case AuthAnthropic:
+		return "x-api-key"
+	case AuthGoogle:
+		return "x-goog-api-key"
+	}
+	return "bearer"
+}
+
+// NativeAuthStyle 返回 provider 对应的 SDK（见 config.GetProviderInfo）实际使用的认证方式
+func NativeAuthStyle(pType config.ProviderType) AuthStyle {
+	switch pType {
+	case config.ProviderAnthropic:
+		return AuthAnthropic
+	case config.ProviderGoogle:
+		return AuthGoogle
+	}
+	return AuthBearer
+}
+
+// setAuthHeaders 按认证方式设置请求头
+func (t *Tester) setAuthHeaders(req *http.Request, style AuthStyle) {
+	switch style {
+	case AuthAnthropic:
+		req.Header.Set("x-api-key", t.apiKey)
+		req.Header.Set("anthropic-version", anthropicVersion)
+	case AuthGoogle:
+		req.Header.Set("x-goog-api-key", t.apiKey)
+	default:
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+}
+
+// AuthProbe 一种认证方式的测试结果
+type AuthProbe struct {
+	Style  AuthStyle
+	Native bool  // 是否为 opencode 实际使用的认证方式
+	Err    error // 为 nil 表示网关接受该认证方式
+}
+
+// Accepted 网关是否接受该认证方式
+// 认证以外的错误（如模型不存在）说明请求已通过认证，同样视为接受
+func (p AuthProbe) Accepted() bool {
+	if p.Err == nil {
+		return true
+	}
+	te, ok := AsTestError(p.Err)
+	return ok && te.Code != ErrCodeAuthInvalid && te.StatusCode != 0
+}
+
+// ProbeAuth 分别使用 SDK 原生认证方式和 Bearer 方式测试模型，报告网关接受哪种方式
+// provider 原生方式就是 Bearer 时只测试一次
+func (t *Tester) ProbeAuth(ctx context.Context, model string, pType config.ProviderType) []AuthProbe {
+	native := NativeAuthStyle(pType)
+	styles := []AuthStyle{native}
+	if native != AuthBearer {
+		styles = append(styles, AuthBearer)
+	}
+	probes := make([]AuthProbe, 0, len(styles))
+	for _, style := range styles {
+		_, err := t.TestConnectionWithAuth(ctx, model, pType, style)
+		probes = append(probes, AuthProbe{Style: style, Native: style == native, Err: err})
+	}
+	return probes
+}
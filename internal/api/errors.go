@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"dmxapi-config/internal/i18n"
+)
+
+// ErrorCode 稳定的错误代码，便于脚本判断失败原因（取值保持向后兼容，不要随意修改）
+type ErrorCode string
+
+const (
+	ErrCodeAuthInvalid         ErrorCode = "auth_invalid"         // API Key 无效或已被禁用
+	ErrCodeInsufficientBalance ErrorCode = "insufficient_balance" // 账户余额 / 额度不足
+	ErrCodeModelNotFound       ErrorCode = "model_not_found"      // 模型不存在或当前分组未开通
+	ErrCodeRateLimited         ErrorCode = "rate_limited"         // 请求频率超限
+	ErrCodeUpstream            ErrorCode = "upstream_error"       // 网关或上游服务 5xx 错误
+	ErrCodeBadRequest          ErrorCode = "bad_request"          // 其他 4xx 请求错误
+	ErrCodeWrongBasePath       ErrorCode = "wrong_base_path"      // URL 指向网页而非 API（返回 HTML）
+	ErrCodeDNS                 ErrorCode = "dns_error"            // 域名解析失败
+	ErrCodeTLS                 ErrorCode = "tls_error"            // TLS 握手或证书校验失败
+	ErrCodeTimeout             ErrorCode = "timeout"              // 请求超时
+	ErrCodeNetwork             ErrorCode = "network_error"        // 其他网络错误（连接被拒绝等）
+	ErrCodeInvalidResponse     ErrorCode = "invalid_response"     // 响应格式无法识别
+	ErrCodeCancelled           ErrorCode = "cancelled"            // 用户中断（Ctrl+C）
+	ErrCodeUnknown             ErrorCode = "unknown"              // 未能归类的错误
+)
+
+// maxErrorBodyLen 错误信息中保留的响应体最大字符数
+const maxErrorBodyLen = 200
+
+// TestError 连接测试失败时返回的结构化错误
+type TestError struct {
+	Code       ErrorCode // 稳定的错误代码
+	StatusCode int       // HTTP 状态码，网络错误时为 0
+	Message    string    // 经过脱敏和截断的错误描述
+	Err        error     // 原始错误（可能为 nil）
+}
+
+// Error 实现 error 接口
+func (e *TestError) Error() string {
+	if e.StatusCode != 0 {
+		return i18n.T("api.error_status", e.StatusCode, e.Message)
+	}
+	return e.Message
+}
+
+// Unwrap 返回原始错误，支持 errors.Is / errors.As
+func (e *TestError) Unwrap() error {
+	return e.Err
+}
+
+// Hint 返回该错误的处理建议（按当前界面语言）
+func (e *TestError) Hint() string {
+	key := "hint." + string(e.Code)
+	if hint := i18n.T(key); hint != key {
+		return hint
+	}
+	return i18n.T("hint." + string(ErrCodeUnknown))
+}
+
+// AsTestError 从错误链中提取 *TestError
+func AsTestError(err error) (*TestError, bool) {
+	var te *TestError
+	if errors.As(err, &te) {
+		return te, true
+	}
+	return nil, false
+}
+
+// classifyHTTPError 根据状态码和响应体归类 HTTP 错误
+// apiMessage 为从 JSON 错误结构中解析出的错误信息（可能为空）
+func (t *Tester) classifyHTTPError(statusCode int, body []byte, apiMessage string) *TestError {
+	message := apiMessage
+	if message == "" {
+		message = string(body)
+	}
+	message = t.sanitize(message)
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+
+	lower := strings.ToLower(apiMessage + " " + string(body))
+	code := ErrCodeUnknown
+	switch {
+	case looksLikeHTML(body):
+		code = ErrCodeWrongBasePath
+		message = i18n.T("api.html_response")
+	case statusCode == http.StatusPaymentRequired || containsAny(lower, balanceKeywords):
+		code = ErrCodeInsufficientBalance
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		code = ErrCodeAuthInvalid
+	case statusCode == http.StatusNotFound || containsAny(lower, modelKeywords):
+		code = ErrCodeModelNotFound
+	case statusCode == http.StatusTooManyRequests:
+		code = ErrCodeRateLimited
+	case statusCode >= 500:
+		code = ErrCodeUpstream
+	case statusCode >= 400:
+		code = ErrCodeBadRequest
+	}
+
+	return &TestError{Code: code, StatusCode: statusCode, Message: message}
+}
+
+// classifyTransportError 归类发送请求阶段的网络错误（DNS、TLS、超时等）
+func (t *Tester) classifyTransportError(err error) *TestError {
+	code := ErrCodeNetwork
+
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCertErr x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return &TestError{Code: ErrCodeCancelled, Message: i18n.T("api.cancelled"), Err: err}
+	case errors.As(err, &dnsErr):
+		code = ErrCodeDNS
+	case errors.As(err, &certErr), errors.As(err, &unknownAuthErr), errors.As(err, &hostnameErr),
+		errors.As(err, &invalidCertErr), errors.As(err, &recordErr):
+		code = ErrCodeTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return &TestError{Code: ErrCodeTimeout, Message: i18n.T("api.timeout", t.timeout), Err: err}
+	}
+
+	return &TestError{
+		Code:    code,
+		Message: t.sanitize(i18n.T("api.send_failed", err)),
+		Err:     err,
+	}
+}
+
+// invalidResponse 构造响应格式无效的错误
+func (t *Tester) invalidResponse(body []byte, message string) *TestError {
+	if looksLikeHTML(body) {
+		return &TestError{Code: ErrCodeWrongBasePath, Message: i18n.T("api.html_response")}
+	}
+	return &TestError{Code: ErrCodeInvalidResponse, Message: t.sanitize(message)}
+}
+
+// balanceKeywords 余额不足的常见错误信息片段（含 one-api / new-api 网关的中文提示）
+var balanceKeywords = []string{
+	"insufficient_quota", "insufficient balance", "insufficient_user_quota",
+	"quota exceeded", "额度不足", "余额不足", "额度已用尽",
+}
+
+// modelKeywords 模型不存在或未开通的常见错误信息片段
+var modelKeywords = []string{
+	"model_not_found", "model not found", "does not exist", "no available channel",
+	"无可用渠道", "模型不存在", "not supported model",
+}
+
+// apiKeyPattern 匹配错误信息中可能回显的 sk- 形式的密钥
+var apiKeyPattern = regexp.MustCompile(`sk-[A-Za-z0-9_\-]{8,}`)
+
+// containsAny 判断 s 是否包含任意一个关键词
+func containsAny(s string, keywords []string) bool {
+	for _, k := range keywords {
+		if strings.Contains(s, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikeHTML 判断响应体是否为 HTML 页面（通常说明 URL 填写成了网页地址）
+func looksLikeHTML(body []byte) bool {
+	trimmed := strings.ToLower(strings.TrimSpace(string(body)))
+	return strings.HasPrefix(trimmed, "<!doctype html") || strings.HasPrefix(trimmed, "<html")
+}
+
+// sanitize 脱敏并截断错误信息：移除 API Key、合并空白字符、限制长度
+func (t *Tester) sanitize(s string) string {
+	if t.apiKey != "" {
+		s = strings.ReplaceAll(s, t.apiKey, "sk-***")
+	}
+	s = apiKeyPattern.ReplaceAllString(s, "sk-***")
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) > maxErrorBodyLen {
+		runes := []rune(s)
+		s = string(runes[:maxErrorBodyLen]) + "..."
+	}
+	return s
+}
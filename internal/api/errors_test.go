@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"unicode/utf8"
+
+	"dmxapi-config/internal/i18n"
+)
+
+func TestClassifyHTTPError(t *testing.T) {
+	tester := NewTester("https://www.dmxapi.cn", testKey)
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		apiMessage string
+		want       ErrorCode
+	}{
+		// HTML 页面优先于状态码判断
+		{"html 200", 200, "<!DOCTYPE html><html></html>", "", ErrCodeWrongBasePath},
+		{"html 404", 404, "  <html><body>Not Found</body></html>", "", ErrCodeWrongBasePath},
+		{"html 401", 401, "<HTML>login</HTML>", "", ErrCodeWrongBasePath},
+		// 余额不足优先于 401 / 403 和 404
+		{"402", 402, `{"error":{"message":"payment required"}}`, "payment required", ErrCodeInsufficientBalance},
+		{"403 quota", 403, `{"error":{"message":"insufficient_user_quota"}}`, "insufficient_user_quota", ErrCodeInsufficientBalance},
+		{"429 quota", 429, "", "You exceeded your current quota: quota exceeded", ErrCodeInsufficientBalance},
+		{"zh balance", 400, `{"error":{"message":"该令牌额度不足"}}`, "", ErrCodeInsufficientBalance},
+		// 401 / 403 优先于模型关键词
+		{"401", 401, `{"error":{"message":"invalid api key"}}`, "invalid api key", ErrCodeAuthInvalid},
+		{"403 model", 403, "", "model_not_found for this token", ErrCodeAuthInvalid},
+		{"404", 404, "", "not found", ErrCodeModelNotFound},
+		{"400 model", 400, "", "The model `gpt-55` does not exist", ErrCodeModelNotFound},
+		{"503 channel", 503, "", "当前分组无可用渠道", ErrCodeModelNotFound},
+		{"429", 429, "", "rate limit exceeded", ErrCodeRateLimited},
+		{"500", 500, "internal error", "", ErrCodeUpstream},
+		{"503", 503, "", "upstream service unavailable", ErrCodeUpstream},
+		{"400", 400, "", "invalid request body", ErrCodeBadRequest},
+		{"422", 422, "", "", ErrCodeBadRequest},
+		{"3xx", 302, "", "", ErrCodeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := tester.classifyHTTPError(tt.status, []byte(tt.body), tt.apiMessage)
+			if te.Code != tt.want || te.StatusCode != tt.status {
+				t.Fatalf("classifyHTTPError(%d, %q, %q) = %s (%d), want %s", tt.status, tt.body, tt.apiMessage, te.Code, te.StatusCode, tt.want)
+			}
+			if hint := te.Hint(); hint != i18n.T("hint."+string(tt.want)) {
+				t.Errorf("Hint() = %q, want the %s hint", hint, tt.want)
+			}
+			if te.Message == "" {
+				t.Error("empty message")
+			}
+		})
+	}
+}
+
+func TestClassifyHTTPErrorMessage(t *testing.T) {
+	tester := NewTester("https://www.dmxapi.cn", testKey)
+	tests := []struct {
+		body, apiMessage, want string
+	}{
+		{`{"error":{"message":"bad model"}}`, "bad model", "bad model"},
+		{"plain\n  text  body", "", "plain text body"},
+		{"", "", "Too Many Requests"},
+		{"<!doctype html>", "", i18n.T("api.html_response")},
+	}
+	for _, tt := range tests {
+		status := 429
+		if te := tester.classifyHTTPError(status, []byte(tt.body), tt.apiMessage); te.Message != tt.want {
+			t.Errorf("message for %q / %q = %q, want %q", tt.body, tt.apiMessage, te.Message, tt.want)
+		}
+	}
+}
+
+func TestClassifyTransportError(t *testing.T) {
+	tester := NewTester("https://www.dmxapi.cn", testKey)
+	wrap := func(err error) error {
+		return &url.Error{Op: "Post", URL: "https://www.dmxapi.cn/v1/messages", Err: err}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"cancelled", wrap(context.Canceled), ErrCodeCancelled},
+		{"deadline", wrap(context.DeadlineExceeded), ErrCodeTimeout},
+		{"net timeout", wrap(&net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}), ErrCodeTimeout},
+		{"dns", wrap(&net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "www.dmxapi.cm", IsNotFound: true}}), ErrCodeDNS},
+		{"unknown authority", wrap(x509.UnknownAuthorityError{}), ErrCodeTLS},
+		{"hostname", wrap(x509.HostnameError{Certificate: &x509.Certificate{}, Host: "dmxapi.cn"}), ErrCodeTLS},
+		{"refused", wrap(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}), ErrCodeNetwork},
+		{"other", errors.New("boom"), ErrCodeNetwork},
+	}
+	for _, tt := range tests {
+		te := tester.classifyTransportError(tt.err)
+		if te.Code != tt.want {
+			t.Errorf("%s: code = %s, want %s", tt.name, te.Code, tt.want)
+		}
+		if !errors.Is(te, tt.err) {
+			t.Errorf("%s: original error not wrapped", tt.name)
+		}
+		if te.StatusCode != 0 {
+			t.Errorf("%s: StatusCode = %d", tt.name, te.StatusCode)
+		}
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	const customKey = "team-key-0123456789"
+	tester := NewTester("https://www.dmxapi.cn", customKey)
+	long := strings.Repeat("错", maxErrorBodyLen+10)
+	tests := []struct {
+		name, in, want string
+	}{
+		{"own key", "invalid key " + customKey, "invalid key sk-***"},
+		{"sk token", "Incorrect API key provided: sk-abcdEFGH1234_-xy", "Incorrect API key provided: sk-***"},
+		{"short sk", "see sk-123", "see sk-123"},
+		{"whitespace", "  line one\n\tline two  ", "line one line two"},
+		{"exact limit", strings.Repeat("a", maxErrorBodyLen), strings.Repeat("a", maxErrorBodyLen)},
+		{"multibyte truncation", long, strings.Repeat("错", maxErrorBodyLen) + "..."},
+		{"key removed before truncation", strings.Repeat("x", maxErrorBodyLen-5) + " " + customKey, strings.Repeat("x", maxErrorBodyLen-5) + " sk-*..."},
+	}
+	for _, tt := range tests {
+		got := tester.sanitize(tt.in)
+		if got != tt.want {
+			t.Errorf("%s: sanitize = %q, want %q", tt.name, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("%s: sanitize returned invalid UTF-8", tt.name)
+		}
+		if strings.Contains(got, customKey) {
+			t.Errorf("%s: key not removed", tt.name)
+		}
+	}
+
+	// 网络错误的描述同样脱敏
+	te := tester.classifyTransportError(fmt.Errorf("dial https://proxy/?key=%s: refused", customKey))
+	if strings.Contains(te.Message, customKey) {
+		t.Errorf("transport error message contains the key: %q", te.Message)
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"dmxapi-config/internal/i18n"
+)
+
+// modelsResponse /v1/models 响应结构（OpenAI 兼容格式）
+type modelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// ListModels 查询网关 /v1/models 返回的可用模型 ID（按名称排序）
+// 请求失败时返回 *TestError，调用方可以据此降级到内置模型列表
+func (t *Tester) ListModels(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", t.baseURL+"/v1/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("common.create_request_failed"), err)
+	}
+	setCustomHeaders(req, t.headers.Global)
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, t.classifyTransportError(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, t.classifyTransportError(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp ChatResponse
+		var apiMessage string
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+			apiMessage = errResp.Error.Message
+		}
+		return nil, t.classifyHTTPError(resp.StatusCode, body, apiMessage)
+	}
+
+	var parsed modelsResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, t.invalidResponse(body, i18n.T("api.parse_failed_plain"))
+	}
+	models := make([]string, 0, len(parsed.Data))
+	for _, m := range parsed.Data {
+		if m.ID != "" {
+			models = append(models, m.ID)
+		}
+	}
+	if len(models) == 0 {
+		return nil, t.invalidResponse(body, i18n.T("api.models_empty"))
+	}
+	sort.Strings(models)
+	return models, nil
+}
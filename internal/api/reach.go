@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"dmxapi-config/internal/i18n"
+)
+
+// reachTimeout 可达性检查的超时时间（远短于连接测试，避免在输入 URL 后长时间等待）
+const reachTimeout = 10 * time.Second
+
+// CheckReachable 在输入 API Key 之前检查网关地址：DNS、TLS 和连接是否正常，以及地址是否指向 API
+// 请求 /v1/models（不带 API Key），任何非 404 的 API 响应（包括 401）都视为可达
+func (t *Tester) CheckReachable(ctx context.Context) error {
+	timeout := min(reachTimeout, t.timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", t.baseURL+"/v1/models", nil)
+	if err != nil {
+		return &TestError{Code: ErrCodeNetwork, Message: t.sanitize(err.Error()), Err: err}
+	}
+	setCustomHeaders(req, t.headers.Global)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		te := t.classifyTransportError(err)
+		if te.Code == ErrCodeTimeout {
+			te.Message = i18n.T("api.timeout", timeout)
+		}
+		return te
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if looksLikeHTML(body) || strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return &TestError{Code: ErrCodeWrongBasePath, StatusCode: resp.StatusCode, Message: i18n.T("api.html_response")}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return &TestError{Code: ErrCodeWrongBasePath, StatusCode: resp.StatusCode, Message: i18n.T("api.models_path_not_found", t.baseURL+"/v1/models")}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+
+	"dmxapi-config/internal/config"
+)
+
+// probeReasoningPrompt 推理探测使用的问题（需要简单推理，促使模型输出思考内容）
+const probeReasoningPrompt = "Which is larger, 9.11 or 9.9? Answer briefly."
+
+// probeThinkingBudget 开启思考时的预算 token 数（Anthropic 要求至少 1024）
+const probeThinkingBudget = 1024
+
+// ReasoningSupport 模型是否返回推理（思考）内容
+type ReasoningSupport int
+
+const (
+	ReasoningUnknown   ReasoningSupport = iota // 探测失败（网络、认证、限流等），无法判断
+	ReasoningSupported                         // 返回了思考内容或推理 token
+	ReasoningNone                              // 正常返回但没有推理内容，或网关拒绝了思考参数
+)
+
+// String 返回结果文档中使用的名称
+func (s ReasoningSupport) String() string {
+	switch s {
+	case ReasoningSupported:
+		return "supported"
+	case ReasoningNone:
+		return "none"
+	}
+	return "unknown"
+}
+
+// ReasoningProbe 单个模型的推理输出探测结果
+type ReasoningProbe struct {
+	Model    string
+	Provider config.ProviderType
+	Support  ReasoningSupport
+	Tokens   int   // 响应中报告的推理 token 数，接口不提供时为 0
+	Err      error // Support 为 ReasoningNone 时可能为网关拒绝思考参数的原因，为 ReasoningUnknown 时为探测失败的原因
+}
+
+// Reasoning 按探测结果返回写入配置的 reasoning 字段，known 为 false 时不应写入
+func (p ReasoningProbe) Reasoning() (reasoning, known bool) {
+	switch p.Support {
+	case ReasoningSupported:
+		return true, true
+	case ReasoningNone:
+		return false, true
+	}
+	return false, false
+}
+
+// ProbeReasoning 开启思考后发送一个简单问题，检查响应中是否带有推理内容或推理 token
+// 各接口开启方式：Anthropic thinking、Gemini thinkingConfig.includeThoughts、Responses reasoning.summary；
+// Chat Completions 没有统一的开关，检查 reasoning_content 字段和 reasoning_tokens 用量
+func (t *Tester) ProbeReasoning(ctx context.Context, model string, pType config.ProviderType) ReasoningProbe {
+	t.auth = NativeAuthStyle(pType)
+	t.provider = pType
+	t.attempts = 0
+
+	var body []byte
+	var err error
+	switch pType {
+	case config.ProviderAnthropic:
+		body, err = t.sendAnthropic(ctx, map[string]interface{}{
+			"model":      model,
+			"max_tokens": probeThinkingBudget * 2,
+			"thinking":   map[string]interface{}{"type": "enabled", "budget_tokens": probeThinkingBudget},
+			"messages":   []Message{{Role: "user", Content: probeReasoningPrompt}},
+		})
+	case config.ProviderGoogle:
+		body, err = t.sendGoogle(ctx, model, map[string]interface{}{
+			"contents": []GeminiContent{{Parts: []GeminiPart{{Text: probeReasoningPrompt}}}},
+			"generationConfig": map[string]interface{}{
+				"thinkingConfig": map[string]interface{}{"includeThoughts": true, "thinkingBudget": probeThinkingBudget},
+			},
+		})
+	case config.ProviderOpenAIResponses:
+		body, err = t.sendOpenAIResponses(ctx, map[string]interface{}{
+			"model":     model,
+			"input":     probeReasoningPrompt,
+			"reasoning": map[string]string{"effort": "low", "summary": "auto"},
+		})
+	default:
+		body, err = t.sendOpenAIChat(ctx, ChatRequest{
+			Model:    model,
+			Messages: []Message{{Role: "user", Content: probeReasoningPrompt}},
+		})
+	}
+
+	probe := ReasoningProbe{Model: model, Provider: pType, Err: err}
+	if err != nil {
+		probe.Support = reasoningSupportOf(err)
+		return probe
+	}
+	found, tokens := reasoningIn(pType, body)
+	probe.Tokens = tokens
+	probe.Support = ReasoningNone
+	if found || tokens > 0 {
+		probe.Support = ReasoningSupported
+	}
+	return probe
+}
+
+// reasoningSupportOf 根据探测请求的错误判断是网关不接受思考参数，还是探测本身失败
+func reasoningSupportOf(err error) ReasoningSupport {
+	if te, ok := AsTestError(err); ok && te.Code == ErrCodeBadRequest {
+		return ReasoningNone
+	}
+	return ReasoningUnknown
+}
+
+// reasoningIn 从响应中查找思考内容和推理 token 数
+func reasoningIn(pType config.ProviderType, body []byte) (found bool, tokens int) {
+	switch pType {
+	case config.ProviderAnthropic:
+		var resp struct {
+			Content []struct {
+				Type string `json:"type"`
+			} `json:"content"`
+		}
+		json.Unmarshal(body, &resp)
+		for _, c := range resp.Content {
+			if c.Type == "thinking" || c.Type == "redacted_thinking" {
+				found = true
+			}
+		}
+	case config.ProviderGoogle:
+		var resp struct {
+			Candidates []struct {
+				Content struct {
+					Parts []struct {
+						Thought bool `json:"thought"`
+					} `json:"parts"`
+				} `json:"content"`
+			} `json:"candidates"`
+			UsageMetadata struct {
+				ThoughtsTokenCount int `json:"thoughtsTokenCount"`
+			} `json:"usageMetadata"`
+		}
+		json.Unmarshal(body, &resp)
+		for _, c := range resp.Candidates {
+			for _, p := range c.Content.Parts {
+				found = found || p.Thought
+			}
+		}
+		tokens = resp.UsageMetadata.ThoughtsTokenCount
+	case config.ProviderOpenAIResponses:
+		var resp struct {
+			Output []struct {
+				Type string `json:"type"`
+			} `json:"output"`
+			Usage struct {
+				OutputTokensDetails struct {
+					ReasoningTokens int `json:"reasoning_tokens"`
+				} `json:"output_tokens_details"`
+			} `json:"usage"`
+		}
+		json.Unmarshal(body, &resp)
+		for _, o := range resp.Output {
+			found = found || o.Type == "reasoning"
+		}
+		tokens = resp.Usage.OutputTokensDetails.ReasoningTokens
+	default:
+		var resp struct {
+			Choices []struct {
+				Message struct {
+					ReasoningContent string `json:"reasoning_content"`
+					Reasoning        string `json:"reasoning"`
+				} `json:"message"`
+			} `json:"choices"`
+			Usage struct {
+				CompletionTokensDetails struct {
+					ReasoningTokens int `json:"reasoning_tokens"`
+				} `json:"completion_tokens_details"`
+			} `json:"usage"`
+		}
+		json.Unmarshal(body, &resp)
+		for _, c := range resp.Choices {
+			found = found || c.Message.ReasoningContent != "" || c.Message.Reasoning != ""
+		}
+		tokens = resp.Usage.CompletionTokensDetails.ReasoningTokens
+	}
+	return found, tokens
+}
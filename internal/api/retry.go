@@ -0,0 +1,105 @@
+package api
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy 请求重试策略
+type RetryPolicy struct {
+	MaxRetries int           // 最大重试次数（不含首次请求），0 表示不重试
+	BaseDelay  time.Duration // 首次重试的基础等待时间，之后按指数增长
+	MaxDelay   time.Duration // 单次等待时间上限（同样约束 Retry-After）
+}
+
+// DefaultRetryPolicy 返回默认重试策略：最多重试 2 次，等待 1s 起步、上限 10s
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 2,
+		BaseDelay:  time.Second,
+		MaxDelay:   10 * time.Second,
+	}
+}
+
+// RetryNotifier 每次重试前的回调，attempt 为即将进行的第几次重试（从 1 开始）
+type RetryNotifier func(attempt int, delay time.Duration, reason string)
+
+// retryableStatus 判断状态码是否值得重试（限流与网关类临时错误）
+func retryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryableError 判断网络错误是否值得重试
+// DNS 和 TLS 错误通常是配置问题，重试没有意义
+func retryableError(te *TestError) bool {
+	return te.Code == ErrCodeTimeout || te.Code == ErrCodeNetwork
+}
+
+// backoff 计算第 attempt 次重试（从 1 开始）的等待时间：指数退避 + 抖动
+// 抖动取 [delay/2, delay) 区间，避免多个客户端同时重试
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << uint(attempt-1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// retryAfterHeaders 服务端建议等待时间的响应头，按优先级排列
+var retryAfterHeaders = []string{
+	"Retry-After",
+	"X-RateLimit-Reset-Requests",
+	"Anthropic-RateLimit-Requests-Reset",
+	"X-RateLimit-Reset",
+}
+
+// retryAfter 从响应头解析服务端建议的等待时间，无法解析时返回 0
+// 兼容秒数、Go duration（如 "6m0s"）、Unix 时间戳、RFC3339 和 HTTP 日期格式
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	for _, name := range retryAfterHeaders {
+		v := strings.TrimSpace(header.Get(name))
+		if v == "" {
+			continue
+		}
+		if d := parseRetryValue(v, now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// parseRetryValue 解析单个等待时间值
+func parseRetryValue(v string, now time.Time) time.Duration {
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		// 数值过大时视为 Unix 时间戳
+		if secs > 1e9 {
+			return time.Unix(int64(secs), 0).Sub(now)
+		}
+		return time.Duration(secs * float64(time.Second))
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return d
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.Sub(now)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   time.Duration
+	}{
+		{"seconds", map[string]string{"Retry-After": "3"}, 3 * time.Second},
+		{"fractional seconds", map[string]string{"Retry-After": "1.5"}, 1500 * time.Millisecond},
+		{"go duration", map[string]string{"X-RateLimit-Reset-Requests": "6m0s"}, 6 * time.Minute},
+		{"short go duration", map[string]string{"X-RateLimit-Reset-Requests": "250ms"}, 250 * time.Millisecond},
+		{"unix timestamp", map[string]string{"X-RateLimit-Reset": strconv.FormatInt(now.Add(20*time.Second).Unix(), 10)}, 20 * time.Second},
+		{"rfc3339", map[string]string{"Anthropic-RateLimit-Requests-Reset": now.Add(45 * time.Second).Format(time.RFC3339)}, 45 * time.Second},
+		{"http date", map[string]string{"Retry-After": now.Add(2 * time.Minute).Format(http.TimeFormat)}, 2 * time.Minute},
+		{"padded", map[string]string{"Retry-After": "  7 "}, 7 * time.Second},
+		{"none", nil, 0},
+		{"zero", map[string]string{"Retry-After": "0"}, 0},
+		{"negative", map[string]string{"Retry-After": "-5"}, 0},
+		{"past unix timestamp", map[string]string{"X-RateLimit-Reset": strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)}, 0},
+		{"past rfc3339", map[string]string{"Retry-After": now.Add(-time.Hour).Format(time.RFC3339)}, 0},
+		{"past http date", map[string]string{"Retry-After": now.Add(-time.Hour).Format(http.TimeFormat)}, 0},
+		{"garbage", map[string]string{"Retry-After": "soon"}, 0},
+		// 优先级高的响应头无法解析时使用下一个
+		{"fallback header", map[string]string{"Retry-After": "soon", "X-RateLimit-Reset": "4"}, 4 * time.Second},
+		{"priority", map[string]string{"Retry-After": "2", "X-RateLimit-Reset-Requests": "9s"}, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		for k, v := range tt.header {
+			h.Set(k, v)
+		}
+		if got := retryAfter(h, now); got != tt.want {
+			t.Errorf("%s: retryAfter = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBackoffBounds(t *testing.T) {
+	policies := []RetryPolicy{
+		DefaultRetryPolicy(),
+		{MaxRetries: 5, BaseDelay: time.Millisecond, MaxDelay: 50 * time.Millisecond},
+		{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: time.Second},
+		{MaxRetries: 5, BaseDelay: 0, MaxDelay: time.Second},
+		{MaxRetries: 5, BaseDelay: time.Nanosecond, MaxDelay: time.Nanosecond},
+	}
+	for _, p := range policies {
+		for attempt := 1; attempt <= 70; attempt++ {
+			for i := 0; i < 20; i++ {
+				if d := p.backoff(attempt); d < 0 || d > p.MaxDelay {
+					t.Fatalf("%+v: backoff(%d) = %s, want within [0, %s]", p, attempt, d, p.MaxDelay)
+				}
+			}
+		}
+	}
+
+	// 未达到上限前按指数增长，抖动取 [delay/2, delay)
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Hour}
+	for attempt, base := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond} {
+		for i := 0; i < 20; i++ {
+			if d := p.backoff(attempt); d < base/2 || d >= base {
+				t.Errorf("backoff(%d) = %s, want within [%s, %s)", attempt, d, base/2, base)
+			}
+		}
+	}
+}
+
+// newStatusServer 返回固定状态码和响应头的服务器，并统计请求数
+func newStatusServer(t *testing.T, status int, header map[string]string) (*Tester, *int32) {
+	t.Helper()
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		for k, v := range header {
+			w.Header().Set(k, v)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error":{"message":"request failed"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	tester := NewTester(srv.URL, testKey)
+	tester.SetRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+	return tester, &requests
+}
+
+func TestRetryOnlyTransientStatus(t *testing.T) {
+	tests := []struct {
+		status   int
+		attempts int
+	}{
+		{400, 1}, {401, 1}, {402, 1}, {403, 1}, {404, 1}, {409, 1}, {413, 1}, {422, 1},
+		{429, 3}, {500, 3}, {502, 3}, {503, 3}, {504, 3},
+		{501, 1},
+	}
+	for _, tt := range tests {
+		tester, requests := newStatusServer(t, tt.status, nil)
+		result, err := tester.TestConnection(context.Background(), "DeepSeek-V3")
+		if err == nil {
+			t.Fatalf("HTTP %d: TestConnection succeeded", tt.status)
+		}
+		if result.Attempts != tt.attempts || int(atomic.LoadInt32(requests)) != tt.attempts {
+			t.Errorf("HTTP %d: attempts = %d, requests = %d, want %d", tt.status, result.Attempts, atomic.LoadInt32(requests), tt.attempts)
+		}
+	}
+}
+
+func TestRetryAfterCapped(t *testing.T) {
+	tests := []struct {
+		header map[string]string
+		want   time.Duration
+	}{
+		{map[string]string{"Retry-After": "3600"}, 5 * time.Millisecond},
+		{map[string]string{"Retry-After": time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}, 5 * time.Millisecond},
+		{map[string]string{"X-RateLimit-Reset-Requests": "2ms"}, 2 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		tester, _ := newStatusServer(t, http.StatusTooManyRequests, tt.header)
+		var delays []time.Duration
+		tester.SetRetryNotifier(func(attempt int, delay time.Duration, reason string) {
+			delays = append(delays, delay)
+		})
+		tester.TestConnection(context.Background(), "DeepSeek-V3")
+		if len(delays) != 2 {
+			t.Fatalf("%v: %d retries, want 2", tt.header, len(delays))
+		}
+		for _, d := range delays {
+			if d != tt.want {
+				t.Errorf("%v: retry delay = %s, want %s", tt.header, d, tt.want)
+			}
+		}
+	}
+}
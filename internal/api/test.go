@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/i18n"
 )
 
 // ChatRequest 聊天请求结构
@@ -26,11 +29,11 @@ type Message struct {
 
 // ChatResponse 聊天响应结构
 type ChatResponse struct {
-	ID      string   `json:"id"`
-	Object  string   `json:"object"`
-	Created int64    `json:"created"`
-	Model   string   `json:"model"`
-	Choices []Choice `json:"choices"`
+	ID      string    `json:"id"`
+	Object  string    `json:"object"`
+	Created int64     `json:"created"`
+	Model   string    `json:"model"`
+	Choices []Choice  `json:"choices"`
 	Error   *APIError `json:"error,omitempty"`
 }
 
@@ -48,10 +51,35 @@ type APIError struct {
 }
 
 // Tester API测试器
+// 同一个 Tester 不应在多个 goroutine 中并发使用（重试计数保存在实例上）
 type Tester struct {
-	baseURL string
-	apiKey  string
-	client  *http.Client
+	baseURL  string
+	apiKey   string
+	client   *http.Client
+	timeout  time.Duration // 单次请求（不含重试等待）的超时时间
+	retry    RetryPolicy
+	onRetry  RetryNotifier
+	auth     AuthStyle           // 当前测试使用的认证方式
+	provider config.ProviderType // 当前测试的 provider 类型，决定发送哪些自定义请求头
+	headers  config.Headers      // 与 opencode.json 中一致的自定义请求头
+	attempts int                 // 最近一次测试实际发出的请求次数
+}
+
+// DefaultTimeout 单次测试请求的默认超时时间
+const DefaultTimeout = 60 * time.Second
+
+// TestResult 单次连接测试的结果
+type TestResult struct {
+	Model    string              // 测试使用的模型
+	Provider config.ProviderType // 模型路由到的 provider 类型
+	Auth     AuthStyle           // 测试使用的认证方式
+	Attempts int                 // 实际发出的请求次数（含重试）
+	Duration time.Duration       // 总耗时（含重试等待）
+}
+
+// Retried 是否经过重试才得到最终结果
+func (r *TestResult) Retried() bool {
+	return r.Attempts > 1
 }
 
 // NewTester 创建新的API测试器
@@ -61,9 +89,132 @@ func NewTester(baseURL, apiKey string) *Tester {
 	return &Tester{
 		baseURL: baseURL,
 		apiKey:  apiKey,
+		// 超时由每个请求的 context 控制（见 SetTimeout），便于同时支持取消
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Transport: debuglog.Transport(),
 		},
+		timeout: DefaultTimeout,
+		retry:   DefaultRetryPolicy(),
+	}
+}
+
+// SetTimeout 设置单次请求的超时时间，d <= 0 时恢复默认值
+func (t *Tester) SetTimeout(d time.Duration) {
+	if d <= 0 {
+		d = DefaultTimeout
+	}
+	t.timeout = d
+}
+
+// SetRetryPolicy 设置请求重试策略
+func (t *Tester) SetRetryPolicy(policy RetryPolicy) {
+	t.retry = policy
+}
+
+// SetRetryNotifier 设置重试前的回调，用于在界面上提示正在重试
+func (t *Tester) SetRetryNotifier(fn RetryNotifier) {
+	t.onRetry = fn
+}
+
+// SetHeaders 设置随每个请求发送的自定义请求头，与写入 opencode.json 的 options.headers 一致
+// 连接测试和探测按模型的 provider 类型发送；/v1/models 等公共接口只发送对所有 provider 生效的请求头
+// 请求头名称登记到调试日志，取值不写入日志
+func (t *Tester) SetHeaders(h config.Headers) {
+	t.headers = h
+	for _, name := range h.Names() {
+		debuglog.AddSensitiveHeader(name)
+	}
+}
+
+// setCustomHeaders 设置自定义请求头，认证头在之后设置，不会被覆盖
+func setCustomHeaders(req *http.Request, headers map[string]string) {
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// postJSON 以 JSON 格式发送 POST 请求，返回状态码和响应体（最多读取 1MB）
+// 网络层错误统一归类为 *TestError；限流和临时性错误按重试策略自动重试
+// 每次请求单独计算超时；ctx 被取消时立即中止请求和重试等待，返回 ErrCodeCancelled
+func (t *Tester) postJSON(ctx context.Context, reqURL string, payload interface{}) (int, []byte, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, fmt.Errorf(i18n.T("api.marshal_failed"), err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		canRetry := attempt < t.retry.MaxRetries
+
+		statusCode, header, body, err := t.do(ctx, reqURL, jsonData)
+		if err != nil {
+			te := t.classifyTransportError(err)
+			if canRetry && retryableError(te) && ctx.Err() == nil {
+				if err := t.wait(ctx, attempt+1, 0, te.Message); err != nil {
+					return 0, nil, t.classifyTransportError(err)
+				}
+				continue
+			}
+			return 0, nil, te
+		}
+
+		if canRetry && retryableStatus(statusCode) {
+			if err := t.wait(ctx, attempt+1, retryAfter(header, time.Now()), fmt.Sprintf("HTTP %d", statusCode)); err != nil {
+				return 0, nil, t.classifyTransportError(err)
+			}
+			continue
+		}
+
+		return statusCode, body, nil
+	}
+}
+
+// do 发送单次 POST 请求并读取响应体，超时时间为 t.timeout
+func (t *Tester) do(ctx context.Context, reqURL string, jsonData []byte) (int, http.Header, []byte, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(reqCtx, "POST", reqURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	setCustomHeaders(httpReq, t.headers.For(t.provider))
+	httpReq.Header.Set("Content-Type", "application/json")
+	t.setAuthHeaders(httpReq, t.auth)
+
+	t.attempts++
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return resp.StatusCode, resp.Header, body, nil
+}
+
+// wait 在第 attempt 次重试前等待，ctx 被取消时提前返回 ctx.Err()
+// serverDelay 为服务端通过 Retry-After 等响应头建议的等待时间，为 0 时使用指数退避
+func (t *Tester) wait(ctx context.Context, attempt int, serverDelay time.Duration, reason string) error {
+	delay := serverDelay
+	if delay <= 0 {
+		delay = t.retry.backoff(attempt)
+	}
+	if delay > t.retry.MaxDelay {
+		delay = t.retry.MaxDelay
+	}
+	if t.onRetry != nil {
+		t.onRetry(attempt, delay, reason)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -91,70 +242,84 @@ type AnthropicError struct {
 
 // TestConnection 测试API连接
 // 使用用户指定的 model 发送一个简单请求，验证 API Key 和 URL 是否有效
-func (t *Tester) TestConnection(model string) error {
-	switch config.ClassifyModel(model) {
+// 无论成功与否都会返回 TestResult，便于展示重试次数和耗时
+func (t *Tester) TestConnection(ctx context.Context, model string) (*TestResult, error) {
+	return t.TestConnectionAs(ctx, model, config.ClassifyModel(model))
+}
+
+// TestConnectionAs 按指定的 provider 类型测试模型连接（用于模板中的路由覆盖）
+// 请求头与 opencode 中该 provider 的 SDK 一致（见 NativeAuthStyle）
+func (t *Tester) TestConnectionAs(ctx context.Context, model string, pType config.ProviderType) (*TestResult, error) {
+	return t.TestConnectionWithAuth(ctx, model, pType, NativeAuthStyle(pType))
+}
+
+// TestConnectionWithAuth 按指定的 provider 类型和认证方式测试模型连接
+func (t *Tester) TestConnectionWithAuth(ctx context.Context, model string, pType config.ProviderType, style AuthStyle) (*TestResult, error) {
+	t.auth = style
+	t.provider = pType
+	t.attempts = 0
+	start := time.Now()
+
+	var err error
+	switch pType {
 	case config.ProviderAnthropic:
-		return t.testAnthropicConnection(model)
+		err = t.testAnthropicConnection(ctx, model)
 	case config.ProviderGoogle:
-		return t.testGoogleConnection(model)
+		err = t.testGoogleConnection(ctx, model)
 	case config.ProviderOpenAIResponses:
-		return t.testOpenAIResponsesConnection(model)
+		err = t.testOpenAIResponsesConnection(ctx, model)
 	default:
-		return t.testOpenAIConnection(model)
+		err = t.testOpenAIConnection(ctx, model)
+	}
+
+	result := &TestResult{
+		Model:    model,
+		Provider: pType,
+		Auth:     style,
+		Attempts: t.attempts,
+		Duration: time.Since(start),
 	}
+	return result, err
 }
 
 // testAnthropicConnection 使用 Anthropic Messages API 测试连接
-func (t *Tester) testAnthropicConnection(model string) error {
+func (t *Tester) testAnthropicConnection(ctx context.Context, model string) error {
 	req := AnthropicRequest{
 		Model:     model,
 		MaxTokens: 10,
 		Messages:  []Message{{Role: "user", Content: "Hi"}},
 	}
+	_, err := t.sendAnthropic(ctx, req)
+	return err
+}
 
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("序列化请求失败: %w", err)
-	}
-
-	url := t.baseURL + "/v1/messages"
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("创建请求失败: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+t.apiKey)
-
-	resp, err := t.client.Do(httpReq)
-	if err != nil {
-		return fmt.Errorf("发送请求失败: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+// sendAnthropic 发送 Anthropic Messages API 请求并检查响应
+func (t *Tester) sendAnthropic(ctx context.Context, req interface{}) ([]byte, error) {
+	reqURL := t.baseURL + "/v1/messages"
+	statusCode, body, err := t.postJSON(ctx, reqURL, req)
 	if err != nil {
-		return fmt.Errorf("读取响应失败: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	if statusCode != http.StatusOK {
 		var anthResp AnthropicResponse
+		var apiMessage string
 		if json.Unmarshal(body, &anthResp) == nil && anthResp.Error != nil {
-			return fmt.Errorf("API错误 (%d): %s", resp.StatusCode, anthResp.Error.Message)
+			apiMessage = anthResp.Error.Message
 		}
-		return fmt.Errorf("API请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+		return nil, t.classifyHTTPError(statusCode, body, apiMessage)
 	}
 
 	var anthResp AnthropicResponse
 	if err := json.Unmarshal(body, &anthResp); err != nil {
-		return fmt.Errorf("解析响应失败: %w", err)
+		return nil, t.invalidResponse(body, i18n.T("api.parse_failed", err))
 	}
 
 	if len(anthResp.Content) == 0 {
-		return fmt.Errorf("API响应无效：没有返回任何内容")
+		return nil, t.invalidResponse(body, i18n.T("api.empty_content"))
 	}
 
-	return nil
+	return body, nil
 }
 
 // GeminiRequest Google Generative Language API 请求结构
@@ -206,160 +371,121 @@ type OpenAIResponsesResponse struct {
 // testGoogleConnection 使用 Google Generative Language API 测试连接
 // URL 格式与 opencode 配置中 @ai-sdk/google baseURL(url+"/v1beta") 一致：
 // {baseURL}/v1beta/models/{model}:generateContent
-func (t *Tester) testGoogleConnection(model string) error {
+func (t *Tester) testGoogleConnection(ctx context.Context, model string) error {
 	req := GeminiRequest{
 		Contents: []GeminiContent{
 			{Parts: []GeminiPart{{Text: "Hi"}}},
 		},
 	}
+	_, err := t.sendGoogle(ctx, model, req)
+	return err
+}
 
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("序列化请求失败: %w", err)
-	}
-
+// sendGoogle 发送 Gemini generateContent 请求并检查响应
+func (t *Tester) sendGoogle(ctx context.Context, model string, req interface{}) ([]byte, error) {
 	reqURL := fmt.Sprintf("%s/v1beta/models/%s:generateContent", t.baseURL, url.PathEscape(model))
-	httpReq, err := http.NewRequest("POST", reqURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("创建请求失败: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+t.apiKey)
-
-	resp, err := t.client.Do(httpReq)
+	statusCode, body, err := t.postJSON(ctx, reqURL, req)
 	if err != nil {
-		return fmt.Errorf("发送请求失败: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
-	if err != nil {
-		return fmt.Errorf("读取响应失败: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
+	if statusCode != http.StatusOK {
 		var geminiResp GeminiResponse
+		var apiMessage string
 		if json.Unmarshal(body, &geminiResp) == nil && geminiResp.Error != nil {
-			return fmt.Errorf("API错误 (%d): %s", resp.StatusCode, geminiResp.Error.Message)
+			apiMessage = geminiResp.Error.Message
 		}
-		return fmt.Errorf("API请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+		return nil, t.classifyHTTPError(statusCode, body, apiMessage)
 	}
 
 	var geminiResp GeminiResponse
 	if err := json.Unmarshal(body, &geminiResp); err != nil {
-		return fmt.Errorf("解析响应失败: %w", err)
+		return nil, t.invalidResponse(body, i18n.T("api.parse_failed", err))
 	}
 
 	if len(geminiResp.Candidates) == 0 {
-		return fmt.Errorf("API响应无效：没有返回任何 candidates")
+		return nil, t.invalidResponse(body, i18n.T("api.empty_candidates"))
 	}
 
-	return nil
+	return body, nil
 }
 
 // testOpenAIResponsesConnection 使用 OpenAI Responses API 测试连接
-func (t *Tester) testOpenAIResponsesConnection(model string) error {
+func (t *Tester) testOpenAIResponsesConnection(ctx context.Context, model string) error {
 	req := OpenAIResponsesRequest{
 		Model: model,
 		Input: "Hi",
 	}
+	_, err := t.sendOpenAIResponses(ctx, req)
+	return err
+}
 
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("序列化请求失败: %w", err)
-	}
-
-	url := t.baseURL + "/v1/responses"
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("创建请求失败: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+t.apiKey)
-
-	resp, err := t.client.Do(httpReq)
-	if err != nil {
-		return fmt.Errorf("发送请求失败: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+// sendOpenAIResponses 发送 OpenAI Responses API 请求并检查响应
+func (t *Tester) sendOpenAIResponses(ctx context.Context, req interface{}) ([]byte, error) {
+	reqURL := t.baseURL + "/v1/responses"
+	statusCode, body, err := t.postJSON(ctx, reqURL, req)
 	if err != nil {
-		return fmt.Errorf("读取响应失败: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	if statusCode != http.StatusOK {
 		var respErr OpenAIResponsesResponse
+		var apiMessage string
 		if json.Unmarshal(body, &respErr) == nil && respErr.Error != nil {
-			return fmt.Errorf("API错误 (%d): %s", resp.StatusCode, respErr.Error.Message)
+			apiMessage = respErr.Error.Message
 		}
-		return fmt.Errorf("API请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+		return nil, t.classifyHTTPError(statusCode, body, apiMessage)
 	}
 
 	var responsesResp OpenAIResponsesResponse
 	if err := json.Unmarshal(body, &responsesResp); err != nil {
-		return fmt.Errorf("解析响应失败: %w", err)
+		return nil, t.invalidResponse(body, i18n.T("api.parse_failed", err))
 	}
 
 	if len(responsesResp.Output) == 0 {
-		return fmt.Errorf("API响应无效：没有返回任何输出")
+		return nil, t.invalidResponse(body, i18n.T("api.empty_output"))
 	}
 
-	return nil
+	return body, nil
 }
 
 // testOpenAIConnection 使用 OpenAI Chat Completions API 测试连接
-func (t *Tester) testOpenAIConnection(model string) error {
+func (t *Tester) testOpenAIConnection(ctx context.Context, model string) error {
 	req := ChatRequest{
 		Model: model,
 		Messages: []Message{
 			{Role: "user", Content: "Hi"},
 		},
 	}
+	_, err := t.sendOpenAIChat(ctx, req)
+	return err
+}
 
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("序列化请求失败: %w", err)
-	}
-
-	url := t.baseURL + "/v1/chat/completions"
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("创建请求失败: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+t.apiKey)
-
-	resp, err := t.client.Do(httpReq)
-	if err != nil {
-		return fmt.Errorf("发送请求失败: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+// sendOpenAIChat 发送 OpenAI Chat Completions 请求并检查响应
+func (t *Tester) sendOpenAIChat(ctx context.Context, req interface{}) ([]byte, error) {
+	reqURL := t.baseURL + "/v1/chat/completions"
+	statusCode, body, err := t.postJSON(ctx, reqURL, req)
 	if err != nil {
-		return fmt.Errorf("读取响应失败: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	if statusCode != http.StatusOK {
 		var chatResp ChatResponse
+		var apiMessage string
 		if json.Unmarshal(body, &chatResp) == nil && chatResp.Error != nil {
-			return fmt.Errorf("API错误 (%d): %s", resp.StatusCode, chatResp.Error.Message)
+			apiMessage = chatResp.Error.Message
 		}
-		return fmt.Errorf("API请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+		return nil, t.classifyHTTPError(statusCode, body, apiMessage)
 	}
 
 	var chatResp ChatResponse
 	if err := json.Unmarshal(body, &chatResp); err != nil {
-		return fmt.Errorf("解析响应失败: %w", err)
+		return nil, t.invalidResponse(body, i18n.T("api.parse_failed", err))
 	}
 
 	if len(chatResp.Choices) == 0 {
-		return fmt.Errorf("API响应无效：没有返回任何内容")
+		return nil, t.invalidResponse(body, i18n.T("api.empty_content"))
 	}
 
-	return nil
+	return body, nil
 }
@@ -0,0 +1,433 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/mockserver"
+)
+
+const testKey = "sk-mocktestkey1234567890"
+
+// newMockTester 启动模拟网关并返回指向它的 Tester（重试等待缩短到毫秒级）
+func newMockTester(t *testing.T, opts mockserver.Options) (*Tester, *mockserver.Server) {
+	t.Helper()
+	if opts.APIKey == "" {
+		opts.APIKey = testKey
+	}
+	gw := mockserver.New(opts)
+	srv := httptest.NewServer(gw)
+	t.Cleanup(srv.Close)
+
+	tester := NewTester(srv.URL+"/v1", testKey)
+	tester.SetRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+	return tester, gw
+}
+
+// wantCode 断言错误为指定错误代码的 *TestError
+func wantCode(t *testing.T, err error, code ErrorCode) *TestError {
+	t.Helper()
+	te, ok := AsTestError(err)
+	if !ok {
+		t.Fatalf("error = %v, want *TestError with code %s", err, code)
+	}
+	if te.Code != code {
+		t.Fatalf("error code = %s (%v), want %s", te.Code, err, code)
+	}
+	return te
+}
+
+func TestTestConnectionProviders(t *testing.T) {
+	tests := []struct {
+		model string
+		pType config.ProviderType
+	}{
+		{"claude-sonnet-4-5", config.ProviderAnthropic},
+		{"gemini-2.5-pro", config.ProviderGoogle},
+		{"gpt-5", config.ProviderOpenAIResponses},
+		{"DeepSeek-V3", config.ProviderOpenAI},
+	}
+	tester, _ := newMockTester(t, mockserver.Options{})
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			result, err := tester.TestConnection(context.Background(), tt.model)
+			if err != nil {
+				t.Fatalf("TestConnection(%q) error: %v", tt.model, err)
+			}
+			if result.Provider != tt.pType {
+				t.Errorf("Provider = %v, want %v", result.Provider, tt.pType)
+			}
+			if result.Attempts != 1 || result.Retried() {
+				t.Errorf("Attempts = %d, want 1", result.Attempts)
+			}
+		})
+	}
+}
+
+func TestTestConnectionAsOverride(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{Models: []string{"my-proxy-model"}})
+	result, err := tester.TestConnectionAs(context.Background(), "my-proxy-model", config.ProviderAnthropic)
+	if err != nil {
+		t.Fatalf("TestConnectionAs error: %v", err)
+	}
+	if result.Provider != config.ProviderAnthropic {
+		t.Errorf("Provider = %v, want anthropic", result.Provider)
+	}
+}
+
+func TestTestConnectionFaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		fault    mockserver.Fault
+		model    string
+		code     ErrorCode
+		attempts int
+	}{
+		{"unauthorized", mockserver.FaultUnauthorized, "claude-sonnet-4-5", ErrCodeAuthInvalid, 1},
+		{"balance", mockserver.FaultBalance, "DeepSeek-V3", ErrCodeInsufficientBalance, 1},
+		{"model not found", mockserver.FaultNotFound, "gemini-2.5-pro", ErrCodeModelNotFound, 1},
+		{"html page", mockserver.FaultHTML, "DeepSeek-V3", ErrCodeWrongBasePath, 1},
+		{"malformed json", mockserver.FaultMalformed, "gpt-5", ErrCodeInvalidResponse, 1},
+		{"rate limited", mockserver.FaultRateLimited, "DeepSeek-V3", ErrCodeRateLimited, 3},
+		{"server error", mockserver.FaultServerError, "claude-sonnet-4-5", ErrCodeUpstream, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester, gw := newMockTester(t, mockserver.Options{Fault: tt.fault})
+			result, err := tester.TestConnection(context.Background(), tt.model)
+			wantCode(t, err, tt.code)
+			if result.Attempts != tt.attempts {
+				t.Errorf("Attempts = %d, want %d", result.Attempts, tt.attempts)
+			}
+			if gw.Requests() != tt.attempts {
+				t.Errorf("server saw %d requests, want %d", gw.Requests(), tt.attempts)
+			}
+		})
+	}
+}
+
+func TestTestConnectionWrongKey(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{APIKey: "sk-someotherkey12345678"})
+	_, err := tester.TestConnection(context.Background(), "DeepSeek-V3")
+	te := wantCode(t, err, ErrCodeAuthInvalid)
+	if te.StatusCode != 401 {
+		t.Errorf("StatusCode = %d, want 401", te.StatusCode)
+	}
+}
+
+func TestTestConnectionUnknownModel(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{})
+	_, err := tester.TestConnection(context.Background(), "claude-sonet-4")
+	wantCode(t, err, ErrCodeModelNotFound)
+}
+
+func TestTestConnectionRetryRecovers(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{Fault: mockserver.FaultRateLimited, FaultCount: 1})
+	var notified int
+	tester.SetRetryNotifier(func(attempt int, delay time.Duration, reason string) {
+		notified++
+		if delay > 5*time.Millisecond {
+			t.Errorf("retry delay %s exceeds MaxDelay", delay)
+		}
+	})
+
+	result, err := tester.TestConnection(context.Background(), "gemini-2.5-pro")
+	if err != nil {
+		t.Fatalf("TestConnection error after retry: %v", err)
+	}
+	if result.Attempts != 2 || !result.Retried() {
+		t.Errorf("Attempts = %d, want 2", result.Attempts)
+	}
+	if notified != 1 {
+		t.Errorf("retry notifier called %d times, want 1", notified)
+	}
+}
+
+func TestTestConnectionNoRetry(t *testing.T) {
+	tester, gw := newMockTester(t, mockserver.Options{Fault: mockserver.FaultServerError})
+	tester.SetRetryPolicy(RetryPolicy{})
+	result, err := tester.TestConnection(context.Background(), "DeepSeek-V3")
+	wantCode(t, err, ErrCodeUpstream)
+	if result.Attempts != 1 || gw.Requests() != 1 {
+		t.Errorf("Attempts = %d, requests = %d, want 1", result.Attempts, gw.Requests())
+	}
+}
+
+func TestTestConnectionTimeout(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{Fault: mockserver.FaultSlow, Delay: time.Second})
+	tester.SetRetryPolicy(RetryPolicy{})
+	tester.SetTimeout(50 * time.Millisecond)
+
+	_, err := tester.TestConnection(context.Background(), "DeepSeek-V3")
+	wantCode(t, err, ErrCodeTimeout)
+}
+
+func TestTestConnectionTimeoutPerAttempt(t *testing.T) {
+	// 每次重试重新计算超时：第一次超时后重试成功
+	tester, gw := newMockTester(t, mockserver.Options{Fault: mockserver.FaultSlow, FaultCount: 1, Delay: time.Second})
+	tester.SetTimeout(100 * time.Millisecond)
+
+	result, err := tester.TestConnection(context.Background(), "DeepSeek-V3")
+	if err != nil {
+		t.Fatalf("TestConnection error after timed-out attempt: %v", err)
+	}
+	if result.Attempts != 2 || gw.Requests() != 2 {
+		t.Errorf("Attempts = %d, requests = %d, want 2", result.Attempts, gw.Requests())
+	}
+}
+
+func TestTestConnectionCancelled(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{Fault: mockserver.FaultSlow, Delay: 5 * time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := tester.TestConnection(ctx, "claude-sonnet-4-5")
+	wantCode(t, err, ErrCodeCancelled)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancellation took %s, want prompt return", elapsed)
+	}
+	if result.Attempts != 1 {
+		t.Errorf("Attempts = %d, want no retry after cancellation", result.Attempts)
+	}
+}
+
+func TestTestConnectionCancelledDuringRetryWait(t *testing.T) {
+	tester, gw := newMockTester(t, mockserver.Options{Fault: mockserver.FaultServerError})
+	tester.SetRetryPolicy(RetryPolicy{MaxRetries: 3, BaseDelay: 5 * time.Second, MaxDelay: 5 * time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	tester.SetRetryNotifier(func(int, time.Duration, string) { cancel() })
+
+	_, err := tester.TestConnection(ctx, "DeepSeek-V3")
+	wantCode(t, err, ErrCodeCancelled)
+	if gw.Requests() != 1 {
+		t.Errorf("server saw %d requests, want 1", gw.Requests())
+	}
+}
+
+func TestTestConnectionSlowSucceeds(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{Fault: mockserver.FaultSlow, Delay: 20 * time.Millisecond})
+	result, err := tester.TestConnection(context.Background(), "claude-sonnet-4-5")
+	if err != nil {
+		t.Fatalf("TestConnection error: %v", err)
+	}
+	if result.Duration < 20*time.Millisecond {
+		t.Errorf("Duration = %s, want at least the injected delay", result.Duration)
+	}
+}
+
+func TestTestConnectionNetworkError(t *testing.T) {
+	srv := httptest.NewServer(mockserver.New(mockserver.Options{}))
+	srv.Close()
+
+	tester := NewTester(srv.URL, testKey)
+	tester.SetRetryPolicy(RetryPolicy{})
+	_, err := tester.TestConnection(context.Background(), "DeepSeek-V3")
+	wantCode(t, err, ErrCodeNetwork)
+}
+
+func TestListModels(t *testing.T) {
+	models := []string{"gpt-5", "claude-sonnet-4-5", "DeepSeek-V3"}
+	tester, _ := newMockTester(t, mockserver.Options{Models: models})
+	got, err := tester.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels error: %v", err)
+	}
+	want := []string{"DeepSeek-V3", "claude-sonnet-4-5", "gpt-5"}
+	if len(got) != len(want) {
+		t.Fatalf("ListModels = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ListModels = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestListModelsUnauthorized(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{Fault: mockserver.FaultUnauthorized})
+	_, err := tester.ListModels(context.Background())
+	wantCode(t, err, ErrCodeAuthInvalid)
+}
+
+func TestCheckReachable(t *testing.T) {
+	tester, gw := newMockTester(t, mockserver.Options{})
+	// 不带 API Key 的 401 也说明地址指向 API
+	tester.apiKey = ""
+	if err := tester.CheckReachable(context.Background()); err != nil {
+		t.Errorf("CheckReachable error: %v", err)
+	}
+
+	gw.SetFault(mockserver.FaultHTML, 0)
+	wantCode(t, tester.CheckReachable(context.Background()), ErrCodeWrongBasePath)
+}
+
+func TestNativeAuthHeaders(t *testing.T) {
+	tests := []struct {
+		model string
+		style AuthStyle
+	}{
+		{"claude-sonnet-4-5", AuthAnthropic},
+		{"gemini-2.5-pro", AuthGoogle},
+		{"gpt-5", AuthBearer},
+		{"DeepSeek-V3", AuthBearer},
+	}
+	tester, _ := newMockTester(t, mockserver.Options{StrictAuth: true})
+	for _, tt := range tests {
+		result, err := tester.TestConnection(context.Background(), tt.model)
+		if err != nil {
+			t.Errorf("TestConnection(%q) with strict auth: %v", tt.model, err)
+			continue
+		}
+		if result.Auth != tt.style {
+			t.Errorf("TestConnection(%q) auth = %s, want %s", tt.model, result.Auth, tt.style)
+		}
+	}
+}
+
+func TestCustomHeaders(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{RequireHeaders: map[string]string{"X-Team": "ops"}})
+	if _, err := tester.TestConnection(context.Background(), "gpt-5"); err == nil {
+		t.Fatal("TestConnection without the required header succeeded, want error")
+	}
+
+	var headers config.Headers
+	headers.Set(nil, "X-Team", "dev")
+	anthropic := config.ProviderAnthropic
+	headers.Set(&anthropic, "X-Team", "ops")
+	tester.SetHeaders(headers)
+	// 按类型设置的请求头覆盖全局请求头，只发往对应类型的 provider
+	if _, err := tester.TestConnection(context.Background(), "claude-sonnet-4-5"); err != nil {
+		t.Errorf("TestConnection(claude) with provider header: %v", err)
+	}
+	if _, err := tester.TestConnection(context.Background(), "gpt-5"); err == nil {
+		t.Error("TestConnection(gpt-5) with global header X-Team: dev succeeded, want error")
+	}
+
+	headers.Set(nil, "X-Team", "ops")
+	tester.SetHeaders(headers)
+	if _, err := tester.ListModels(context.Background()); err != nil {
+		t.Errorf("ListModels with global header: %v", err)
+	}
+}
+
+func TestProbeAuth(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{StrictAuth: true})
+	probes := tester.ProbeAuth(context.Background(), "claude-sonnet-4-5", config.ProviderAnthropic)
+	if len(probes) != 2 {
+		t.Fatalf("ProbeAuth returned %d probes, want 2", len(probes))
+	}
+	if p := probes[0]; p.Style != AuthAnthropic || !p.Native || !p.Accepted() {
+		t.Errorf("native probe = %+v, want accepted x-api-key", p)
+	}
+	if p := probes[1]; p.Style != AuthBearer || p.Native || p.Accepted() {
+		t.Errorf("bearer probe = %+v, want rejected", p)
+	}
+
+	// OpenAI 兼容接口原生就是 Bearer，只测试一次
+	if probes := tester.ProbeAuth(context.Background(), "DeepSeek-V3", config.ProviderOpenAI); len(probes) != 1 || !probes[0].Accepted() {
+		t.Errorf("ProbeAuth(openai) = %+v, want one accepted probe", probes)
+	}
+}
+
+func TestProbeImageInput(t *testing.T) {
+	tests := []struct {
+		model string
+		pType config.ProviderType
+	}{
+		{"claude-sonnet-4-5", config.ProviderAnthropic},
+		{"gemini-2.5-pro", config.ProviderGoogle},
+		{"gpt-5", config.ProviderOpenAIResponses},
+		{"DeepSeek-V3", config.ProviderOpenAI},
+	}
+	var models []string
+	for _, tt := range tests {
+		models = append(models, tt.model)
+	}
+	tester, _ := newMockTester(t, mockserver.Options{Models: models, StrictAuth: true})
+	textOnly, _ := newMockTester(t, mockserver.Options{Models: models, TextOnly: models})
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			p := tester.ProbeImageInput(context.Background(), tt.model, tt.pType)
+			if p.Support != ImageSupported || p.Err != nil {
+				t.Errorf("ProbeImageInput = %v (%v), want supported", p.Support, p.Err)
+			}
+			if got := p.InputModalities(); len(got) != 2 || got[1] != "image" {
+				t.Errorf("InputModalities = %v, want [text image]", got)
+			}
+
+			p = textOnly.ProbeImageInput(context.Background(), tt.model, tt.pType)
+			if p.Support != ImageUnsupported {
+				t.Errorf("text-only ProbeImageInput = %v (%v), want unsupported", p.Support, p.Err)
+			}
+			// 不带图片的普通测试不受影响
+			if _, err := textOnly.TestConnectionAs(context.Background(), tt.model, tt.pType); err != nil {
+				t.Errorf("text-only TestConnectionAs error: %v", err)
+			}
+		})
+	}
+}
+
+func TestProbeImageInputUnknown(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{Fault: mockserver.FaultUnauthorized})
+	p := tester.ProbeImageInput(context.Background(), "claude-sonnet-4-5", config.ProviderAnthropic)
+	if p.Support != ImageUnknown || p.InputModalities() != nil {
+		t.Errorf("ProbeImageInput with bad key = %v, want unknown without modalities", p.Support)
+	}
+}
+
+func TestProbeReasoning(t *testing.T) {
+	tests := []struct {
+		model  string
+		pType  config.ProviderType
+		tokens bool // 接口是否报告推理 token 数
+	}{
+		{"claude-sonnet-4-5", config.ProviderAnthropic, false},
+		{"gemini-2.5-pro", config.ProviderGoogle, true},
+		{"gpt-5", config.ProviderOpenAIResponses, true},
+		{"DeepSeek-R1", config.ProviderOpenAI, true},
+	}
+	var models []string
+	for _, tt := range tests {
+		models = append(models, tt.model)
+	}
+	thinking, _ := newMockTester(t, mockserver.Options{Models: models, Reasoning: models})
+	plain, _ := newMockTester(t, mockserver.Options{Models: models})
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			p := thinking.ProbeReasoning(context.Background(), tt.model, tt.pType)
+			if p.Support != ReasoningSupported {
+				t.Errorf("ProbeReasoning = %v (%v), want supported", p.Support, p.Err)
+			}
+			if (p.Tokens > 0) != tt.tokens {
+				t.Errorf("Tokens = %d, want reported: %v", p.Tokens, tt.tokens)
+			}
+			if reasoning, known := p.Reasoning(); !reasoning || !known {
+				t.Errorf("Reasoning() = %v, %v, want true, true", reasoning, known)
+			}
+
+			// 非推理模型：拒绝思考参数（Chat Completions 无参数，正常返回但没有思考内容）
+			p = plain.ProbeReasoning(context.Background(), tt.model, tt.pType)
+			if p.Support != ReasoningNone {
+				t.Errorf("plain ProbeReasoning = %v (%v), want none", p.Support, p.Err)
+			}
+			if reasoning, known := p.Reasoning(); reasoning || !known {
+				t.Errorf("plain Reasoning() = %v, %v, want false, true", reasoning, known)
+			}
+		})
+	}
+}
+
+func TestProbeReasoningUnknown(t *testing.T) {
+	tester, _ := newMockTester(t, mockserver.Options{Fault: mockserver.FaultServerError})
+	tester.SetRetryPolicy(RetryPolicy{})
+	p := tester.ProbeReasoning(context.Background(), "gpt-5", config.ProviderOpenAIResponses)
+	if _, known := p.Reasoning(); known || p.Support != ReasoningUnknown {
+		t.Errorf("ProbeReasoning with gateway error = %v, want unknown", p.Support)
+	}
+}
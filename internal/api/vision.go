@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	"dmxapi-config/internal/config"
+)
+
+// probeImage 图片输入探测使用的 16x16 纯红色 PNG（base64）
+const probeImage = "iVBORw0KGgoAAAANSUhEUgAAABAAAAAQCAIAAACQkWg2AAAAFklEQVR42mP4z8BAEmIY1TCqYfhqAACQ+f8B8u7oVwAAAABJRU5ErkJggg=="
+
+// probeImagePrompt 随图片发送的问题
+const probeImagePrompt = "What color is this image? Answer in one word."
+
+// imageKeywords 网关拒绝图片输入时错误信息中常见的关键词
+var imageKeywords = []string{"image", "vision", "multimodal", "multi-modal", "图片", "图像", "多模态"}
+
+// ImageSupport 模型对图片输入的支持情况
+type ImageSupport int
+
+const (
+	ImageUnknown     ImageSupport = iota // 探测失败（网络、认证、限流等），无法判断
+	ImageSupported                       // 网关接受图片并正常返回
+	ImageUnsupported                     // 网关拒绝了图片输入
+)
+
+// String 返回结果文档中使用的名称
+func (s ImageSupport) String() string {
+	switch s {
+	case ImageSupported:
+		return "supported"
+	case ImageUnsupported:
+		return "unsupported"
+	}
+	return "unknown"
+}
+
+// ImageProbe 单个模型的图片输入探测结果
+type ImageProbe struct {
+	Model    string
+	Provider config.ProviderType
+	Support  ImageSupport
+	Err      error // Support 为 ImageUnsupported 时为网关的拒绝原因，为 ImageUnknown 时为探测失败的原因
+}
+
+// InputModalities 按探测结果返回写入配置的输入模态，无法判断时返回 nil
+func (p ImageProbe) InputModalities() []string {
+	switch p.Support {
+	case ImageSupported:
+		return []string{"text", "image"}
+	case ImageUnsupported:
+		return []string{"text"}
+	}
+	return nil
+}
+
+// ProbeImageInput 以 provider 原生格式发送一张小图片，检查模型能否通过该接口接收图片
+// 各接口格式：Anthropic image 内容块、Gemini inlineData、Responses input_image、Chat Completions image_url
+// 网关返回 200 视为支持；返回 4xx 请求错误（或错误信息提到图片）视为不支持
+func (t *Tester) ProbeImageInput(ctx context.Context, model string, pType config.ProviderType) ImageProbe {
+	t.auth = NativeAuthStyle(pType)
+	t.provider = pType
+	t.attempts = 0
+
+	var err error
+	switch pType {
+	case config.ProviderAnthropic:
+		_, err = t.sendAnthropic(ctx, map[string]interface{}{
+			"model":      model,
+			"max_tokens": 10,
+			"messages": []map[string]interface{}{{
+				"role": "user",
+				"content": []map[string]interface{}{
+					{"type": "image", "source": map[string]string{"type": "base64", "media_type": "image/png", "data": probeImage}},
+					{"type": "text", "text": probeImagePrompt},
+				},
+			}},
+		})
+	case config.ProviderGoogle:
+		_, err = t.sendGoogle(ctx, model, map[string]interface{}{
+			"contents": []map[string]interface{}{{
+				"parts": []map[string]interface{}{
+					{"inlineData": map[string]string{"mimeType": "image/png", "data": probeImage}},
+					{"text": probeImagePrompt},
+				},
+			}},
+		})
+	case config.ProviderOpenAIResponses:
+		_, err = t.sendOpenAIResponses(ctx, map[string]interface{}{
+			"model": model,
+			"input": []map[string]interface{}{{
+				"role": "user",
+				"content": []map[string]interface{}{
+					{"type": "input_text", "text": probeImagePrompt},
+					{"type": "input_image", "image_url": "data:image/png;base64," + probeImage},
+				},
+			}},
+		})
+	default:
+		_, err = t.sendOpenAIChat(ctx, map[string]interface{}{
+			"model": model,
+			"messages": []map[string]interface{}{{
+				"role": "user",
+				"content": []map[string]interface{}{
+					{"type": "text", "text": probeImagePrompt},
+					{"type": "image_url", "image_url": map[string]string{"url": "data:image/png;base64," + probeImage}},
+				},
+			}},
+		})
+	}
+
+	probe := ImageProbe{Model: model, Provider: pType, Support: ImageSupported, Err: err}
+	if err != nil {
+		probe.Support = imageSupportOf(err)
+	}
+	return probe
+}
+
+// imageSupportOf 根据探测请求的错误判断是网关拒绝了图片，还是探测本身失败
+func imageSupportOf(err error) ImageSupport {
+	te, ok := AsTestError(err)
+	if !ok {
+		return ImageUnknown
+	}
+	switch te.Code {
+	case ErrCodeBadRequest:
+		return ImageUnsupported
+	case ErrCodeUpstream, ErrCodeUnknown:
+		// 部分网关把上游的图片格式错误原样作为 5xx 返回
+		if containsAny(strings.ToLower(te.Message), imageKeywords) {
+			return ImageUnsupported
+		}
+	}
+	return ImageUnknown
+}
@@ -27,3 +27,9 @@ func (a *AuthManager) Login() (string, error) {
 	writer := config.NewWriter()
 	return writer.WriteAuth(authConfig)
 }
+
+// LoginRemoving 执行认证配置，同时删除 remove 中列出的 provider 的认证条目
+func (a *AuthManager) LoginRemoving(remove []string) (string, error) {
+	authConfig := config.NewAuthConfig(a.providerIDs, a.apiKey)
+	return config.NewWriter().WriteAuthRemoving(authConfig, remove)
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// endpointSuffixPattern 匹配 URL 末尾误粘贴的 API 接口路径（可带版本前缀），如
+// /v1/chat/completions、/v1/messages、/v1beta/models/gemini-2.5-pro:generateContent
+var endpointSuffixPattern = regexp.MustCompile(`(?i)(/v\d+(beta\d*)?)?/(chat/completions|completions|messages|responses|embeddings|models(/[^/]+)?|dashboard/billing/[a-z_]+)$`)
+
+// dashboardPathPattern 匹配 DMXAPI 控制台页面路径（整个路径都是页面路径时才去除，避免误伤子路径部署）
+var dashboardPathPattern = regexp.MustCompile(`(?i)^/(token|rmb|console|panel|dashboard|login|register|pricing|topup|log|detail|setting|about|docs?)(/.*)?$`)
+
+// BaseURLInfo 对用户输入的 URL 的分析结果
+type BaseURLInfo struct {
+	URL      string // 去除接口路径、页面路径、查询参数和版本后缀后的基础 URL
+	Stripped string // 被去除的部分（仅版本后缀时为空），用于提示用户
+	Subpath  string // 基础 URL 中保留的路径，非空说明网关部署在子路径下（如 /openai）
+	Insecure bool   // 使用 http:// 访问非本机地址，API Key 将以明文传输
+}
+
+// AnalyzeBaseURL 识别并去除误粘贴的接口路径（如 /v1/chat/completions）、控制台页面路径和查询参数，
+// 同时检测子路径部署和不安全的 http:// 地址
+func AnalyzeBaseURL(raw string) BaseURLInfo {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return BaseURLInfo{URL: NormalizeBaseURL(raw)}
+	}
+
+	// 查询参数可能含有密钥（如 ?key=），提示中不显示取值
+	stripped := ""
+	if u.RawQuery != "" {
+		stripped = "?..."
+	}
+	if u.Fragment != "" {
+		stripped += "#" + u.Fragment
+	}
+
+	p := strings.TrimRight(u.EscapedPath(), "/")
+	if loc := endpointSuffixPattern.FindStringIndex(p); loc != nil {
+		stripped = p[loc[0]:] + stripped
+		p = p[:loc[0]]
+	} else if dashboardPathPattern.MatchString(p) {
+		stripped = p + stripped
+		p = ""
+	}
+	p = strings.TrimRight(versionSuffixPattern.ReplaceAllString(p, ""), "/")
+
+	return BaseURLInfo{
+		URL:      u.Scheme + "://" + u.Host + p,
+		Stripped: stripped,
+		Subpath:  p,
+		Insecure: strings.EqualFold(u.Scheme, "http") && !isLoopbackHost(u.Hostname()),
+	}
+}
+
+// isLoopbackHost 判断主机名是否指向本机
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
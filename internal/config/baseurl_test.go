@@ -0,0 +1,52 @@
+package config
+
+import "testing"
+
+func TestAnalyzeBaseURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want BaseURLInfo
+	}{
+		{"https://www.dmxapi.cn", BaseURLInfo{URL: "https://www.dmxapi.cn"}},
+		{"  https://www.dmxapi.cn/  ", BaseURLInfo{URL: "https://www.dmxapi.cn"}},
+		{"https://www.dmxapi.cn/v1", BaseURLInfo{URL: "https://www.dmxapi.cn"}},
+		{"https://www.dmxapi.cn/v1/", BaseURLInfo{URL: "https://www.dmxapi.cn"}},
+		// 误粘贴的接口路径
+		{"https://www.dmxapi.cn/v1/chat/completions", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "/v1/chat/completions"}},
+		{"https://www.dmxapi.cn/v1/messages", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "/v1/messages"}},
+		{"https://www.dmxapi.cn/v1/responses/", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "/v1/responses"}},
+		{"https://www.dmxapi.cn/v1/models", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "/v1/models"}},
+		{"https://www.dmxapi.cn/v1beta/models/gemini-2.5-pro:generateContent", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "/v1beta/models/gemini-2.5-pro:generateContent"}},
+		{"https://www.dmxapi.cn/V1/Chat/Completions", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "/V1/Chat/Completions"}},
+		{"https://www.dmxapi.cn/v1/dashboard/billing/usage", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "/v1/dashboard/billing/usage"}},
+		// 控制台页面路径
+		{"https://www.dmxapi.cn/console", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "/console"}},
+		{"https://www.dmxapi.cn/token/", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "/token"}},
+		{"https://www.dmxapi.cn/console/log", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "/console/log"}},
+		// 查询参数和片段（取值不显示）
+		{"https://www.dmxapi.cn?key=sk-secret", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "?..."}},
+		{"https://www.dmxapi.cn/v1?", BaseURLInfo{URL: "https://www.dmxapi.cn"}},
+		{"https://www.dmxapi.cn/v1/chat/completions?key=sk-secret", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "/v1/chat/completions?..."}},
+		{"https://www.dmxapi.cn/#/token", BaseURLInfo{URL: "https://www.dmxapi.cn", Stripped: "#/token"}},
+		// 子路径部署
+		{"https://gw.example.com/openai/v1", BaseURLInfo{URL: "https://gw.example.com/openai", Subpath: "/openai"}},
+		{"https://gw.example.com/openai/v1/chat/completions", BaseURLInfo{URL: "https://gw.example.com/openai", Stripped: "/v1/chat/completions", Subpath: "/openai"}},
+		{"https://gw.example.com/openai/console", BaseURLInfo{URL: "https://gw.example.com/openai/console", Subpath: "/openai/console"}},
+		// 大写的协议名
+		{"HTTPS://www.dmxapi.cn/v1", BaseURLInfo{URL: "https://www.dmxapi.cn"}},
+		{"HTTP://gw.example.com", BaseURLInfo{URL: "http://gw.example.com", Insecure: true}},
+		// 不安全的 http 地址（本机地址除外）
+		{"http://www.dmxapi.cn/v1", BaseURLInfo{URL: "http://www.dmxapi.cn", Insecure: true}},
+		{"http://192.168.1.10:3000", BaseURLInfo{URL: "http://192.168.1.10:3000", Insecure: true}},
+		{"http://localhost:8089", BaseURLInfo{URL: "http://localhost:8089"}},
+		{"http://127.0.0.1:8089/v1", BaseURLInfo{URL: "http://127.0.0.1:8089"}},
+		{"http://[::1]:8089", BaseURLInfo{URL: "http://[::1]:8089"}},
+		// 无法解析时只做规范化
+		{"www.dmxapi.cn/v1", BaseURLInfo{URL: "www.dmxapi.cn"}},
+	}
+	for _, tt := range tests {
+		if got := AnalyzeBaseURL(tt.in); got != tt.want {
+			t.Errorf("AnalyzeBaseURL(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"dmxapi-config/internal/debuglog"
+)
+
+// builtinCatalogData 内置模型目录，更新模型时修改 catalog.json 并递增 version
+//
+//go:embed catalog.json
+var builtinCatalogData []byte
+
+// catalogCacheFile 从网关刷新后的模型目录缓存（位于状态目录）
+const catalogCacheFile = "model-catalog.json"
+
+// vendorPrefixes 刷新时为内置目录中没有的模型按名称前缀推断厂商
+var vendorPrefixes = []struct{ prefix, vendor string }{
+	{"claude", "Anthropic"},
+	{"gemini", "Google"},
+	{"gpt", "OpenAI"},
+	{"codex", "OpenAI"},
+	{"o1", "OpenAI"},
+	{"o3", "OpenAI"},
+	{"o4", "OpenAI"},
+	{"deepseek", "DeepSeek"},
+	{"qwen", "Alibaba"},
+	{"kimi", "Moonshot"},
+	{"moonshot", "Moonshot"},
+	{"glm", "Zhipu"},
+}
+
+// Catalog 模型目录：网关无法访问时用于浏览、搜索和校验模型名称
+type Catalog struct {
+	Version     int            `json:"version"`                // 内置目录版本，内置目录更新后旧缓存自动失效
+	Updated     string         `json:"updated"`                // 内置目录的更新日期
+	Source      string         `json:"source,omitempty"`       // 刷新时使用的网关地址，内置目录为空
+	RefreshedAt time.Time      `json:"refreshed_at,omitempty"` // 从网关刷新的时间，内置目录为零值
+	Models      []CatalogModel `json:"models"`
+}
+
+// CatalogModel 模型目录中的一个模型
+type CatalogModel struct {
+	ID           string   `json:"id"`
+	Vendor       string   `json:"vendor,omitempty"`       // 厂商，如 Anthropic，未知时为空
+	Provider     string   `json:"provider"`               // provider 类型名称，如 anthropic
+	Context      int      `json:"context,omitempty"`      // 上下文长度（token），未知时为 0
+	Output       int      `json:"output,omitempty"`       // 最大输出长度（token），未知时为 0
+	Capabilities []string `json:"capabilities,omitempty"` // image（图片输入）、reasoning（推理输出）、tools（工具调用）
+	Tier         string   `json:"tier,omitempty"`         // 价格档位：budget、standard、premium，未知时为空
+}
+
+// ProviderType 返回模型的 provider 类型，目录中的名称无法识别时按模型名称判断
+func (m CatalogModel) ProviderType() ProviderType {
+	if pType, ok := ParseProviderType(m.Provider); ok {
+		return pType
+	}
+	return classifyByPrefix(m.ID)
+}
+
+// BuiltinCatalog 返回内置模型目录（每次返回新的副本）
+func BuiltinCatalog() *Catalog {
+	var c Catalog
+	if err := json.Unmarshal(builtinCatalogData, &c); err != nil {
+		// catalog.json 随程序编译，格式错误属于构建问题
+		panic("config: invalid catalog.json: " + err.Error())
+	}
+	return &c
+}
+
+// BuiltinModels 返回内置模型目录中的模型 ID
+func BuiltinModels() []string {
+	return BuiltinCatalog().IDs()
+}
+
+// LoadCatalog 返回最近一次从 source 网关刷新的模型目录；没有缓存、缓存无法读取、
+// 缓存来自其他网关或内置目录版本更新时返回内置目录
+func LoadCatalog(source string) *Catalog {
+	builtin := BuiltinCatalog()
+	path, err := catalogCachePath()
+	if err != nil {
+		return builtin
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return builtin
+	}
+	var cached Catalog
+	if err := json.Unmarshal(data, &cached); err != nil || len(cached.Models) == 0 {
+		debuglog.Printf("config", "catalog: ignoring unreadable cache %s", path)
+		return builtin
+	}
+	if cached.Version < builtin.Version {
+		debuglog.Printf("config", "catalog: cache version %d older than built-in %d, ignored", cached.Version, builtin.Version)
+		return builtin
+	}
+	if NormalizeBaseURL(cached.Source) != NormalizeBaseURL(source) {
+		// 不同网关提供的模型不同，其他网关的目录无法用于校验
+		debuglog.Printf("config", "catalog: cache refreshed from %s, not %s, ignored", cached.Source, source)
+		return builtin
+	}
+	debuglog.Printf("config", "catalog: using cache refreshed at %s (%d models)", cached.RefreshedAt.Format(time.RFC3339), len(cached.Models))
+	return &cached
+}
+
+// SaveCatalog 保存从网关刷新的模型目录，供离线时使用
+func SaveCatalog(c *Catalog) error {
+	path, err := catalogCachePath()
+	if err != nil {
+		return err
+	}
+	if err := EnsureDir(path); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// catalogCachePath 返回模型目录缓存文件路径
+func catalogCachePath() (string, error) {
+	dir, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, catalogCacheFile), nil
+}
+
+// IsRefreshed 判断目录是否来自网关刷新
+func (c *Catalog) IsRefreshed() bool {
+	return !c.RefreshedAt.IsZero()
+}
+
+// IDs 返回目录中所有模型 ID
+func (c *Catalog) IDs() []string {
+	ids := make([]string, len(c.Models))
+	for i, m := range c.Models {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+// Lookup 按模型 ID 查找（优先精确匹配，其次不区分大小写）
+func (c *Catalog) Lookup(id string) (CatalogModel, bool) {
+	for _, m := range c.Models {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	for _, m := range c.Models {
+		if strings.EqualFold(m.ID, id) {
+			return m, true
+		}
+	}
+	return CatalogModel{}, false
+}
+
+// RefreshCatalog 按网关 /v1/models 返回的模型生成新目录：只保留网关提供的模型，
+// 内置目录中已有的模型沿用其元数据，其余模型按名称推断厂商和 provider 类型并排在后面
+func RefreshCatalog(gatewayModels []string, source string) *Catalog {
+	c := BuiltinCatalog()
+	available := make(map[string]bool, len(gatewayModels))
+	for _, id := range gatewayModels {
+		available[id] = true
+	}
+
+	refreshed := &Catalog{Version: c.Version, Updated: c.Updated, Source: source, RefreshedAt: time.Now()}
+	known := make(map[string]bool)
+	for _, m := range c.Models {
+		if available[m.ID] {
+			refreshed.Models = append(refreshed.Models, m)
+			known[m.ID] = true
+		}
+	}
+	var unknown []CatalogModel
+	for _, id := range gatewayModels {
+		if known[id] {
+			continue
+		}
+		known[id] = true
+		unknown = append(unknown, CatalogModel{ID: id, Vendor: vendorOf(id), Provider: classifyByPrefix(id).String()})
+	}
+	slices.SortFunc(unknown, func(a, b CatalogModel) int {
+		if a.Vendor != b.Vendor {
+			// 厂商未知的模型排在最后
+			if a.Vendor == "" || b.Vendor == "" {
+				return strings.Compare(b.Vendor, a.Vendor)
+			}
+			return strings.Compare(a.Vendor, b.Vendor)
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	refreshed.Models = append(refreshed.Models, unknown...)
+	debuglog.Printf("config", "catalog: refreshed from %s, %d models (%d not in built-in catalog)", source, len(refreshed.Models), len(unknown))
+	return refreshed
+}
+
+// vendorOf 按模型名称前缀推断厂商，无法判断时返回空字符串
+func vendorOf(id string) string {
+	name := strings.ToLower(id)
+	for _, v := range vendorPrefixes {
+		if strings.HasPrefix(name, v.prefix) {
+			return v.vendor
+		}
+	}
+	return ""
+}
+
+var (
+	activeCatalogMu sync.RWMutex
+	activeCatalog   = BuiltinCatalog()
+)
+
+// UseCatalog 设置 ClassifyModel 查询的模型目录（默认为内置目录）
+func UseCatalog(c *Catalog) {
+	activeCatalogMu.Lock()
+	defer activeCatalogMu.Unlock()
+	activeCatalog = c
+}
+
+// lookupActive 在当前使用的模型目录中查找模型
+func lookupActive(id string) (CatalogModel, bool) {
+	activeCatalogMu.RLock()
+	defer activeCatalogMu.RUnlock()
+	return activeCatalog.Lookup(id)
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadCatalog(t *testing.T) {
+	setupHome(t)
+	builtin := BuiltinCatalog()
+	if got := LoadCatalog("https://www.dmxapi.cn"); got.IsRefreshed() || len(got.Models) != len(builtin.Models) {
+		t.Errorf("LoadCatalog without cache = %d models (refreshed %v), want the built-in catalog", len(got.Models), got.IsRefreshed())
+	}
+
+	refreshed := RefreshCatalog([]string{"gpt-5", "my-model"}, "https://www.dmxapi.cn")
+	if err := SaveCatalog(refreshed); err != nil {
+		t.Fatal(err)
+	}
+	got := LoadCatalog("https://www.dmxapi.cn")
+	if !got.IsRefreshed() || !reflect.DeepEqual(got.IDs(), []string{"gpt-5", "my-model"}) || got.Source != "https://www.dmxapi.cn" {
+		t.Errorf("LoadCatalog = %v from %q (refreshed %v), want the cache", got.IDs(), got.Source, got.IsRefreshed())
+	}
+	// 地址末尾的 / 和 /v1 不影响匹配
+	if got := LoadCatalog("https://www.dmxapi.cn/v1/"); !got.IsRefreshed() {
+		t.Error("LoadCatalog ignored the cache for the same gateway with a /v1 suffix")
+	}
+
+	// 其他网关刷新的目录不能用于当前网关
+	for _, source := range []string{"https://gw.example.com", ""} {
+		if got := LoadCatalog(source); got.IsRefreshed() || len(got.Models) != len(builtin.Models) {
+			t.Errorf("LoadCatalog(%q) used the cache refreshed from %s", source, refreshed.Source)
+		}
+	}
+
+	// 内置目录版本更新后旧缓存失效
+	refreshed.Version = builtin.Version - 1
+	if err := SaveCatalog(refreshed); err != nil {
+		t.Fatal(err)
+	}
+	if got := LoadCatalog("https://www.dmxapi.cn"); got.IsRefreshed() {
+		t.Errorf("LoadCatalog used a cache with version %d older than built-in %d", refreshed.Version, builtin.Version)
+	}
+
+	path, _ := catalogCachePath()
+	writeTestJSON(t, path, map[string]interface{}{"version": builtin.Version, "source": "https://www.dmxapi.cn", "models": []string{}})
+	if got := LoadCatalog("https://www.dmxapi.cn"); got.IsRefreshed() {
+		t.Error("LoadCatalog used an empty cache")
+	}
+}
+
+func TestRefreshCatalog(t *testing.T) {
+	builtin := BuiltinCatalog()
+	known := builtin.Models[0]
+	start := time.Now()
+	c := RefreshCatalog([]string{"zeta-model", "qwen-max", "gemini-9-pro", "alpha-model", known.ID, "claude-x", "qwen-max"}, "https://gw.example.com")
+
+	if c.Version != builtin.Version || c.Source != "https://gw.example.com" || c.RefreshedAt.Before(start) {
+		t.Errorf("catalog header = version %d, source %q, refreshed at %v", c.Version, c.Source, c.RefreshedAt)
+	}
+	// 内置目录中的模型在前并保留元数据，其余按厂商排序，厂商未知的排在最后，重复项只保留一个
+	want := []string{known.ID, "qwen-max", "claude-x", "gemini-9-pro", "alpha-model", "zeta-model"}
+	if got := c.IDs(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("IDs = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(c.Models[0], known) {
+		t.Errorf("built-in metadata lost: %+v, want %+v", c.Models[0], known)
+	}
+	tests := []struct {
+		id, vendor string
+		pType      ProviderType
+	}{
+		{"qwen-max", "Alibaba", ProviderOpenAI},
+		{"claude-x", "Anthropic", ProviderAnthropic},
+		{"gemini-9-pro", "Google", ProviderGoogle},
+		{"alpha-model", "", ProviderOpenAI},
+	}
+	for _, tt := range tests {
+		m, ok := c.Lookup(tt.id)
+		if !ok || m.Vendor != tt.vendor || m.ProviderType() != tt.pType {
+			t.Errorf("Lookup(%q) = %+v, %v; want vendor %q, provider %v", tt.id, m, ok, tt.vendor, tt.pType)
+		}
+	}
+}
+
+func TestCatalogLookup(t *testing.T) {
+	c := &Catalog{Models: []CatalogModel{
+		{ID: "DeepSeek-V3", Provider: "openai"},
+		{ID: "deepseek-v3", Provider: "anthropic"},
+		{ID: "GPT-5", Provider: "openai-responses"},
+	}}
+	tests := []struct {
+		id, want, provider string
+		ok                 bool
+	}{
+		{"deepseek-v3", "deepseek-v3", "anthropic", true}, // 精确匹配优先
+		{"DEEPSEEK-V3", "DeepSeek-V3", "openai", true},
+		{"gpt-5", "GPT-5", "openai-responses", true},
+		{"gpt-5-mini", "", "", false},
+	}
+	for _, tt := range tests {
+		m, ok := c.Lookup(tt.id)
+		if ok != tt.ok || m.ID != tt.want || m.Provider != tt.provider {
+			t.Errorf("Lookup(%q) = %+v, %v; want %q (%s)", tt.id, m, ok, tt.want, tt.provider)
+		}
+	}
+}
+
+func TestClassifyModelUsesCatalog(t *testing.T) {
+	t.Cleanup(func() { UseCatalog(BuiltinCatalog()) })
+	UseCatalog(&Catalog{Models: []CatalogModel{{ID: "my-claude-proxy", Provider: "anthropic"}}})
+	if got := ClassifyModel("My-Claude-Proxy"); got != ProviderAnthropic {
+		t.Errorf("ClassifyModel(My-Claude-Proxy) = %v, want anthropic from the catalog", got)
+	}
+	if got := ClassifyModel("gemini-2.5-pro"); got != ProviderGoogle {
+		t.Errorf("ClassifyModel(gemini-2.5-pro) = %v, want google by prefix", got)
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"regexp"
 	"strings"
 )
@@ -28,6 +29,30 @@ const (
 	ProviderOpenAIResponses
 )
 
+// providerTypeNames provider 类型在模板等外部文件中使用的名称
+var providerTypeNames = map[ProviderType]string{
+	ProviderAnthropic:       "anthropic",
+	ProviderGoogle:          "google",
+	ProviderOpenAI:          "openai",
+	ProviderOpenAIResponses: "openai-responses",
+}
+
+// String 返回 provider 类型名称，如 "anthropic"
+func (p ProviderType) String() string {
+	return providerTypeNames[p]
+}
+
+// ParseProviderType 解析 provider 类型名称（不区分大小写），无法识别时返回 false
+func ParseProviderType(name string) (ProviderType, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for pType, n := range providerTypeNames {
+		if n == name {
+			return pType, true
+		}
+	}
+	return 0, false
+}
+
 // ProviderInfo 存储 provider 元信息
 type ProviderInfo struct {
 	ID   string
@@ -35,22 +60,38 @@ type ProviderInfo struct {
 	Name string
 }
 
-// GetProviderInfo 根据类型返回 provider 信息
+// GetProviderInfo 根据类型返回 provider 信息，ID 和显示名称来自当前命名空间（见 UseNamespace）
 func GetProviderInfo(pType ProviderType) ProviderInfo {
+	ns := CurrentNamespace()
+	info := ProviderInfo{ID: ns.ProviderID(pType), Name: ns.ProviderName(pType)}
 	switch pType {
 	case ProviderAnthropic:
-		return ProviderInfo{ID: "dmxapi-anthropic", NPM: "@ai-sdk/anthropic", Name: "DMXAPI Claude"}
+		info.NPM = "@ai-sdk/anthropic"
 	case ProviderGoogle:
-		return ProviderInfo{ID: "dmxapi-google", NPM: "@ai-sdk/google", Name: "DMXAPI Gemini"}
+		info.NPM = "@ai-sdk/google"
 	case ProviderOpenAIResponses:
-		return ProviderInfo{ID: "dmxapi-openai-responses", NPM: "@ai-sdk/openai", Name: "DMXAPI OpenAI Responses"}
+		info.NPM = "@ai-sdk/openai"
 	default:
-		return ProviderInfo{ID: "dmxapi-openai", NPM: "@ai-sdk/openai-compatible", Name: "DMXAPI OpenAI"}
+		info.NPM = "@ai-sdk/openai-compatible"
 	}
+	return info
+}
+
+// ProviderTypeByID 根据当前命名空间中的 provider ID 反查 provider 类型（如 dmxapi-anthropic），无法识别时返回 false
+func ProviderTypeByID(id string) (ProviderType, bool) {
+	return CurrentNamespace().ParseProviderID(id)
 }
 
-// ClassifyModel 根据模型名称前缀判断 provider 类型
+// ClassifyModel 判断模型的 provider 类型：优先使用模型目录中的记录，目录中没有时按模型名称前缀判断
 func ClassifyModel(modelName string) ProviderType {
+	if m, ok := lookupActive(modelName); ok {
+		return m.ProviderType()
+	}
+	return classifyByPrefix(modelName)
+}
+
+// classifyByPrefix 根据模型名称前缀判断 provider 类型
+func classifyByPrefix(modelName string) ProviderType {
 	name := strings.ToLower(modelName)
 	if strings.HasPrefix(name, "claude") {
 		return ProviderAnthropic
@@ -67,7 +108,9 @@ func ClassifyModel(modelName string) ProviderType {
 
 // OpenCodeConfig 表示 opencode.json 配置文件结构
 type OpenCodeConfig struct {
-	Provider map[string]Provider `json:"provider"`
+	Provider   map[string]Provider `json:"provider"`
+	Model      string              `json:"model,omitempty"`       // 默认模型，格式为 provider/model
+	SmallModel string              `json:"small_model,omitempty"` // 轻量任务（如生成标题）使用的模型
 }
 
 // Provider 表示一个API提供者配置
@@ -80,25 +123,87 @@ type Provider struct {
 
 // ProviderOptions 提供者选项
 type ProviderOptions struct {
-	BaseURL string `json:"baseURL"`
-	APIKey  string `json:"apiKey"`
+	BaseURL string            `json:"baseURL"`
+	APIKey  string            `json:"apiKey,omitempty"`  // 为空时 opencode 使用 auth.json 中的认证条目
+	Headers map[string]string `json:"headers,omitempty"` // 随每个请求发送的自定义请求头（见 Headers）
 }
 
 // Model 模型配置
+// 除 name 和 options 外的其他 opencode 模型字段（如 limit）保存在 Extra 中，读写时原样保留
 type Model struct {
-	Name string `json:"name"`
+	Name    string                 `json:"name"`
+	Options map[string]interface{} `json:"options,omitempty"`
+	Extra   map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON 将 Extra 中的字段与 name、options 一起输出
+func (m Model) MarshalJSON() ([]byte, error) {
+	out := make(map[string]interface{}, len(m.Extra)+2)
+	for k, v := range m.Extra {
+		out[k] = v
+	}
+	out["name"] = m.Name
+	if len(m.Options) > 0 {
+		out["options"] = m.Options
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON 读取 name、options，其余字段存入 Extra
+func (m *Model) UnmarshalJSON(data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = Model{}
+	if name, ok := raw["name"].(string); ok {
+		m.Name = name
+	}
+	if options, ok := raw["options"].(map[string]interface{}); ok {
+		m.Options = options
+	}
+	delete(raw, "name")
+	delete(raw, "options")
+	if len(raw) > 0 {
+		m.Extra = raw
+	}
+	return nil
+}
+
+// ModelSpec 描述一个待写入配置的模型（模板导入等场景使用）
+type ModelSpec struct {
+	ID       string        // 模型 ID
+	Model    Model         // 写入 opencode.json 的模型配置，Name 为空时使用 ID
+	Provider *ProviderType // 指定路由的 provider 类型，为 nil 时按模型名称自动判断
 }
 
 // NewDMXAPIConfig 创建 DMXAPI 配置（基于模型名称路由）
 func NewDMXAPIConfig(url, apiKey string, models []string) *OpenCodeConfig {
+	specs := make([]ModelSpec, 0, len(models))
+	for _, m := range models {
+		specs = append(specs, ModelSpec{ID: m})
+	}
+	return NewDMXAPIConfigFromSpecs(url, apiKey, specs)
+}
+
+// NewDMXAPIConfigFromSpecs 按模型描述创建 DMXAPI 配置
+// 支持逐个模型指定 provider 类型和附加字段
+func NewDMXAPIConfigFromSpecs(url, apiKey string, specs []ModelSpec) *OpenCodeConfig {
 	// 按 provider 类型分组模型
 	modelGroups := make(map[ProviderType]map[string]Model)
-	for _, m := range models {
-		pType := ClassifyModel(m)
+	for _, spec := range specs {
+		pType := ClassifyModel(spec.ID)
+		if spec.Provider != nil {
+			pType = *spec.Provider
+		}
+		model := spec.Model
+		if model.Name == "" {
+			model.Name = spec.ID
+		}
 		if modelGroups[pType] == nil {
 			modelGroups[pType] = make(map[string]Model)
 		}
-		modelGroups[pType][m] = Model{Name: m}
+		modelGroups[pType][spec.ID] = model
 	}
 
 	// 为每组模型创建对应的 provider
@@ -128,6 +233,60 @@ func NewDMXAPIConfig(url, apiKey string, models []string) *OpenCodeConfig {
 	}
 }
 
+// QualifiedModelID 返回模型在配置中的完整 ID（provider/model），模型不存在时返回 false
+func (c *OpenCodeConfig) QualifiedModelID(model string) (string, bool) {
+	for id, p := range c.Provider {
+		if _, ok := p.Models[model]; ok {
+			return id + "/" + model, true
+		}
+	}
+	return "", false
+}
+
+// SetInputModalities 设置模型的输入模态（opencode 的 modalities.input），用于标记模型能否接收图片
+// 已有的 modalities.output 保持不变，没有时补充为 ["text"]；模型不存在时返回 false
+func (c *OpenCodeConfig) SetInputModalities(model string, input []string) bool {
+	for id, p := range c.Provider {
+		m, ok := p.Models[model]
+		if !ok {
+			continue
+		}
+		modalities, _ := m.Extra["modalities"].(map[string]interface{})
+		updated := map[string]interface{}{"input": input, "output": []string{"text"}}
+		if output, ok := modalities["output"]; ok {
+			updated["output"] = output
+		}
+		extra := make(map[string]interface{}, len(m.Extra)+1)
+		for k, v := range m.Extra {
+			extra[k] = v
+		}
+		extra["modalities"] = updated
+		m.Extra = extra
+		c.Provider[id].Models[model] = m
+		return true
+	}
+	return false
+}
+
+// SetReasoning 设置模型的 reasoning 字段（opencode 据此展示思考内容），模型不存在时返回 false
+func (c *OpenCodeConfig) SetReasoning(model string, reasoning bool) bool {
+	for id, p := range c.Provider {
+		m, ok := p.Models[model]
+		if !ok {
+			continue
+		}
+		extra := make(map[string]interface{}, len(m.Extra)+1)
+		for k, v := range m.Extra {
+			extra[k] = v
+		}
+		extra["reasoning"] = reasoning
+		m.Extra = extra
+		c.Provider[id].Models[model] = m
+		return true
+	}
+	return false
+}
+
 // AuthConfig 表示 auth.json 认证配置
 type AuthConfig map[string]AuthEntry
 
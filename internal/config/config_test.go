@@ -0,0 +1,80 @@
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testKey = "sk-configtestkey1234567890"
+
+// setupHome 把 HOME 指向临时目录，并在测试结束后恢复默认命名空间和消息输出
+func setupHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	SetMessageOutput(io.Discard)
+	t.Cleanup(func() {
+		UseNamespace(DefaultNamespace)
+		SetMessageOutput(os.Stdout)
+	})
+	return home
+}
+
+// writeTestJSON 把 v 写入 path（自动创建目录）
+func writeTestJSON(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeOpenCode 写入临时 HOME 中的 opencode.json 和 auth.json（auth 为 nil 时不写）
+func writeOpenCode(t *testing.T, cfg OpenCodeConfig, auth AuthConfig) {
+	t.Helper()
+	configPath, _ := GetConfigPath()
+	writeTestJSON(t, configPath, cfg)
+	if auth != nil {
+		authPath, _ := GetAuthPath()
+		writeTestJSON(t, authPath, auth)
+	}
+}
+
+// readOpenCode 读取临时 HOME 中的 opencode.json 和 auth.json，文件不存在时返回零值
+func readOpenCode(t *testing.T) (OpenCodeConfig, AuthConfig) {
+	t.Helper()
+	var cfg OpenCodeConfig
+	var auth AuthConfig
+	configPath, _ := GetConfigPath()
+	if data, err := os.ReadFile(configPath); err == nil {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			t.Fatalf("parse opencode.json: %v", err)
+		}
+	}
+	authPath, _ := GetAuthPath()
+	if data, err := os.ReadFile(authPath); err == nil {
+		if err := json.Unmarshal(data, &auth); err != nil {
+			t.Fatalf("parse auth.json: %v", err)
+		}
+	}
+	return cfg, auth
+}
+
+// testProvider 创建包含指定模型的 provider
+func testProvider(npm, name, baseURL string, models ...string) Provider {
+	p := Provider{NPM: npm, Name: name, Options: ProviderOptions{BaseURL: baseURL, APIKey: testKey}, Models: map[string]Model{}}
+	for _, m := range models {
+		p.Models[m] = Model{Name: m}
+	}
+	return p
+}
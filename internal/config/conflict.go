@@ -0,0 +1,182 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/i18n"
+)
+
+// ConflictKind 写入配置时与现有配置冲突的类型
+type ConflictKind string
+
+const (
+	ConflictModel    ConflictKind = "model"    // 模型 ID 同时出现在其他 provider 中，opencode 的模型列表中会出现两个同名模型
+	ConflictProvider ConflictKind = "provider" // 要写入的 provider ID 已被其他配置（不是本工具创建的）占用，写入时会被替换
+	ConflictAuth     ConflictKind = "auth"     // auth.json 中已有同一 provider 的其他认证信息，写入时会被替换
+)
+
+// Conflict 写入配置前发现的一处冲突
+type Conflict struct {
+	Kind     ConflictKind
+	ID       string // 模型 ID（ConflictModel）或 provider ID
+	Provider string // 本次写入的 provider ID
+	Other    string // 冲突的一方：其他 provider 的 ID（ConflictModel）、现有 provider 的名称和地址（ConflictProvider）或认证类型（ConflictAuth）
+}
+
+// Message 冲突的说明文字
+func (c Conflict) Message() string {
+	switch c.Kind {
+	case ConflictModel:
+		return i18n.T("conflict.model", c.ID, c.Other, c.Provider)
+	case ConflictProvider:
+		return i18n.T("conflict.provider", c.ID, c.Other)
+	}
+	return i18n.T("conflict.auth", c.ID, c.Other)
+}
+
+// ConflictResolution 处理冲突的方式
+type ConflictResolution string
+
+const (
+	ResolveKeepBoth     ConflictResolution = "keep"     // 按计划写入：同名模型两边都保留，替换同 ID 的 provider 和认证条目
+	ResolvePreferDMXAPI ConflictResolution = "dmxapi"   // 按计划写入，并从其他 provider 中删除同名模型
+	ResolvePreferOthers ConflictResolution = "existing" // 保留现有配置：不写入有冲突的模型、provider 和认证条目
+	ResolveCancel       ConflictResolution = "cancel"   // 不写入任何文件
+)
+
+// ParseConflictResolution 解析 --on-conflict 参数，空字符串表示运行时询问
+func ParseConflictResolution(s string) (ConflictResolution, error) {
+	switch r := ConflictResolution(s); r {
+	case "", ResolveKeepBoth, ResolvePreferDMXAPI, ResolvePreferOthers, ResolveCancel:
+		return r, nil
+	}
+	return "", fmt.Errorf(i18n.T("conflict.unknown_resolution"), s)
+}
+
+// DetectConflicts 分析现有 opencode.json 的全部 provider 和 auth.json，找出写入 cfg 时的冲突：
+// 其他 provider 中的同名模型、被占用的 provider ID 和不同的认证条目。结果按类型和 ID 排序
+func (w *Writer) DetectConflicts(cfg *OpenCodeConfig) []Conflict {
+	var existing OpenCodeConfig
+	if path, err := GetConfigPath(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			if err := json.Unmarshal(data, &existing); err != nil {
+				debuglog.Printf("config", "conflict: failed to parse %s: %v", path, err)
+			}
+		}
+	}
+	var auth AuthConfig
+	if path, err := GetAuthPath(); err == nil {
+		auth = w.readExistingAuth(path)
+	}
+
+	ns := CurrentNamespace()
+	var conflicts []Conflict
+	for id, p := range cfg.Provider {
+		old, exists := existing.Provider[id]
+		shadowed := exists && !createdByTool(old, p)
+		if shadowed {
+			conflicts = append(conflicts, Conflict{Kind: ConflictProvider, ID: id, Provider: id, Other: fmt.Sprintf("%s, %s", old.Name, old.Options.BaseURL)})
+		}
+		if entry, ok := auth[id]; ok {
+			// 本工具创建的 provider 更换 API Key 属于正常更新，不算冲突
+			if entry.Type != "api" || (entry.Key != p.Options.APIKey && (!exists || shadowed)) {
+				conflicts = append(conflicts, Conflict{Kind: ConflictAuth, ID: id, Provider: id, Other: entry.Type})
+			}
+		}
+		for model := range p.Models {
+			for otherID, other := range existing.Provider {
+				// 本工具创建的 provider（包括其他命名空间）不算冲突，多个账号配置同一模型是预期的用法
+				if _, known := NamespaceOf(otherID); known || otherID == id || ns.Owns(otherID) {
+					continue
+				}
+				if _, ok := other.Models[model]; ok {
+					conflicts = append(conflicts, Conflict{Kind: ConflictModel, ID: model, Provider: id, Other: otherID})
+				}
+			}
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		a, b := conflicts[i], conflicts[j]
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Other < b.Other
+	})
+	for _, c := range conflicts {
+		debuglog.Printf("config", "conflict: %s %q (ours %s, other %s)", c.Kind, c.ID, c.Provider, c.Other)
+	}
+	return conflicts
+}
+
+// createdByTool 判断现有 provider 是否由本工具创建：SDK 相同，且显示名称或地址与本次写入的一致
+func createdByTool(old, p Provider) bool {
+	return old.NPM == p.NPM && (old.Name == p.Name || NormalizeBaseURL(old.Options.BaseURL) == NormalizeBaseURL(p.Options.BaseURL))
+}
+
+// ResolveConflicts 按选择的方式处理冲突，返回写入 auth.json 时需要跳过的 provider ID
+//   - ResolvePreferDMXAPI：之后 WriteConfig 合并时从其他 provider 中删除同名模型
+//   - ResolvePreferOthers：从 cfg 中去掉有冲突的模型和 provider（没有剩余模型的 provider 一并去掉），
+//     指向被去掉模型的默认模型清空；有冲突的认证条目保持不变，对应 provider 不写 options.apiKey
+//     （opencode 优先使用 options.apiKey，写入新 Key 会使保留的认证条目失效）
+func (w *Writer) ResolveConflicts(cfg *OpenCodeConfig, conflicts []Conflict, r ConflictResolution) (skipAuth []string) {
+	switch r {
+	case ResolvePreferDMXAPI:
+		for _, c := range conflicts {
+			if c.Kind == ConflictModel {
+				if w.dropModels == nil {
+					w.dropModels = make(map[string][]string)
+				}
+				w.dropModels[c.Other] = append(w.dropModels[c.Other], c.ID)
+			}
+		}
+	case ResolvePreferOthers:
+		for _, c := range conflicts {
+			switch c.Kind {
+			case ConflictModel:
+				delete(cfg.Provider[c.Provider].Models, c.ID)
+			case ConflictProvider:
+				delete(cfg.Provider, c.ID)
+				skipAuth = append(skipAuth, c.ID)
+			case ConflictAuth:
+				if p, ok := cfg.Provider[c.ID]; ok {
+					p.Options.APIKey = ""
+					cfg.Provider[c.ID] = p
+				}
+				skipAuth = append(skipAuth, c.ID)
+			}
+		}
+		for id, p := range cfg.Provider {
+			if len(p.Models) == 0 {
+				delete(cfg.Provider, id)
+				skipAuth = append(skipAuth, id)
+			}
+		}
+		for _, ref := range []*string{&cfg.Model, &cfg.SmallModel} {
+			if *ref != "" && !cfg.hasModelRef(*ref) {
+				*ref = ""
+			}
+		}
+	}
+	slices.Sort(skipAuth)
+	return slices.Compact(skipAuth)
+}
+
+// hasModelRef 判断 provider/model 形式的模型引用是否在配置中
+func (c *OpenCodeConfig) hasModelRef(ref string) bool {
+	for id, p := range c.Provider {
+		for model := range p.Models {
+			if id+"/"+model == ref {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// conflictFixture 现有配置：用户自己的 provider 中有同名模型，dmxapi-google 被其他配置占用，
+// dmxapi-openai 的认证条目是 OAuth
+func conflictFixture(t *testing.T) {
+	t.Helper()
+	writeOpenCode(t, OpenCodeConfig{
+		Provider: map[string]Provider{
+			"my-claude":     testProvider("@ai-sdk/anthropic", "My Claude", "https://example.com/v1", "claude-sonnet-4-5", "claude-opus-4-5"),
+			"my-gemini":     testProvider("@ai-sdk/google", "My Gemini", "https://example.com/v1beta", "gemini-2.5-pro"),
+			"dmxapi-google": testProvider("@ai-sdk/openai-compatible", "Other", "https://other.example.com/v1", "gemini-2.5-flash"),
+		},
+		Model: "my-claude/claude-opus-4-5",
+	}, AuthConfig{
+		"dmxapi-openai": {Type: "oauth"},
+		"my-claude":     {Type: "api", Key: "sk-mine"},
+	})
+}
+
+// conflictConfig 本次要写入的配置
+func conflictConfig() *OpenCodeConfig {
+	cfg := NewDMXAPIConfig("https://www.dmxapi.cn", testKey, []string{"claude-sonnet-4-5", "gemini-2.5-pro", "DeepSeek-V3", "gpt-5"})
+	cfg.Model = "dmxapi-anthropic/claude-sonnet-4-5"
+	cfg.SmallModel = "dmxapi-google/gemini-2.5-pro"
+	return cfg
+}
+
+func TestDetectConflicts(t *testing.T) {
+	setupHome(t)
+	conflictFixture(t)
+	got := NewWriter().DetectConflicts(conflictConfig())
+	want := []Conflict{
+		{Kind: ConflictProvider, ID: "dmxapi-google", Provider: "dmxapi-google", Other: "Other, https://other.example.com/v1"},
+		{Kind: ConflictModel, ID: "claude-sonnet-4-5", Provider: "dmxapi-anthropic", Other: "my-claude"},
+		{Kind: ConflictModel, ID: "gemini-2.5-pro", Provider: "dmxapi-google", Other: "my-gemini"},
+		{Kind: ConflictAuth, ID: "dmxapi-openai", Provider: "dmxapi-openai", Other: "oauth"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectConflicts =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestResolveConflicts(t *testing.T) {
+	tests := []struct {
+		resolution ConflictResolution
+		skipAuth   []string
+		providers  []string // 写入后 opencode.json 中的 provider
+		userClaude []string // 写入后 my-claude 中的模型
+		userGemini []string // 写入后 my-gemini 中的模型
+		model      string
+		smallModel string
+	}{
+		{
+			resolution: ResolveKeepBoth,
+			providers:  []string{"dmxapi-anthropic", "dmxapi-google", "dmxapi-openai", "dmxapi-openai-responses", "my-claude", "my-gemini"},
+			userClaude: []string{"claude-opus-4-5", "claude-sonnet-4-5"},
+			userGemini: []string{"gemini-2.5-pro"},
+			model:      "dmxapi-anthropic/claude-sonnet-4-5",
+			smallModel: "dmxapi-google/gemini-2.5-pro",
+		},
+		{
+			resolution: ResolvePreferDMXAPI,
+			providers:  []string{"dmxapi-anthropic", "dmxapi-google", "dmxapi-openai", "dmxapi-openai-responses", "my-claude", "my-gemini"},
+			userClaude: []string{"claude-opus-4-5"},
+			userGemini: []string{},
+			model:      "dmxapi-anthropic/claude-sonnet-4-5",
+			smallModel: "dmxapi-google/gemini-2.5-pro",
+		},
+		{
+			// dmxapi-google 先因 provider 冲突被去掉，之后其中模型的冲突不再处理；
+			// dmxapi-anthropic 去掉同名模型后为空，一并去掉，指向它们的默认模型清空
+			resolution: ResolvePreferOthers,
+			skipAuth:   []string{"dmxapi-anthropic", "dmxapi-google", "dmxapi-openai"},
+			providers:  []string{"dmxapi-google", "dmxapi-openai", "dmxapi-openai-responses", "my-claude", "my-gemini"},
+			userClaude: []string{"claude-opus-4-5", "claude-sonnet-4-5"},
+			userGemini: []string{"gemini-2.5-pro"},
+			model:      "my-claude/claude-opus-4-5",
+		},
+		{
+			// 取消由调用方处理，ResolveConflicts 不做修改
+			resolution: ResolveCancel,
+			providers:  []string{"dmxapi-anthropic", "dmxapi-google", "dmxapi-openai", "dmxapi-openai-responses", "my-claude", "my-gemini"},
+			userClaude: []string{"claude-opus-4-5", "claude-sonnet-4-5"},
+			userGemini: []string{"gemini-2.5-pro"},
+			model:      "dmxapi-anthropic/claude-sonnet-4-5",
+			smallModel: "dmxapi-google/gemini-2.5-pro",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.resolution), func(t *testing.T) {
+			setupHome(t)
+			conflictFixture(t)
+			cfg := conflictConfig()
+			w := NewWriter()
+			skipAuth := w.ResolveConflicts(cfg, w.DetectConflicts(cfg), tt.resolution)
+			if len(skipAuth) == 0 {
+				skipAuth = nil
+			}
+			if !reflect.DeepEqual(skipAuth, tt.skipAuth) {
+				t.Errorf("skipAuth = %v, want %v", skipAuth, tt.skipAuth)
+			}
+
+			ids := slices.DeleteFunc(GetProviderIDs(cfg), func(id string) bool { return slices.Contains(skipAuth, id) })
+			if _, err := w.WriteAuth(NewAuthConfig(ids, testKey)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.WriteConfig(cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			got, auth := readOpenCode(t)
+			if providers := sortedKeys(got.Provider); !reflect.DeepEqual(providers, tt.providers) {
+				t.Errorf("providers = %v, want %v", providers, tt.providers)
+			}
+			if models := sortedKeys(got.Provider["my-claude"].Models); !reflect.DeepEqual(models, tt.userClaude) {
+				t.Errorf("my-claude models = %v, want %v", models, tt.userClaude)
+			}
+			if models := sortedKeys(got.Provider["my-gemini"].Models); !reflect.DeepEqual(models, tt.userGemini) {
+				t.Errorf("my-gemini models = %v, want %v", models, tt.userGemini)
+			}
+			if got.Model != tt.model || got.SmallModel != tt.smallModel {
+				t.Errorf("model = %q, small_model = %q; want %q, %q", got.Model, got.SmallModel, tt.model, tt.smallModel)
+			}
+
+			// 保留现有配置时被占用的 provider 和 OAuth 认证保持原样
+			if tt.resolution == ResolvePreferOthers {
+				if got.Provider["dmxapi-google"].Name != "Other" {
+					t.Errorf("dmxapi-google replaced: %+v", got.Provider["dmxapi-google"])
+				}
+				if auth["dmxapi-openai"].Type != "oauth" {
+					t.Errorf("dmxapi-openai auth replaced: %+v", auth["dmxapi-openai"])
+				}
+				// options.apiKey 优先于 auth.json，保留的认证条目所在 provider 不能写入新 Key
+				if key := got.Provider["dmxapi-openai"].Options.APIKey; key != "" {
+					t.Errorf("dmxapi-openai options.apiKey = %q, want empty so the kept auth entry is used", key)
+				}
+			} else if auth["dmxapi-openai"] != (AuthEntry{Type: "api", Key: testKey}) {
+				t.Errorf("dmxapi-openai auth = %+v", auth["dmxapi-openai"])
+			}
+			if key := got.Provider["dmxapi-openai-responses"].Options.APIKey; key != testKey {
+				t.Errorf("dmxapi-openai-responses options.apiKey = %q, want the new key", key)
+			}
+			if auth["my-claude"].Key != "sk-mine" {
+				t.Errorf("my-claude auth changed: %+v", auth["my-claude"])
+			}
+		})
+	}
+}
+
+func TestPreferOthersKeepsAuthKey(t *testing.T) {
+	setupHome(t)
+	const otherKey = "sk-otherkey1234567"
+	writeOpenCode(t, OpenCodeConfig{}, AuthConfig{"dmxapi-anthropic": {Type: "api", Key: otherKey}})
+	cfg := NewDMXAPIConfig("https://www.dmxapi.cn", testKey, []string{"claude-sonnet-4-5", "gpt-5"})
+	w := NewWriter()
+	skipAuth := w.ResolveConflicts(cfg, w.DetectConflicts(cfg), ResolvePreferOthers)
+	if !reflect.DeepEqual(skipAuth, []string{"dmxapi-anthropic"}) {
+		t.Fatalf("skipAuth = %v, want [dmxapi-anthropic]", skipAuth)
+	}
+	ids := slices.DeleteFunc(GetProviderIDs(cfg), func(id string) bool { return slices.Contains(skipAuth, id) })
+	if _, err := w.WriteAuth(NewAuthConfig(ids, testKey)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不写 apiKey 字段（空字符串同样会覆盖 auth.json）
+	configPath, _ := GetConfigPath()
+	var raw struct {
+		Provider map[string]struct {
+			Options map[string]interface{} `json:"options"`
+		} `json:"provider"`
+	}
+	data, _ := os.ReadFile(configPath)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if key, ok := raw.Provider["dmxapi-anthropic"].Options["apiKey"]; ok {
+		t.Errorf("dmxapi-anthropic options.apiKey = %v, want no apiKey field", key)
+	}
+	if key := raw.Provider["dmxapi-openai-responses"].Options["apiKey"]; key != testKey {
+		t.Errorf("dmxapi-openai-responses options.apiKey = %v, want %q", key, testKey)
+	}
+	_, auth := readOpenCode(t)
+	if auth["dmxapi-anthropic"].Key != otherKey || auth["dmxapi-openai-responses"].Key != testKey {
+		t.Errorf("auth = %+v, want the existing anthropic key kept and the new key for openai-responses", auth)
+	}
+
+	// 读取现有配置时使用其他 provider 中的 Key
+	if existing := NewReader().ReadExistingConfig(); existing == nil || existing.APIKey != testKey {
+		t.Errorf("ReadExistingConfig = %+v, want API key %q", existing, testKey)
+	}
+}
+
+func TestCreatedByTool(t *testing.T) {
+	ours := testProvider("@ai-sdk/anthropic", "DMXAPI Claude", "https://www.dmxapi.cn/v1")
+	tests := []struct {
+		name string
+		old  Provider
+		want bool
+	}{
+		{"same name and url", testProvider("@ai-sdk/anthropic", "DMXAPI Claude", "https://www.dmxapi.cn/v1"), true},
+		{"renamed", testProvider("@ai-sdk/anthropic", "My Claude", "https://www.dmxapi.cn/v1"), true},
+		{"url without version", testProvider("@ai-sdk/anthropic", "My Claude", "https://www.dmxapi.cn"), true},
+		{"url changed", testProvider("@ai-sdk/anthropic", "DMXAPI Claude", "https://other.example.com/v1"), true},
+		{"other sdk", testProvider("@ai-sdk/openai-compatible", "DMXAPI Claude", "https://www.dmxapi.cn/v1"), false},
+		{"other name and url", testProvider("@ai-sdk/anthropic", "My Claude", "https://other.example.com/v1"), false},
+	}
+	for _, tt := range tests {
+		if got := createdByTool(tt.old, ours); got != tt.want {
+			t.Errorf("%s: createdByTool = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDetectAuthConflicts(t *testing.T) {
+	ours := testProvider("@ai-sdk/anthropic", "DMXAPI Claude", "https://www.dmxapi.cn/v1", "claude-sonnet-4-5")
+	foreign := testProvider("@ai-sdk/anthropic", "Other", "https://other.example.com/v1")
+	tests := []struct {
+		name     string
+		existing map[string]Provider
+		entry    AuthEntry
+		want     bool
+	}{
+		{"key update of our provider", map[string]Provider{"dmxapi-anthropic": ours}, AuthEntry{Type: "api", Key: "sk-oldkey12345678"}, false},
+		{"same key without provider", nil, AuthEntry{Type: "api", Key: testKey}, false},
+		{"other key without provider", nil, AuthEntry{Type: "api", Key: "sk-otherkey1234567"}, true},
+		{"other key on foreign provider", map[string]Provider{"dmxapi-anthropic": foreign}, AuthEntry{Type: "api", Key: "sk-otherkey1234567"}, true},
+		{"oauth on our provider", map[string]Provider{"dmxapi-anthropic": ours}, AuthEntry{Type: "oauth"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHome(t)
+			writeOpenCode(t, OpenCodeConfig{Provider: tt.existing}, AuthConfig{"dmxapi-anthropic": tt.entry})
+			cfg := NewDMXAPIConfig("https://www.dmxapi.cn", testKey, []string{"claude-sonnet-4-5"})
+			got := slices.ContainsFunc(NewWriter().DetectConflicts(cfg), func(c Conflict) bool { return c.Kind == ConflictAuth })
+			if got != tt.want {
+				t.Errorf("auth conflict = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectConflictsIgnoresOwnProviders(t *testing.T) {
+	setupHome(t)
+	if err := RememberNamespace(Namespace{Prefix: "work"}); err != nil {
+		t.Fatal(err)
+	}
+	writeOpenCode(t, OpenCodeConfig{Provider: map[string]Provider{
+		// 其他账号中的同名模型是预期的用法
+		"work-anthropic": testProvider("@ai-sdk/anthropic", "DMXAPI Claude (work)", "https://work.example.com/v1", "claude-sonnet-4-5"),
+		// 当前命名空间中按旧路由放在其他 provider 的模型会被本次写入替换
+		"dmxapi-openai": testProvider("@ai-sdk/openai-compatible", "DMXAPI OpenAI", "https://www.dmxapi.cn/v1", "claude-sonnet-4-5"),
+		"dmxapi":        testProvider("@ai-sdk/openai-compatible", "DMXAPI", "https://www.dmxapi.cn/v1", "claude-sonnet-4-5"),
+	}}, nil)
+	cfg := NewDMXAPIConfig("https://www.dmxapi.cn", testKey, []string{"claude-sonnet-4-5"})
+	if got := NewWriter().DetectConflicts(cfg); len(got) != 0 {
+		t.Errorf("DetectConflicts = %+v, want none", got)
+	}
+}
+
+func TestParseConflictResolution(t *testing.T) {
+	for _, s := range []string{"", "keep", "dmxapi", "existing", "cancel"} {
+		if r, err := ParseConflictResolution(s); err != nil || string(r) != s {
+			t.Errorf("ParseConflictResolution(%q) = %q, %v", s, r, err)
+		}
+	}
+	if _, err := ParseConflictResolution("Keep"); err == nil {
+		t.Error("ParseConflictResolution(Keep) succeeded")
+	}
+}
+
+// sortedKeys 返回 map 的键（排序后）
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
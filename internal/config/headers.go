@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"dmxapi-config/internal/i18n"
+)
+
+// headerNamePattern HTTP 请求头名称允许的字符（RFC 9110 token）
+var headerNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// reservedHeaders 不允许自定义的请求头（小写）：认证头由 API Key 生成，其余由 SDK 或 HTTP 库设置
+var reservedHeaders = map[string]bool{
+	"authorization":     true,
+	"x-api-key":         true,
+	"x-goog-api-key":    true,
+	"anthropic-version": true,
+	"content-type":      true,
+	"content-length":    true,
+	"host":              true,
+}
+
+// Headers 自定义请求头，写入 opencode.json 中 provider 的 options.headers，连接测试时同样发送
+// Global 用于所有 provider，Provider 按 provider 类型设置，同名时覆盖 Global
+type Headers struct {
+	Global   map[string]string
+	Provider map[ProviderType]map[string]string
+}
+
+// ParseHeader 解析 "Name: Value" 形式的请求头
+func ParseHeader(s string) (name, value string, err error) {
+	name, value, ok := strings.Cut(s, ":")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf(i18n.T("headers.bad_format"), s)
+	}
+	if err := ValidateHeader(name, value); err != nil {
+		return "", "", err
+	}
+	return name, value, nil
+}
+
+// ValidateHeader 检查请求头名称和取值：名称只能包含 token 字符且不能是认证等保留请求头，取值不能换行
+func ValidateHeader(name, value string) error {
+	if !headerNamePattern.MatchString(name) {
+		return fmt.Errorf(i18n.T("headers.bad_name"), name)
+	}
+	if reservedHeaders[strings.ToLower(name)] {
+		return fmt.Errorf(i18n.T("headers.reserved"), name)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf(i18n.T("headers.bad_value"), name)
+	}
+	return nil
+}
+
+// Set 设置请求头，pType 为 nil 时设置到所有 provider
+func (h *Headers) Set(pType *ProviderType, name, value string) {
+	if pType == nil {
+		if h.Global == nil {
+			h.Global = make(map[string]string)
+		}
+		h.Global[name] = value
+		return
+	}
+	if h.Provider == nil {
+		h.Provider = make(map[ProviderType]map[string]string)
+	}
+	if h.Provider[*pType] == nil {
+		h.Provider[*pType] = make(map[string]string)
+	}
+	h.Provider[*pType][name] = value
+}
+
+// Merge 返回合并后的请求头，other 中的同名请求头覆盖 h 中的
+func (h Headers) Merge(other Headers) Headers {
+	var merged Headers
+	for _, src := range []Headers{h, other} {
+		for name, value := range src.Global {
+			merged.Set(nil, name, value)
+		}
+		for pType, headers := range src.Provider {
+			for name, value := range headers {
+				merged.Set(&pType, name, value)
+			}
+		}
+	}
+	return merged
+}
+
+// IsEmpty 判断是否没有任何自定义请求头
+func (h Headers) IsEmpty() bool {
+	if len(h.Global) > 0 {
+		return false
+	}
+	for _, headers := range h.Provider {
+		if len(headers) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// hoistShared 把 types 中所有 provider 取值都相同的请求头移到 Global，读取现有配置时用于还原全局请求头
+func (h *Headers) hoistShared(types []ProviderType) {
+	if len(types) == 0 {
+		return
+	}
+	for name, value := range h.Provider[types[0]] {
+		shared := true
+		for _, pType := range types[1:] {
+			if v, ok := h.Provider[pType][name]; !ok || v != value {
+				shared = false
+				break
+			}
+		}
+		if !shared {
+			continue
+		}
+		h.Set(nil, name, value)
+		for _, pType := range types {
+			delete(h.Provider[pType], name)
+		}
+	}
+}
+
+// Names 返回所有自定义请求头的名称（去重并排序）
+func (h Headers) Names() []string {
+	var names []string
+	for name := range h.Global {
+		names = append(names, name)
+	}
+	for _, headers := range h.Provider {
+		for name := range headers {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// For 返回发往某类 provider 的请求头（Global 与该类型的请求头合并），没有时返回 nil
+func (h Headers) For(pType ProviderType) map[string]string {
+	if len(h.Global) == 0 && len(h.Provider[pType]) == 0 {
+		return nil
+	}
+	headers := maps.Clone(h.Global)
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	maps.Copy(headers, h.Provider[pType])
+	return headers
+}
+
+// ApplyHeaders 为配置中当前命名空间的每个 provider 设置 options.headers
+func (c *OpenCodeConfig) ApplyHeaders(h Headers) {
+	for id, p := range c.Provider {
+		pType, ok := ProviderTypeByID(id)
+		if !ok {
+			continue
+		}
+		p.Options.Headers = h.For(pType)
+		c.Provider[id] = p
+	}
+}
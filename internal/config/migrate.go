@@ -0,0 +1,204 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+
+	"dmxapi-config/internal/debuglog"
+)
+
+// Migration 将旧版本的单一 dmxapi provider 转换为按 provider 类型拆分的布局
+// 旧版本把所有模型放在一个 provider 中，新版本写入 dmxapi-* 后旧条目仍然保留，opencode 中会重复显示模型
+type Migration struct {
+	URL    string // 网关地址（已去除版本后缀）
+	APIKey string // 旧 provider 或 auth.json 中的 API Key
+
+	Moves []ModelMove // 旧 provider 中每个模型的去向（按模型 ID 排序）
+
+	LegacyProvider bool // opencode.json 中存在旧 provider
+	LegacyAuth     bool // auth.json 中存在旧 provider 的认证条目
+
+	// Config 迁移后写入的配置：旧 provider 中的模型与已有 dmxapi-* provider 中的模型合并
+	Config *OpenCodeConfig
+}
+
+// ModelMove 迁移时一个模型的去向
+type ModelMove struct {
+	Model     string // 模型 ID
+	To        string // 迁移后所在的 provider ID
+	Duplicate bool   // 拆分后的 provider 中已有同名模型，保留已有配置，只删除旧条目
+}
+
+// LegacyProviderID 返回旧版本使用的单一 provider ID
+func LegacyProviderID() string {
+	return legacyProviderID
+}
+
+// DetectLegacy 检测 opencode.json 和 auth.json 中旧版本的单一 dmxapi provider，
+// 返回迁移计划；不是默认命名空间、没有旧条目或配置无法读取时返回 nil
+func (r *Reader) DetectLegacy() *Migration {
+	if !CurrentNamespace().IsDefault() {
+		return nil
+	}
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil
+	}
+	var existing OpenCodeConfig
+	if data, err := os.ReadFile(configPath); err == nil {
+		if err := json.Unmarshal(data, &existing); err != nil {
+			debuglog.Printf("config", "migrate: failed to parse %s: %v", configPath, err)
+			return nil
+		}
+	}
+	legacyAuth, hasAuth := readAuthEntry(legacyProviderID)
+	legacy, hasProvider := existing.Provider[legacyProviderID]
+	if !hasProvider && !hasAuth {
+		return nil
+	}
+
+	m := &Migration{
+		URL:            NormalizeBaseURL(legacy.Options.BaseURL),
+		APIKey:         legacy.Options.APIKey,
+		LegacyProvider: hasProvider,
+		LegacyAuth:     hasAuth,
+	}
+	if m.APIKey == "" {
+		m.APIKey = legacyAuth.Key
+	}
+
+	// 已经拆分的模型保持原来的 provider，旧 provider 中的模型按当前路由规则分组
+	var specs []ModelSpec
+	for id, provider := range existing.Provider {
+		pType, ok := CurrentNamespace().ParseProviderID(id)
+		if !ok {
+			continue
+		}
+		if m.URL == "" {
+			m.URL = NormalizeBaseURL(provider.Options.BaseURL)
+		}
+		if m.APIKey == "" {
+			m.APIKey = provider.Options.APIKey
+		}
+		for name, model := range provider.Models {
+			specs = append(specs, ModelSpec{ID: name, Model: model, Provider: &pType})
+		}
+	}
+	split := make(map[string]bool, len(specs))
+	for _, s := range specs {
+		split[s.ID] = true
+	}
+	for name, model := range legacy.Models {
+		if !split[name] {
+			specs = append(specs, ModelSpec{ID: name, Model: model})
+		}
+	}
+	if len(specs) > 0 {
+		m.Config = NewDMXAPIConfigFromSpecs(m.URL, m.APIKey, specs)
+		// 旧 provider 的自定义请求头用于所有拆分后的 provider，已拆分 provider 自身的请求头优先
+		var headers Headers
+		for name, value := range legacy.Options.Headers {
+			headers.Set(nil, name, value)
+		}
+		for id, provider := range existing.Provider {
+			if pType, ok := CurrentNamespace().ParseProviderID(id); ok {
+				for name, value := range provider.Options.Headers {
+					headers.Set(&pType, name, value)
+				}
+			}
+		}
+		m.Config.ApplyHeaders(headers)
+	}
+
+	for name := range legacy.Models {
+		m.Moves = append(m.Moves, ModelMove{Model: name, To: m.Config.providerOf(name), Duplicate: split[name]})
+	}
+	sort.Slice(m.Moves, func(i, j int) bool { return m.Moves[i].Model < m.Moves[j].Model })
+
+	// 指向旧 provider 的默认模型改为指向迁移后的 provider
+	if m.Config != nil {
+		if name, ok := legacyModelRef(existing.Model); ok {
+			m.Config.Model, _ = m.Config.QualifiedModelID(name)
+		}
+		if name, ok := legacyModelRef(existing.SmallModel); ok {
+			m.Config.SmallModel, _ = m.Config.QualifiedModelID(name)
+		}
+	}
+
+	debuglog.Printf("config", "migrate: legacy layout detected (provider %v, auth %v, %d models)", hasProvider, hasAuth, len(m.Moves))
+	return m
+}
+
+// ProviderIDs 返回迁移后的 provider ID（按名称排序）
+func (m *Migration) ProviderIDs() []string {
+	if m.Config == nil {
+		return nil
+	}
+	ids := GetProviderIDs(m.Config)
+	sort.Strings(ids)
+	return ids
+}
+
+// legacyModelRef 解析指向旧 provider 的 dmxapi/model 形式的模型引用
+func legacyModelRef(ref string) (string, bool) {
+	model, ok := strings.CutPrefix(ref, legacyProviderID+"/")
+	return model, ok && model != ""
+}
+
+// providerOf 返回模型所在的 provider ID，模型不存在时返回空字符串
+func (c *OpenCodeConfig) providerOf(model string) string {
+	for id, p := range c.Provider {
+		if _, ok := p.Models[model]; ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// readAuthEntry 读取 auth.json 中指定 provider 的认证条目
+func readAuthEntry(id string) (AuthEntry, bool) {
+	authPath, err := GetAuthPath()
+	if err != nil {
+		return AuthEntry{}, false
+	}
+	data, err := os.ReadFile(authPath)
+	if err != nil {
+		return AuthEntry{}, false
+	}
+	var auth AuthConfig
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return AuthEntry{}, false
+	}
+	entry, ok := auth[id]
+	return entry, ok
+}
+
+// Migrate 执行迁移：写入拆分后的 provider 和认证条目，删除旧 provider 及其认证条目
+// 两个文件写入前都会备份，备份路径见 Backups
+func (w *Writer) Migrate(m *Migration) (configPath, authPath string, err error) {
+	remove := []string{legacyProviderID}
+	var providerIDs []string
+	if m.Config != nil {
+		providerIDs = GetProviderIDs(m.Config)
+	}
+	if m.LegacyAuth || len(providerIDs) > 0 {
+		authPath, err = w.WriteAuthRemoving(NewAuthConfig(providerIDs, m.APIKey), remove)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	if m.LegacyProvider {
+		cfg := m.Config
+		if cfg == nil {
+			cfg = &OpenCodeConfig{Provider: map[string]Provider{}}
+		}
+		configPath, err = w.WriteConfigRemoving(cfg, remove)
+		if err != nil {
+			return "", authPath, err
+		}
+	}
+	debuglog.Printf("config", "migrate: legacy provider %q migrated to %v", legacyProviderID, providerIDs)
+	return configPath, authPath, nil
+}
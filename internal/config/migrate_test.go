@@ -0,0 +1,204 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDetectLegacyNone(t *testing.T) {
+	setupHome(t)
+	if m := NewReader().DetectLegacy(); m != nil {
+		t.Errorf("DetectLegacy without config = %+v, want nil", m)
+	}
+	writeOpenCode(t, OpenCodeConfig{Provider: map[string]Provider{
+		"dmxapi-anthropic": testProvider("@ai-sdk/anthropic", "DMXAPI Claude", "https://www.dmxapi.cn/v1", "claude-sonnet-4-5"),
+	}}, AuthConfig{"dmxapi-anthropic": {Type: "api", Key: testKey}})
+	if m := NewReader().DetectLegacy(); m != nil {
+		t.Errorf("DetectLegacy with split layout only = %+v, want nil", m)
+	}
+}
+
+func TestDetectLegacyOtherNamespace(t *testing.T) {
+	setupHome(t)
+	writeOpenCode(t, OpenCodeConfig{Provider: map[string]Provider{
+		legacyProviderID: testProvider("@ai-sdk/openai-compatible", "DMXAPI", "https://www.dmxapi.cn/v1", "gpt-5"),
+	}}, AuthConfig{legacyProviderID: {Type: "api", Key: testKey}})
+	UseNamespace(Namespace{Prefix: "work"})
+	if m := NewReader().DetectLegacy(); m != nil {
+		t.Errorf("DetectLegacy in namespace work = %+v, want nil", m)
+	}
+}
+
+func TestMigrateLegacyProviderOnly(t *testing.T) {
+	setupHome(t)
+	legacy := testProvider("@ai-sdk/openai-compatible", "DMXAPI", "https://www.dmxapi.cn/v1", "claude-sonnet-4-5", "gpt-5", "DeepSeek-V3")
+	legacy.Options.Headers = map[string]string{"X-Team": "ops"}
+	writeOpenCode(t, OpenCodeConfig{
+		Provider:   map[string]Provider{legacyProviderID: legacy},
+		Model:      "dmxapi/claude-sonnet-4-5",
+		SmallModel: "dmxapi/DeepSeek-V3",
+	}, nil)
+
+	m := NewReader().DetectLegacy()
+	if m == nil {
+		t.Fatal("DetectLegacy = nil")
+	}
+	if !m.LegacyProvider || m.LegacyAuth || m.URL != "https://www.dmxapi.cn" || m.APIKey != testKey {
+		t.Errorf("migration = %+v", m)
+	}
+	wantMoves := []ModelMove{
+		{Model: "DeepSeek-V3", To: "dmxapi-openai"},
+		{Model: "claude-sonnet-4-5", To: "dmxapi-anthropic"},
+		{Model: "gpt-5", To: "dmxapi-openai-responses"},
+	}
+	if !reflect.DeepEqual(m.Moves, wantMoves) {
+		t.Errorf("Moves = %+v, want %+v", m.Moves, wantMoves)
+	}
+	if m.Config.Model != "dmxapi-anthropic/claude-sonnet-4-5" || m.Config.SmallModel != "dmxapi-openai/DeepSeek-V3" {
+		t.Errorf("model = %q, small_model = %q", m.Config.Model, m.Config.SmallModel)
+	}
+
+	w := NewWriter()
+	configPath, authPath, err := w.Migrate(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configPath == "" || authPath == "" {
+		t.Errorf("Migrate paths = %q, %q", configPath, authPath)
+	}
+	cfg, auth := readOpenCode(t)
+	if _, ok := cfg.Provider[legacyProviderID]; ok {
+		t.Error("legacy provider still in opencode.json")
+	}
+	for _, mv := range wantMoves {
+		p := cfg.Provider[mv.To]
+		if _, ok := p.Models[mv.Model]; !ok {
+			t.Errorf("%s missing from %s", mv.Model, mv.To)
+		}
+		if p.Options.Headers["X-Team"] != "ops" {
+			t.Errorf("%s headers = %v, want the legacy X-Team header", mv.To, p.Options.Headers)
+		}
+		if auth[mv.To] != (AuthEntry{Type: "api", Key: testKey}) {
+			t.Errorf("auth[%s] = %+v", mv.To, auth[mv.To])
+		}
+	}
+	if cfg.Model != "dmxapi-anthropic/claude-sonnet-4-5" || cfg.SmallModel != "dmxapi-openai/DeepSeek-V3" {
+		t.Errorf("written model = %q, small_model = %q", cfg.Model, cfg.SmallModel)
+	}
+
+	// 只有 opencode.json 在迁移前存在，只备份它
+	backups := w.Backups()
+	if len(backups) != 1 {
+		t.Fatalf("Backups = %v, want one backup of opencode.json", backups)
+	}
+	if _, err := os.Stat(backups[0]); err != nil {
+		t.Errorf("backup missing: %v", err)
+	}
+
+	if m := NewReader().DetectLegacy(); m != nil {
+		t.Errorf("DetectLegacy after migration = %+v, want nil", m)
+	}
+}
+
+func TestMigrateLegacyAuthOnly(t *testing.T) {
+	setupHome(t)
+	writeOpenCode(t, OpenCodeConfig{Provider: map[string]Provider{}}, AuthConfig{
+		legacyProviderID: {Type: "api", Key: testKey},
+		"openai":         {Type: "oauth"},
+	})
+
+	m := NewReader().DetectLegacy()
+	if m == nil {
+		t.Fatal("DetectLegacy = nil")
+	}
+	if m.LegacyProvider || !m.LegacyAuth || m.Config != nil || len(m.Moves) != 0 || m.APIKey != testKey {
+		t.Errorf("migration = %+v", m)
+	}
+
+	w := NewWriter()
+	configPath, _, err := w.Migrate(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configPath != "" {
+		t.Errorf("opencode.json written to %s, want untouched", configPath)
+	}
+	_, auth := readOpenCode(t)
+	if _, ok := auth[legacyProviderID]; ok {
+		t.Error("legacy auth entry not removed")
+	}
+	if auth["openai"].Type != "oauth" {
+		t.Errorf("unrelated auth entry changed: %+v", auth)
+	}
+	if len(w.Backups()) != 1 {
+		t.Errorf("Backups = %v, want one backup of auth.json", w.Backups())
+	}
+}
+
+func TestMigrateMixedLayout(t *testing.T) {
+	setupHome(t)
+	split := testProvider("@ai-sdk/anthropic", "DMXAPI Claude", "https://www.dmxapi.cn/v1", "claude-sonnet-4-5", "my-proxy-model")
+	split.Models["claude-sonnet-4-5"] = Model{Name: "Sonnet", Extra: map[string]interface{}{"limit": map[string]interface{}{"context": float64(200000)}}}
+	split.Options.Headers = map[string]string{"X-Team": "claude"}
+	legacy := testProvider("@ai-sdk/openai-compatible", "DMXAPI", "https://www.dmxapi.cn/v1", "claude-sonnet-4-5", "gemini-2.5-pro")
+	legacy.Options.Headers = map[string]string{"X-Team": "ops", "X-Trace": "1"}
+	user := testProvider("@ai-sdk/openai", "Mine", "https://example.com/v1", "gpt-4o")
+	writeOpenCode(t, OpenCodeConfig{
+		Provider: map[string]Provider{legacyProviderID: legacy, "dmxapi-anthropic": split, "mine": user},
+		Model:    "dmxapi/gemini-2.5-pro",
+	}, AuthConfig{legacyProviderID: {Type: "api", Key: testKey}, "dmxapi-anthropic": {Type: "api", Key: testKey}})
+
+	m := NewReader().DetectLegacy()
+	if m == nil {
+		t.Fatal("DetectLegacy = nil")
+	}
+	wantMoves := []ModelMove{
+		{Model: "claude-sonnet-4-5", To: "dmxapi-anthropic", Duplicate: true},
+		{Model: "gemini-2.5-pro", To: "dmxapi-google"},
+	}
+	if !reflect.DeepEqual(m.Moves, wantMoves) {
+		t.Errorf("Moves = %+v, want %+v", m.Moves, wantMoves)
+	}
+	if !m.LegacyProvider || !m.LegacyAuth {
+		t.Errorf("LegacyProvider = %v, LegacyAuth = %v", m.LegacyProvider, m.LegacyAuth)
+	}
+	if m.Config.Model != "dmxapi-google/gemini-2.5-pro" {
+		t.Errorf("model = %q", m.Config.Model)
+	}
+
+	w := NewWriter()
+	if _, _, err := w.Migrate(m); err != nil {
+		t.Fatal(err)
+	}
+	cfg, auth := readOpenCode(t)
+	anthropic := cfg.Provider["dmxapi-anthropic"]
+	// 已拆分的模型保留原有配置，按名称无法判断的模型留在原来的 provider
+	if got := anthropic.Models["claude-sonnet-4-5"]; got.Name != "Sonnet" || got.Extra["limit"] == nil {
+		t.Errorf("split model config not kept: %+v", got)
+	}
+	if _, ok := anthropic.Models["my-proxy-model"]; !ok {
+		t.Error("my-proxy-model moved out of dmxapi-anthropic")
+	}
+	if want := map[string]string{"X-Team": "claude", "X-Trace": "1"}; !reflect.DeepEqual(anthropic.Options.Headers, want) {
+		t.Errorf("dmxapi-anthropic headers = %v, want %v", anthropic.Options.Headers, want)
+	}
+	if want := map[string]string{"X-Team": "ops", "X-Trace": "1"}; !reflect.DeepEqual(cfg.Provider["dmxapi-google"].Options.Headers, want) {
+		t.Errorf("dmxapi-google headers = %v, want %v", cfg.Provider["dmxapi-google"].Options.Headers, want)
+	}
+	if !reflect.DeepEqual(cfg.Provider["mine"], user) {
+		t.Errorf("user provider changed: %+v", cfg.Provider["mine"])
+	}
+	if _, ok := cfg.Provider[legacyProviderID]; ok {
+		t.Error("legacy provider still in opencode.json")
+	}
+	if _, ok := auth[legacyProviderID]; ok {
+		t.Error("legacy auth entry still in auth.json")
+	}
+	if auth["dmxapi-google"].Key != testKey {
+		t.Errorf("auth[dmxapi-google] = %+v", auth["dmxapi-google"])
+	}
+	if len(w.Backups()) != 2 {
+		t.Errorf("Backups = %v, want opencode.json and auth.json", w.Backups())
+	}
+}
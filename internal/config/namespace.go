@@ -0,0 +1,209 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/i18n"
+)
+
+// legacyProviderID 旧版本使用的单一 provider ID（所有模型放在一个 provider 中）
+const legacyProviderID = "dmxapi"
+
+// namespacesFile 记录本工具创建过的命名空间（位于状态目录）
+const namespacesFile = "namespaces.json"
+
+// namespacePrefixPattern 命名空间前缀：小写字母、数字，用单个 - 连接
+var namespacePrefixPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// providerTypeLabels provider 类型在显示名称中使用的名称
+var providerTypeLabels = map[ProviderType]string{
+	ProviderAnthropic:       "Claude",
+	ProviderGoogle:          "Gemini",
+	ProviderOpenAI:          "OpenAI",
+	ProviderOpenAIResponses: "OpenAI Responses",
+}
+
+// Namespace 本工具创建的一组 provider 的命名方式（profile）
+// 同时配置多个 DMXAPI 账号时为每个账号使用不同的前缀，provider ID 和 opencode 中的显示名称互不冲突
+type Namespace struct {
+	Prefix string `json:"prefix"`         // provider ID 前缀，如 dmxapi 对应 dmxapi-anthropic、dmxapi-google 等
+	Name   string `json:"name,omitempty"` // 显示名称模板，{type} 替换为 Claude、Gemini 等，{prefix} 替换为前缀；为空时使用默认模板
+}
+
+// DefaultNamespace 默认命名空间（与旧版本创建的 provider 一致）
+var DefaultNamespace = Namespace{Prefix: "dmxapi", Name: "DMXAPI {type}"}
+
+// Validate 检查前缀格式，并要求名称模板包含 {type}（否则同一命名空间的 provider 显示名称相同）
+// 与默认命名空间的 provider ID 相同的前缀（如 dmxapi-openai）不能使用，否则无法区分前缀和 provider
+func (n Namespace) Validate() error {
+	if !namespacePrefixPattern.MatchString(n.Prefix) {
+		return fmt.Errorf(i18n.T("namespace.bad_prefix"), n.Prefix)
+	}
+	if !n.IsDefault() && DefaultNamespace.Owns(n.Prefix) {
+		return fmt.Errorf(i18n.T("namespace.reserved_prefix"), n.Prefix)
+	}
+	if n.Name != "" && !strings.Contains(n.Name, "{type}") {
+		return fmt.Errorf(i18n.T("namespace.bad_name"), n.Name)
+	}
+	return nil
+}
+
+// IsDefault 判断是否为默认命名空间
+func (n Namespace) IsDefault() bool {
+	return n.Prefix == DefaultNamespace.Prefix
+}
+
+// nameTemplate 返回显示名称模板，未设置时默认命名空间为 "DMXAPI {type}"，其他命名空间附加前缀以便区分
+func (n Namespace) nameTemplate() string {
+	if n.Name != "" {
+		return n.Name
+	}
+	if n.IsDefault() {
+		return DefaultNamespace.Name
+	}
+	return "DMXAPI {type} ({prefix})"
+}
+
+// ProviderID 返回 provider 类型在该命名空间中的 ID，如 dmxapi-anthropic
+func (n Namespace) ProviderID(pType ProviderType) string {
+	return n.Prefix + "-" + pType.String()
+}
+
+// ProviderName 返回 provider 类型在该命名空间中的显示名称，如 DMXAPI Claude
+func (n Namespace) ProviderName(pType ProviderType) string {
+	return strings.NewReplacer("{type}", providerTypeLabels[pType], "{prefix}", n.Prefix).Replace(n.nameTemplate())
+}
+
+// ParseProviderID 判断 provider ID 是否属于该命名空间，属于时返回对应的 provider 类型
+func (n Namespace) ParseProviderID(id string) (ProviderType, bool) {
+	rest, ok := strings.CutPrefix(id, n.Prefix+"-")
+	if !ok {
+		return 0, false
+	}
+	for pType, name := range providerTypeNames {
+		if name == rest {
+			return pType, true
+		}
+	}
+	return 0, false
+}
+
+// Owns 判断 provider 是否由本工具在该命名空间中创建（默认命名空间包括旧版本的单一 dmxapi provider）
+func (n Namespace) Owns(id string) bool {
+	if _, ok := n.ParseProviderID(id); ok {
+		return true
+	}
+	return n.IsDefault() && id == legacyProviderID
+}
+
+var (
+	activeNamespaceMu sync.RWMutex
+	activeNamespace   = DefaultNamespace
+)
+
+// UseNamespace 设置 GetProviderInfo、Reader 和 Writer 使用的命名空间（默认为 DefaultNamespace）
+func UseNamespace(n Namespace) {
+	activeNamespaceMu.Lock()
+	defer activeNamespaceMu.Unlock()
+	activeNamespace = n
+}
+
+// CurrentNamespace 返回当前使用的命名空间
+func CurrentNamespace() Namespace {
+	activeNamespaceMu.RLock()
+	defer activeNamespaceMu.RUnlock()
+	return activeNamespace
+}
+
+// KnownNamespaces 返回本工具创建过的所有命名空间：默认命名空间和状态目录中记录的命名空间
+func KnownNamespaces() []Namespace {
+	known := []Namespace{DefaultNamespace}
+	for _, n := range readNamespaces() {
+		if !n.IsDefault() {
+			known = append(known, n)
+		}
+	}
+	return known
+}
+
+// NamespaceOf 在本工具创建过的命名空间中查找 provider 所属的命名空间
+func NamespaceOf(id string) (Namespace, bool) {
+	for _, n := range KnownNamespaces() {
+		if n.Owns(id) {
+			return n, true
+		}
+	}
+	return Namespace{}, false
+}
+
+// RememberNamespace 记录本工具创建过的命名空间，之后读取和写入配置时都能识别其中的 provider
+func RememberNamespace(n Namespace) error {
+	if n.IsDefault() {
+		return nil
+	}
+	namespaces := readNamespaces()
+	for i, known := range namespaces {
+		if known.Prefix == n.Prefix {
+			if known.Name == n.Name {
+				return nil
+			}
+			namespaces = append(namespaces[:i], namespaces[i+1:]...)
+			break
+		}
+	}
+	namespaces = append(namespaces, n)
+
+	path, err := namespacesPath()
+	if err != nil {
+		return err
+	}
+	if err := EnsureDir(path); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(namespaces, "", "  ")
+	if err != nil {
+		return err
+	}
+	debuglog.Printf("config", "namespace: remembered %q in %s", n.Prefix, path)
+	return writeFileAtomic(path, data, 0644)
+}
+
+// readNamespaces 读取状态目录中记录的命名空间，文件不存在或无法解析时返回 nil
+func readNamespaces() []Namespace {
+	path, err := namespacesPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var namespaces []Namespace
+	if err := json.Unmarshal(data, &namespaces); err != nil {
+		debuglog.Printf("config", "namespace: ignoring unreadable %s: %v", path, err)
+		return nil
+	}
+	valid := namespaces[:0]
+	for _, n := range namespaces {
+		if n.Validate() == nil {
+			valid = append(valid, n)
+		}
+	}
+	return valid
+}
+
+// namespacesPath 返回命名空间记录文件路径
+func namespacesPath() (string, error) {
+	dir, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, namespacesFile), nil
+}
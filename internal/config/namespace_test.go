@@ -0,0 +1,198 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+var allProviderTypes = []ProviderType{ProviderAnthropic, ProviderGoogle, ProviderOpenAI, ProviderOpenAIResponses}
+
+func TestNamespaceProviderIDRoundTrip(t *testing.T) {
+	namespaces := []Namespace{DefaultNamespace, {Prefix: "work"}, {Prefix: "dmxapi-team"}, {Prefix: "a1-b2"}}
+	for _, ns := range namespaces {
+		for _, pType := range allProviderTypes {
+			id := ns.ProviderID(pType)
+			if got, ok := ns.ParseProviderID(id); !ok || got != pType {
+				t.Errorf("%s: ParseProviderID(%q) = %v, %v; want %v", ns.Prefix, id, got, ok, pType)
+			}
+			if !ns.Owns(id) {
+				t.Errorf("%s: Owns(%q) = false", ns.Prefix, id)
+			}
+			// 其他命名空间不能认领该 provider
+			for _, other := range namespaces {
+				if other.Prefix != ns.Prefix && other.Owns(id) {
+					t.Errorf("%s also owns %q created in %s", other.Prefix, id, ns.Prefix)
+				}
+			}
+		}
+	}
+
+	for _, id := range []string{"work", "work-", "work-claude", "workx-anthropic", "anthropic", "dmxapi-work-google"} {
+		if (Namespace{Prefix: "work"}).Owns(id) {
+			t.Errorf("work owns %q", id)
+		}
+	}
+}
+
+func TestNamespaceLegacyProvider(t *testing.T) {
+	if !DefaultNamespace.Owns(legacyProviderID) {
+		t.Errorf("default namespace does not own the legacy %q provider", legacyProviderID)
+	}
+	for _, ns := range []Namespace{{Prefix: "work"}, {Prefix: "dmxapi-team"}} {
+		if ns.Owns(legacyProviderID) {
+			t.Errorf("%s owns the legacy %q provider", ns.Prefix, legacyProviderID)
+		}
+	}
+	// 自定义显示名称的 dmxapi 前缀仍是默认命名空间
+	if ns := (Namespace{Prefix: "dmxapi", Name: "Gateway {type}"}); !ns.IsDefault() || !ns.Owns(legacyProviderID) {
+		t.Errorf("dmxapi prefix with a custom name is not the default namespace")
+	}
+}
+
+func TestNamespaceValidate(t *testing.T) {
+	tests := []struct {
+		ns Namespace
+		ok bool
+	}{
+		{DefaultNamespace, true},
+		{Namespace{Prefix: "dmxapi"}, true},
+		{Namespace{Prefix: "work"}, true},
+		{Namespace{Prefix: "dmxapi-team", Name: "Team {type}"}, true},
+		{Namespace{Prefix: ""}, false},
+		{Namespace{Prefix: "Work"}, false},
+		{Namespace{Prefix: "work-"}, false},
+		{Namespace{Prefix: "-work"}, false},
+		{Namespace{Prefix: "a--b"}, false},
+		{Namespace{Prefix: "work_team"}, false},
+		// 与默认命名空间的 provider ID 相同
+		{Namespace{Prefix: "dmxapi-openai"}, false},
+		{Namespace{Prefix: "dmxapi-anthropic"}, false},
+		{Namespace{Prefix: "dmxapi-openai-responses"}, false},
+		{Namespace{Prefix: "work", Name: "Work"}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.ns.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%+v) = %v, want ok = %v", tt.ns, err, tt.ok)
+		}
+	}
+}
+
+func TestNamespaceProviderName(t *testing.T) {
+	tests := []struct {
+		ns    Namespace
+		pType ProviderType
+		want  string
+	}{
+		{DefaultNamespace, ProviderAnthropic, "DMXAPI Claude"},
+		{Namespace{Prefix: "dmxapi"}, ProviderGoogle, "DMXAPI Gemini"},
+		{Namespace{Prefix: "work"}, ProviderOpenAI, "DMXAPI OpenAI (work)"},
+		{Namespace{Prefix: "work", Name: "{prefix}: {type}"}, ProviderOpenAIResponses, "work: OpenAI Responses"},
+	}
+	for _, tt := range tests {
+		if got := tt.ns.ProviderName(tt.pType); got != tt.want {
+			t.Errorf("%+v.ProviderName(%v) = %q, want %q", tt.ns, tt.pType, got, tt.want)
+		}
+	}
+}
+
+func TestRememberNamespace(t *testing.T) {
+	setupHome(t)
+	if err := RememberNamespace(DefaultNamespace); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := namespacesPath()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("default namespace recorded in %s", path)
+	}
+
+	if _, ok := NamespaceOf("work-google"); ok {
+		t.Error("NamespaceOf(work-google) found before work was remembered")
+	}
+	work := Namespace{Prefix: "work", Name: "Work {type}"}
+	if err := RememberNamespace(work); err != nil {
+		t.Fatal(err)
+	}
+	if err := RememberNamespace(Namespace{Prefix: "work", Name: "Office {type}"}); err != nil {
+		t.Fatal(err)
+	}
+	known := KnownNamespaces()
+	if len(known) != 2 || known[1].Name != "Office {type}" {
+		t.Errorf("KnownNamespaces = %+v, want default and the renamed work", known)
+	}
+	if ns, ok := NamespaceOf("work-google"); !ok || ns.Prefix != "work" {
+		t.Errorf("NamespaceOf(work-google) = %+v, %v", ns, ok)
+	}
+	if ns, ok := NamespaceOf("dmxapi"); !ok || !ns.IsDefault() {
+		t.Errorf("NamespaceOf(dmxapi) = %+v, %v", ns, ok)
+	}
+	if _, ok := NamespaceOf("my-anthropic"); ok {
+		t.Error("NamespaceOf(my-anthropic) found for a provider not created by this tool")
+	}
+}
+
+func TestWriterLeavesOtherNamespaces(t *testing.T) {
+	setupHome(t)
+	others := map[string]Provider{
+		"dmxapi":           testProvider("@ai-sdk/openai-compatible", "DMXAPI", "https://www.dmxapi.cn/v1", "DeepSeek-V3"),
+		"dmxapi-anthropic": testProvider("@ai-sdk/anthropic", "DMXAPI Claude", "https://www.dmxapi.cn/v1", "claude-opus-4-5"),
+		"my-anthropic":     testProvider("@ai-sdk/anthropic", "Mine", "https://example.com/v1", "claude-haiku-4-5"),
+	}
+	otherAuth := AuthConfig{"dmxapi-anthropic": {Type: "api", Key: "sk-personal"}, "my-anthropic": {Type: "api", Key: "sk-mine"}}
+	writeOpenCode(t, OpenCodeConfig{Provider: others, Model: "dmxapi-anthropic/claude-opus-4-5"}, otherAuth)
+
+	UseNamespace(Namespace{Prefix: "work"})
+	cfg := NewDMXAPIConfig("https://work.example.com", testKey, []string{"claude-sonnet-4-5", "gemini-2.5-pro"})
+	w := NewWriter()
+	if _, err := w.WriteAuth(NewAuthConfig(GetProviderIDs(cfg), testKey)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	got, auth := readOpenCode(t)
+	for id, want := range others {
+		if !reflect.DeepEqual(got.Provider[id], want) {
+			t.Errorf("provider %s changed:\n got %+v\nwant %+v", id, got.Provider[id], want)
+		}
+	}
+	for id, want := range otherAuth {
+		if auth[id] != want {
+			t.Errorf("auth entry %s = %+v, want %+v", id, auth[id], want)
+		}
+	}
+	if got.Model != "dmxapi-anthropic/claude-opus-4-5" {
+		t.Errorf("model = %q, want unchanged", got.Model)
+	}
+	if p := got.Provider["work-anthropic"]; p.Name != "DMXAPI Claude (work)" || p.Options.BaseURL != "https://work.example.com/v1" {
+		t.Errorf("work-anthropic = %+v", p)
+	}
+	if _, ok := got.Provider["work-google"].Models["gemini-2.5-pro"]; !ok {
+		t.Errorf("work-google = %+v", got.Provider["work-google"])
+	}
+
+	// 每个命名空间只读取自己的模型
+	existing := NewReader().ReadExistingConfig()
+	if existing == nil || !reflect.DeepEqual(existing.Models, []string{"claude-sonnet-4-5", "gemini-2.5-pro"}) {
+		t.Fatalf("work namespace read %+v", existing)
+	}
+	UseNamespace(DefaultNamespace)
+	existing = NewReader().ReadExistingConfig()
+	if existing == nil || !reflect.DeepEqual(existing.Models, []string{"DeepSeek-V3", "claude-opus-4-5"}) {
+		t.Fatalf("default namespace read %+v", existing)
+	}
+	if existing.DefaultModel != "claude-opus-4-5" {
+		t.Errorf("default namespace DefaultModel = %q", existing.DefaultModel)
+	}
+
+	var prefixes []string
+	for _, u := range NewReader().ReadNamespaces() {
+		prefixes = append(prefixes, u.Namespace.Prefix)
+	}
+	sort.Strings(prefixes)
+	if !reflect.DeepEqual(prefixes, []string{"dmxapi", "work"}) {
+		t.Errorf("ReadNamespaces prefixes = %v", prefixes)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"sync"
+
+	"dmxapi-config/internal/i18n"
 )
 
 // GetConfigPath 返回 opencode.json 配置文件的路径
@@ -17,7 +19,7 @@ import (
 func GetConfigPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return "", fmt.Errorf("获取用户目录失败: %w", err)
+		return "", fmt.Errorf(i18n.T("config.home_failed"), err)
 	}
 
 	configDir := filepath.Join(homeDir, ".config", "opencode")
@@ -30,13 +32,24 @@ func GetConfigPath() (string, error) {
 func GetAuthPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return "", fmt.Errorf("获取用户目录失败: %w", err)
+		return "", fmt.Errorf(i18n.T("config.home_failed"), err)
 	}
 
 	authDir := filepath.Join(homeDir, ".local", "share", "opencode")
 	return filepath.Join(authDir, "auth.json"), nil
 }
 
+// GetStateDir 返回本工具自身的状态目录（更新检查缓存等）
+// 与 opencode 一致遵循 XDG 规范，所有平台均为 ~/.local/state/dmxapi-config
+func GetStateDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("config.home_failed"), err)
+	}
+
+	return filepath.Join(homeDir, ".local", "state", "dmxapi-config"), nil
+}
+
 // windowsPermWarning 确保 Windows 权限提示只输出一次（问题7修复）
 var windowsPermWarning sync.Once
 
@@ -45,11 +58,11 @@ var windowsPermWarning sync.Once
 func EnsureDir(filePath string) error {
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("创建目录失败: %w", err)
+		return fmt.Errorf(i18n.T("config.mkdir_failed"), err)
 	}
 	if runtime.GOOS == "windows" {
 		windowsPermWarning.Do(func() {
-			fmt.Println("注意: Windows 不支持 Unix 文件权限 (0600/0755)，请确保配置文件所在目录的访问权限受限。")
+			fmt.Fprintln(messageOutput, i18n.T("config.windows_perm"))
 		})
 	}
 	return nil
@@ -3,14 +3,23 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"sort"
 	"strings"
+
+	"dmxapi-config/internal/debuglog"
 )
 
 // ExistingConfig 表示已存在的配置信息
 type ExistingConfig struct {
 	URL    string   // API URL
 	APIKey string   // API Key
-	Models []string // 模型列表
+	Models []string // 模型列表（按名称排序）
+
+	ModelConfigs   map[string]Model  // 模型 ID 到模型配置（含 options 等附加字段）
+	ModelProviders map[string]string // 模型 ID 到所在的 provider ID
+	Headers        Headers           // 各 provider 的自定义请求头，所有 provider 相同的记录为 Global
+	DefaultModel   string            // opencode.json 的 model 指向 DMXAPI provider 时的模型 ID
+	SmallModel     string            // opencode.json 的 small_model 指向 DMXAPI provider 时的模型 ID
 }
 
 // Reader 配置读取器
@@ -21,9 +30,9 @@ func NewReader() *Reader {
 	return &Reader{}
 }
 
-// ReadExistingConfig 读取现有的 DMXAPI 配置
+// ReadExistingConfig 读取当前命名空间（见 UseNamespace）中现有的 DMXAPI 配置
 // 如果配置不存在或读取失败，返回 nil
-// 支持新旧两种格式（单 dmxapi 或多 dmxapi-* provider）
+// 默认命名空间支持新旧两种格式（单 dmxapi 或多 dmxapi-* provider）
 func (r *Reader) ReadExistingConfig() *ExistingConfig {
 	configPath, err := GetConfigPath()
 	if err != nil {
@@ -32,47 +41,128 @@ func (r *Reader) ReadExistingConfig() *ExistingConfig {
 
 	// 检查文件是否存在
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		debuglog.Printf("config", "reader: %s does not exist, no existing config", configPath)
 		return nil
 	}
 
 	// 读取配置文件
 	data, err := os.ReadFile(configPath)
 	if err != nil {
+		debuglog.Printf("config", "reader: failed to read %s: %v", configPath, err)
 		return nil
 	}
 
 	var config OpenCodeConfig
 	if err := json.Unmarshal(data, &config); err != nil {
+		debuglog.Printf("config", "reader: failed to parse %s, ignoring existing config: %v", configPath, err)
 		return nil
 	}
 
-	// 查找所有 dmxapi-* provider（兼容新旧格式）
+	// 查找当前命名空间中的所有 provider（默认命名空间兼容新旧格式）
+	ns := CurrentNamespace()
 	var models []string
 	var url, apiKey string
+	modelConfigs := make(map[string]Model)
+	modelProviders := make(map[string]string)
+	var headers Headers
+	var types []ProviderType
 
 	for key, provider := range config.Provider {
-		if key == "dmxapi" || strings.HasPrefix(key, "dmxapi-") {
-			for modelName := range provider.Models {
+		if ns.Owns(key) {
+			debuglog.Printf("config", "reader: provider %q matched (%d models, baseURL %s)", key, len(provider.Models), provider.Options.BaseURL)
+			for modelName, model := range provider.Models {
 				models = append(models, modelName)
+				modelConfigs[modelName] = model
+				modelProviders[modelName] = key
 			}
 			if url == "" {
 				url = provider.Options.BaseURL
+			}
+			if apiKey == "" {
+				// 保留其他认证条目的 provider 没有 options.apiKey
 				apiKey = provider.Options.APIKey
 			}
+			if pType, ok := ns.ParseProviderID(key); ok {
+				types = append(types, pType)
+				for name, value := range provider.Options.Headers {
+					headers.Set(&pType, name, value)
+				}
+			}
+		} else if other, ok := NamespaceOf(key); ok {
+			debuglog.Printf("config", "reader: provider %q skipped (namespace %q)", key, other.Prefix)
+		} else {
+			debuglog.Printf("config", "reader: provider %q skipped (not created by this tool)", key)
 		}
 	}
 
 	if len(models) == 0 {
+		debuglog.Printf("config", "reader: no models in namespace %q found in %s", ns.Prefix, configPath)
 		return nil
 	}
 
 	url = NormalizeBaseURL(url)
+	sort.Strings(models)
+	headers.hoistShared(types)
 
 	return &ExistingConfig{
-		URL:    url,
-		APIKey: apiKey,
-		Models: models,
+		URL:            url,
+		APIKey:         apiKey,
+		Models:         models,
+		ModelConfigs:   modelConfigs,
+		ModelProviders: modelProviders,
+		Headers:        headers,
+		DefaultModel:   modelRefIn(ns, config.Model),
+		SmallModel:     modelRefIn(ns, config.SmallModel),
+	}
+}
+
+// NamespaceUsage 配置文件中一个命名空间的使用情况
+type NamespaceUsage struct {
+	Namespace Namespace
+	Providers []string // 该命名空间中的 provider ID（按名称排序）
+	Models    int      // 模型总数
+}
+
+// ReadNamespaces 列出配置文件中出现的所有本工具创建的命名空间（按前缀排序），配置不存在或无法解析时返回 nil
+func (r *Reader) ReadNamespaces() []NamespaceUsage {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil
+	}
+	var config OpenCodeConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+
+	var usages []NamespaceUsage
+	for _, ns := range KnownNamespaces() {
+		usage := NamespaceUsage{Namespace: ns}
+		for key, provider := range config.Provider {
+			if ns.Owns(key) {
+				usage.Providers = append(usage.Providers, key)
+				usage.Models += len(provider.Models)
+			}
+		}
+		if len(usage.Providers) > 0 {
+			sort.Strings(usage.Providers)
+			usages = append(usages, usage)
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Namespace.Prefix < usages[j].Namespace.Prefix })
+	return usages
+}
+
+// modelRefIn 解析 provider/model 形式的模型引用，provider 不属于命名空间 ns 时返回空字符串
+func modelRefIn(ns Namespace, ref string) string {
+	provider, model, ok := strings.Cut(ref, "/")
+	if !ok || !ns.Owns(provider) {
+		return ""
 	}
+	return model
 }
 
 // MaskAPIKey 遮蔽 API Key，只显示前4位和后4位
@@ -3,13 +3,29 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
+
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/i18n"
 )
 
+// messageOutput 备份提示等附带信息的输出位置
+var messageOutput io.Writer = os.Stdout
+
+// SetMessageOutput 设置备份提示等附带信息的输出位置
+// JSON 输出模式下改为标准错误，避免混入结果文档
+func SetMessageOutput(w io.Writer) {
+	messageOutput = w
+}
+
 // Writer 配置文件写入器
-type Writer struct{}
+type Writer struct {
+	backups    []string            // 本次写入前创建的备份文件
+	dropModels map[string][]string // 合并时从其他 provider 中删除的模型（provider ID 到模型 ID），见 ResolveConflicts
+}
 
 // NewWriter 创建新的写入器
 func NewWriter() *Writer {
@@ -18,6 +34,12 @@ func NewWriter() *Writer {
 
 // WriteConfig 写入 opencode.json 配置文件
 func (w *Writer) WriteConfig(config *OpenCodeConfig) (string, error) {
+	return w.WriteConfigRemoving(config, nil)
+}
+
+// WriteConfigRemoving 写入 opencode.json 配置文件，同时删除 remove 中列出的 provider
+// 用于删除模型后某个 provider 已没有剩余模型的情况（合并时默认保留新配置中没有的 provider）
+func (w *Writer) WriteConfigRemoving(config *OpenCodeConfig, remove []string) (string, error) {
 	configPath, err := GetConfigPath()
 	if err != nil {
 		return "", err
@@ -31,32 +53,54 @@ func (w *Writer) WriteConfig(config *OpenCodeConfig) (string, error) {
 	// 备份现有配置
 	if err := w.backupIfExists(configPath); err != nil {
 		// 备份失败不阻止写入，只打印警告
-		fmt.Printf("警告: 备份现有配置失败: %v\n", err)
+		fmt.Fprintln(messageOutput, i18n.T("config.backup_config_failed", err))
 	}
 
 	// 合并现有配置（使用 map 保留未知字段）
-	merged, err := w.mergeConfigPreservingFields(configPath, config)
+	merged, err := w.mergeConfigPreservingFields(configPath, config, remove)
 	if err != nil {
-		return "", fmt.Errorf("合并配置失败: %w", err)
+		return "", fmt.Errorf(i18n.T("config.merge_failed"), err)
 	}
 
 	// 序列化为JSON
 	data, err := json.MarshalIndent(merged, "", "  ")
 	if err != nil {
-		return "", fmt.Errorf("序列化配置失败: %w", err)
+		return "", fmt.Errorf(i18n.T("config.marshal_failed"), err)
 	}
 
-	// 写入文件（配置中含 API Key，使用 0600 限制权限）
+	// 写入文件（配置中含 API Key，使用 0600 限制权限；先写临时文件再重命名，中断时不会留下半个文件）
 	// 注意：Windows 会忽略 Unix 权限位（0600），Windows 权限警告已在 EnsureDir 中统一输出
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
-		return "", fmt.Errorf("写入配置文件失败: %w", err)
+	if err := writeFileAtomic(configPath, data, 0600); err != nil {
+		return "", fmt.Errorf(i18n.T("config.write_failed"), err)
 	}
+	debuglog.Printf("config", "writer: wrote %s (%d bytes)", configPath, len(data))
+	w.rememberNamespace()
 
 	return configPath, nil
 }
 
+// rememberNamespace 记录当前命名空间，之后读取和写入配置时都能识别其中的 provider；记录失败不影响写入结果
+func (w *Writer) rememberNamespace() {
+	if err := RememberNamespace(CurrentNamespace()); err != nil {
+		debuglog.Printf("config", "writer: failed to remember namespace: %v", err)
+	}
+}
+
+// preservedReason 说明合并时保留的 provider（或认证条目）的来源，用于调试日志
+func preservedReason(id string) string {
+	if ns, ok := NamespaceOf(id); ok {
+		return fmt.Sprintf("namespace %q", ns.Prefix)
+	}
+	return "not created by this tool"
+}
+
 // WriteAuth 写入 auth.json 认证文件
 func (w *Writer) WriteAuth(authConfig AuthConfig) (string, error) {
+	return w.WriteAuthRemoving(authConfig, nil)
+}
+
+// WriteAuthRemoving 写入 auth.json 认证文件，同时删除 remove 中列出的 provider 的认证条目
+func (w *Writer) WriteAuthRemoving(authConfig AuthConfig, remove []string) (string, error) {
 	authPath, err := GetAuthPath()
 	if err != nil {
 		return "", err
@@ -69,36 +113,63 @@ func (w *Writer) WriteAuth(authConfig AuthConfig) (string, error) {
 
 	// 备份现有认证配置
 	if err := w.backupIfExists(authPath); err != nil {
-		fmt.Printf("警告: 备份现有认证配置失败: %v\n", err)
+		fmt.Fprintln(messageOutput, i18n.T("config.backup_auth_failed", err))
 	}
 
 	// 读取并合并现有认证配置
 	existingAuth := w.readExistingAuth(authPath)
 	if existingAuth != nil {
 		for k, v := range authConfig {
+			if _, ok := existingAuth[k]; ok {
+				debuglog.Printf("config", "writer: auth entry %q replaced", k)
+			} else {
+				debuglog.Printf("config", "writer: auth entry %q added", k)
+			}
 			existingAuth[k] = v
 		}
+		for _, k := range remove {
+			if _, ok := existingAuth[k]; ok {
+				debuglog.Printf("config", "writer: auth entry %q removed", k)
+				delete(existingAuth, k)
+			}
+		}
+		for k := range existingAuth {
+			if _, ok := authConfig[k]; !ok {
+				debuglog.Printf("config", "writer: auth entry %q preserved (%s)", k, preservedReason(k))
+			}
+		}
+		debuglog.Printf("config", "writer: %s merged, %d entries total", authPath, len(existingAuth))
 		authConfig = existingAuth
+	} else {
+		debuglog.Printf("config", "writer: no readable %s, writing %d new entries", authPath, len(authConfig))
 	}
 
 	// 序列化为JSON
 	data, err := json.MarshalIndent(authConfig, "", "  ")
 	if err != nil {
-		return "", fmt.Errorf("序列化认证配置失败: %w", err)
+		return "", fmt.Errorf(i18n.T("config.marshal_auth_failed"), err)
 	}
 
 	// 写入文件（使用更严格的权限）
 	// 注意：Windows 会忽略 Unix 权限位（0600），Windows 权限警告已在 EnsureDir 中统一输出
-	if err := os.WriteFile(authPath, data, 0600); err != nil {
-		return "", fmt.Errorf("写入认证文件失败: %w", err)
+	if err := writeFileAtomic(authPath, data, 0600); err != nil {
+		return "", fmt.Errorf(i18n.T("config.write_auth_failed"), err)
 	}
+	debuglog.Printf("config", "writer: wrote %s (%d bytes)", authPath, len(data))
+	w.rememberNamespace()
 
 	return authPath, nil
 }
 
+// Backups 返回该写入器写入前创建的备份文件路径
+func (w *Writer) Backups() []string {
+	return w.backups
+}
+
 // backupIfExists 如果文件存在则创建备份
 func (w *Writer) backupIfExists(filePath string) error {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		debuglog.Printf("config", "writer: %s does not exist, no backup needed", filePath)
 		return nil // 文件不存在，无需备份
 	}
 
@@ -111,31 +182,40 @@ func (w *Writer) backupIfExists(filePath string) error {
 	// 读取原文件
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return fmt.Errorf("读取原文件失败: %w", err)
+		return fmt.Errorf(i18n.T("config.read_original_failed"), err)
 	}
 
 	// 写入备份（继承原文件的严格权限）
-	if err := os.WriteFile(backupPath, data, 0600); err != nil {
-		return fmt.Errorf("创建备份失败: %w", err)
+	if err := writeFileAtomic(backupPath, data, 0600); err != nil {
+		return fmt.Errorf(i18n.T("config.backup_failed"), err)
 	}
 
-	fmt.Printf("已备份现有配置到: %s\n", backupPath)
+	debuglog.Printf("config", "writer: backed up %s to %s", filePath, backupPath)
+	w.backups = append(w.backups, backupPath)
+	fmt.Fprintln(messageOutput, i18n.T("config.backed_up", backupPath))
 	return nil
 }
 
 // mergeConfigPreservingFields 使用 map[string]interface{} 合并配置，保留 JSON 中的所有字段
-func (w *Writer) mergeConfigPreservingFields(filePath string, newConfig *OpenCodeConfig) (interface{}, error) {
+func (w *Writer) mergeConfigPreservingFields(filePath string, newConfig *OpenCodeConfig, remove []string) (interface{}, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		// 文件不存在，直接返回新配置
+		debuglog.Printf("config", "writer: %s not readable, writing new config: %v", filePath, err)
 		return newConfig, nil
 	}
 
 	var existing map[string]interface{}
 	if err := json.Unmarshal(data, &existing); err != nil {
 		// 解析失败，直接使用新配置
+		debuglog.Printf("config", "writer: %s is not valid JSON, overwriting with new config: %v", filePath, err)
 		return newConfig, nil
 	}
+	for key := range existing {
+		if key != "provider" {
+			debuglog.Printf("config", "writer: top-level field %q preserved", key)
+		}
+	}
 
 	// 将新配置序列化再反序列化为 map，以便合并
 	newData, err := json.Marshal(newConfig)
@@ -155,12 +235,46 @@ func (w *Writer) mergeConfigPreservingFields(filePath string, newConfig *OpenCod
 		}
 		if np, ok := newProvider.(map[string]interface{}); ok {
 			for k, v := range np {
+				if _, ok := existingProvider[k]; ok {
+					debuglog.Printf("config", "writer: provider %q replaced", k)
+				} else {
+					debuglog.Printf("config", "writer: provider %q added", k)
+				}
 				existingProvider[k] = v
 			}
+			for _, k := range remove {
+				if _, ok := existingProvider[k]; ok {
+					debuglog.Printf("config", "writer: provider %q removed", k)
+					delete(existingProvider, k)
+				}
+			}
+			for k, models := range w.dropModels {
+				provider, _ := existingProvider[k].(map[string]interface{})
+				existingModels, _ := provider["models"].(map[string]interface{})
+				for _, m := range models {
+					if _, ok := existingModels[m]; ok {
+						debuglog.Printf("config", "writer: model %q removed from provider %q (conflict)", m, k)
+						delete(existingModels, m)
+					}
+				}
+			}
+			for k := range existingProvider {
+				if _, ok := np[k]; !ok {
+					debuglog.Printf("config", "writer: provider %q preserved (%s)", k, preservedReason(k))
+				}
+			}
 		}
 		existing["provider"] = existingProvider
 	}
 
+	// 默认模型只在新配置指定时覆盖
+	for _, key := range []string{"model", "small_model"} {
+		if v, ok := newMap[key]; ok {
+			debuglog.Printf("config", "writer: %s set to %v (was %v)", key, v, existing[key])
+			existing[key] = v
+		}
+	}
+
 	return existing, nil
 }
 
@@ -178,3 +292,71 @@ func (w *Writer) readExistingAuth(filePath string) AuthConfig {
 
 	return auth
 }
+
+// writeFileAtomic 先写入同目录下的临时文件再重命名为目标文件
+// 写入过程中被中断（Ctrl+C、断电等）时目标文件保持原样，不会出现只写了一半的配置
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// FileSnapshot 文件在修改前的内容，用于后续步骤失败时恢复
+type FileSnapshot struct {
+	path    string
+	data    []byte
+	existed bool
+}
+
+// SnapshotFile 记录文件当前的内容，文件不存在时恢复即删除
+func SnapshotFile(path string) (*FileSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &FileSnapshot{path: path}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &FileSnapshot{path: path, data: data, existed: true}, nil
+}
+
+// Restore 把文件恢复为记录时的内容
+func (s *FileSnapshot) Restore() error {
+	if !s.existed {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		debuglog.Printf("config", "writer: removed %s (did not exist before)", s.path)
+		return nil
+	}
+	if err := writeFileAtomic(s.path, s.data, 0600); err != nil {
+		return err
+	}
+	debuglog.Printf("config", "writer: restored %s", s.path)
+	return nil
+}
@@ -0,0 +1,197 @@
+package dashboard
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"dmxapi-config/internal/api"
+	"dmxapi-config/internal/auth"
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/i18n"
+	"dmxapi-config/internal/input"
+)
+
+// catalogMsg 网关模型列表获取结果
+type catalogMsg struct {
+	gen    int
+	models []string
+	err    error
+}
+
+// testDoneMsg 单个模型的测试结果
+type testDoneMsg struct {
+	gen    int
+	model  string
+	result *api.TestResult
+	err    error
+}
+
+// loadCatalog 在后台获取网关模型列表，用于校验新添加的模型名称；离线模式下使用缓存或内置的模型目录
+func (m *Model) loadCatalog() tea.Cmd {
+	if m.opts.Force {
+		return nil
+	}
+	if m.opts.Offline {
+		url, gen := m.url, m.gen
+		return func() tea.Msg {
+			return catalogMsg{gen: gen, models: config.LoadCatalog(url).IDs()}
+		}
+	}
+	if m.apiKey == "" {
+		return nil
+	}
+	tester := m.opts.NewTester(m.url, m.apiKey)
+	ctx, gen := m.ctx, m.gen
+	return func() tea.Msg {
+		models, err := tester.ListModels(ctx)
+		return catalogMsg{gen: gen, models: models, err: err}
+	}
+}
+
+// testRows 依次测试指定的模型（逐个发送，避免同时请求触发网关限流）
+func (m *Model) testRows(indexes ...int) tea.Cmd {
+	if m.apiKey == "" {
+		m.setMessage(i18n.T("dash.key_missing"), true)
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, i := range indexes {
+		r := &m.rows[i]
+		if r.Status == statusTesting {
+			continue
+		}
+		r.Status = statusTesting
+		r.Err = nil
+		// 每个测试使用独立的 Tester（Tester 不能并发使用）
+		tester := m.opts.NewTester(m.url, m.apiKey)
+		ctx, gen, model, pType := m.ctx, m.gen, r.ID, r.Provider
+		cmds = append(cmds, func() tea.Msg {
+			result, err := tester.TestConnectionAs(ctx, model, pType)
+			return testDoneMsg{gen: gen, model: model, result: result, err: err}
+		})
+	}
+	m.setMessage("", false)
+	return tea.Sequence(cmds...)
+}
+
+// finishTest 记录测试结果，URL / Key 修改前发出的测试结果直接丢弃
+func (m *Model) finishTest(msg testDoneMsg) {
+	if msg.gen != m.gen {
+		return
+	}
+	for i := range m.rows {
+		r := &m.rows[i]
+		if r.ID != msg.model || r.Status != statusTesting {
+			continue
+		}
+		r.Result = msg.result
+		r.Err = msg.err
+		r.Status = statusOK
+		if msg.err != nil {
+			r.Status = statusFailed
+		}
+	}
+}
+
+// save 写入 auth.json 和 opencode.json
+// 保留现有模型的附加字段和路由；已删除模型所在的 provider 没有剩余模型时连同认证条目一并删除
+func (m *Model) save() {
+	ids := make([]string, len(m.rows))
+	for i, r := range m.rows {
+		ids[i] = r.ID
+	}
+	if err := input.ValidateModels(ids); err != nil {
+		m.setMessage(err.Error(), true)
+		return
+	}
+	if m.apiKey == "" {
+		m.setMessage(i18n.T("dash.key_missing"), true)
+		return
+	}
+
+	cfg := m.buildConfig()
+	var removed []string
+	for _, id := range m.providers {
+		if _, ok := cfg.Provider[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+
+	// 备份提示等信息写入状态栏，不能直接输出到全屏界面
+	var notes bytes.Buffer
+	config.SetMessageOutput(&notes)
+	defer config.SetMessageOutput(os.Stdout)
+
+	// 面板中无法逐项询问，冲突按 --on-conflict 处理（未指定时保留两边），在状态栏中提示数量
+	writer := config.NewWriter()
+	conflicts := writer.DetectConflicts(cfg)
+	resolution := m.opts.OnConflict
+	if resolution == "" {
+		resolution = config.ResolveKeepBoth
+	}
+	var skipAuth []string
+	if len(conflicts) > 0 {
+		if resolution == config.ResolveCancel {
+			m.setMessage(i18n.T("dash.conflicts_cancelled", len(conflicts)), true)
+			return
+		}
+		skipAuth = writer.ResolveConflicts(cfg, conflicts, resolution)
+		if len(cfg.Provider) == 0 {
+			m.setMessage(i18n.T("main.conflict_nothing_left"), true)
+			return
+		}
+	}
+	providerIDs := slices.DeleteFunc(config.GetProviderIDs(cfg), func(id string) bool {
+		return slices.Contains(skipAuth, id)
+	})
+
+	authPath, err := auth.NewAuthManager(providerIDs, m.apiKey).LoginRemoving(removed)
+	if err != nil {
+		m.setMessage(i18n.T("main.auth_failed", err), true)
+		return
+	}
+	configPath, err := writer.WriteConfigRemoving(cfg, removed)
+	if err != nil {
+		m.setMessage(i18n.T("main.config_failed", err), true)
+		return
+	}
+
+	m.dirty = false
+	m.providers = config.GetProviderIDs(cfg)
+	m.outcome = Outcome{Saved: true, Config: cfg, ConfigPath: configPath, AuthPath: authPath}
+	m.setMessage(i18n.T("dash.saved", configPath), false)
+	if n := strings.Count(notes.String(), "\n"); n > 0 {
+		m.message += " " + i18n.T("dash.backed_up", n)
+	}
+	if len(conflicts) > 0 {
+		m.message += " " + i18n.T("dash.conflicts", len(conflicts), i18n.T("conflict.resolution_"+string(resolution)))
+	}
+}
+
+// buildConfig 按当前模型列表生成配置，保留现有模型的附加字段、路由和默认模型
+func (m *Model) buildConfig() *config.OpenCodeConfig {
+	specs := make([]config.ModelSpec, 0, len(m.rows))
+	for _, r := range m.rows {
+		pType := r.Provider
+		spec := config.ModelSpec{ID: r.ID, Provider: &pType}
+		if m.existing != nil {
+			spec.Model = m.existing.ModelConfigs[r.ID]
+		}
+		specs = append(specs, spec)
+	}
+	cfg := config.NewDMXAPIConfigFromSpecs(m.url, m.apiKey, specs)
+	cfg.ApplyHeaders(m.opts.Headers)
+	if m.existing != nil {
+		if id, ok := cfg.QualifiedModelID(m.existing.DefaultModel); ok {
+			cfg.Model = id
+		}
+		if id, ok := cfg.QualifiedModelID(m.existing.SmallModel); ok {
+			cfg.SmallModel = id
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,395 @@
+// Package dashboard 全屏配置面板：在一个界面中查看和修改 DMXAPI 配置、测试模型并保存
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
+
+	"dmxapi-config/internal/api"
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/i18n"
+	"dmxapi-config/internal/input"
+)
+
+// defaultURL 没有现有配置时使用的网关地址
+const defaultURL = "https://www.dmxapi.cn"
+
+// ErrNotTerminal 标准输入或输出不是终端，无法显示配置面板
+var ErrNotTerminal error = i18n.Error("dash.not_terminal")
+
+// status 模型的测试状态
+type status int
+
+const (
+	statusUntested status = iota
+	statusTesting
+	statusOK
+	statusFailed
+)
+
+// row 面板中的一个模型
+type row struct {
+	ID       string
+	Provider config.ProviderType
+	Status   status
+	Result   *api.TestResult
+	Err      error
+}
+
+// mode 面板当前的交互状态
+type mode int
+
+const (
+	modeBrowse      mode = iota // 浏览模型列表
+	modeAddModel                // 输入新模型名称
+	modeEditURL                 // 修改 URL
+	modeEditKey                 // 修改 API Key
+	modeConfirmQuit             // 有未保存的修改时确认退出
+)
+
+// Options 配置面板的依赖
+type Options struct {
+	// NewTester 创建 API 测试器（由调用方应用超时、重试等命令行设置）
+	NewTester func(url, apiKey string) *api.Tester
+	// Force 为 true 时添加模型不按网关模型列表校验（--force-models）
+	Force bool
+	// Offline 为 true 时不获取网关模型列表，改用缓存或内置的模型目录校验（--offline）
+	Offline bool
+	// OnConflict 保存时发现冲突的处理方式（--on-conflict），为空时保留两边
+	OnConflict config.ConflictResolution
+	// Headers 保存时写入各 provider 的自定义请求头（由调用方合并现有配置和命令行参数）
+	Headers config.Headers
+}
+
+// Outcome 面板退出时的结果
+type Outcome struct {
+	Saved      bool                   // 是否保存过配置
+	Config     *config.OpenCodeConfig // 最后一次保存的配置
+	ConfigPath string
+	AuthPath   string
+	Tests      []TestOutcome // 最后一次测试结果（未测试的模型不包含在内）
+}
+
+// TestOutcome 单个模型的测试结果
+type TestOutcome struct {
+	Model  string
+	Result *api.TestResult
+	Err    error
+}
+
+// Model 配置面板的 bubbletea 模型
+type Model struct {
+	opts     Options
+	existing *config.ExistingConfig
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	url     string
+	apiKey  string
+	rows    []row
+	cursor  int
+	catalog []string // 网关模型列表，获取失败时为 nil（不校验）
+	gen     int      // URL / Key 每次修改后加一，丢弃修改前发出的测试结果
+	dirty   bool
+
+	// providers 配置文件中当前由本工具写入的 provider，保存时删除其中已没有模型的 provider
+	providers []string
+
+	mode    mode
+	input   textinput.Model
+	spinner spinner.Model
+	message string // 状态栏消息
+	isError bool   // 状态栏消息是否为错误
+
+	outcome Outcome
+}
+
+// New 基于现有配置创建配置面板，existing 为 nil 时从空配置开始
+func New(existing *config.ExistingConfig, opts Options) *Model {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Model{opts: opts, existing: existing, ctx: ctx, cancel: cancel, url: defaultURL}
+	if existing != nil {
+		m.url = existing.URL
+		m.apiKey = existing.APIKey
+		debuglog.AddSecret(existing.APIKey)
+		for _, id := range existing.Models {
+			pType := config.ClassifyModel(id)
+			if t, ok := config.ProviderTypeByID(existing.ModelProviders[id]); ok {
+				pType = t
+			}
+			m.rows = append(m.rows, row{ID: id, Provider: pType})
+			if p := existing.ModelProviders[id]; !slices.Contains(m.providers, p) {
+				m.providers = append(m.providers, p)
+			}
+		}
+	}
+	m.spinner = spinner.New(spinner.WithSpinner(spinner.Dot))
+	m.input = textinput.New()
+	m.input.CharLimit = 256
+	return m
+}
+
+// Run 在全屏模式下运行配置面板，直到用户退出
+func Run(existing *config.ExistingConfig, opts Options) (Outcome, error) {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return Outcome{}, ErrNotTerminal
+	}
+	m := New(existing, opts)
+	defer m.cancel()
+	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return Outcome{}, err
+	}
+	return final.(*Model).result(), nil
+}
+
+// isTerminal 检测文件是否为终端设备（包括 Cygwin/Git Bash）
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// Init 实现 tea.Model：启动加载动画并获取网关模型列表
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.loadCatalog())
+}
+
+// Update 实现 tea.Model
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	case catalogMsg:
+		if msg.gen == m.gen && msg.err == nil {
+			m.catalog = msg.models
+		}
+		return m, nil
+	case testDoneMsg:
+		m.finishTest(msg)
+		return m, nil
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			m.cancel()
+			return m, tea.Quit
+		}
+		switch m.mode {
+		case modeBrowse:
+			return m.updateBrowse(msg)
+		case modeConfirmQuit:
+			if msg.String() == "y" || msg.String() == "Y" {
+				m.cancel()
+				return m, tea.Quit
+			}
+			m.mode = modeBrowse
+			m.setMessage("", false)
+			return m, nil
+		default:
+			return m.updateInput(msg)
+		}
+	}
+	return m, nil
+}
+
+// updateBrowse 处理浏览模式下的按键
+func (m *Model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down":
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+	case "a":
+		m.startInput(modeAddModel, "", i18n.T("dash.placeholder_model"), false)
+	case "d", "delete", "backspace":
+		m.removeSelected()
+	case "t", "enter":
+		if len(m.rows) > 0 {
+			return m, m.testRows(m.cursor)
+		}
+	case "T":
+		indexes := make([]int, len(m.rows))
+		for i := range m.rows {
+			indexes[i] = i
+		}
+		return m, m.testRows(indexes...)
+	case "u":
+		m.startInput(modeEditURL, m.url, defaultURL, false)
+	case "k":
+		m.startInput(modeEditKey, "", "sk-...", true)
+	case "s":
+		m.save()
+	case "q", "esc":
+		if m.dirty {
+			m.mode = modeConfirmQuit
+			m.setMessage(i18n.T("dash.confirm_quit"), true)
+			return m, nil
+		}
+		m.cancel()
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// startInput 进入输入模式
+func (m *Model) startInput(md mode, value, placeholder string, secret bool) {
+	m.mode = md
+	m.input.SetValue(value)
+	m.input.Placeholder = placeholder
+	m.input.EchoMode = textinput.EchoNormal
+	if secret {
+		m.input.EchoMode = textinput.EchoPassword
+	}
+	m.input.CursorEnd()
+	m.input.Focus()
+	m.setMessage("", false)
+}
+
+// updateInput 处理输入模式下的按键：Enter 确认，Esc 取消
+func (m *Model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.mode = modeBrowse
+		m.input.Blur()
+		m.setMessage("", false)
+		return m, nil
+	case tea.KeyEnter:
+		value := strings.TrimSpace(m.input.Value())
+		var cmd tea.Cmd
+		var err error
+		switch m.mode {
+		case modeAddModel:
+			err = m.addModel(value)
+		case modeEditURL:
+			cmd, err = m.setURL(value)
+		case modeEditKey:
+			cmd, err = m.setAPIKey(value)
+		}
+		if err != nil {
+			m.setMessage(err.Error(), true)
+			return m, nil
+		}
+		m.mode = modeBrowse
+		m.input.Blur()
+		return m, cmd
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// addModel 添加模型，名称重复或不在网关模型列表中时返回错误
+func (m *Model) addModel(id string) error {
+	if err := input.ValidateModels([]string{id}); err != nil {
+		return err
+	}
+	if strings.ContainsAny(id, " \t") {
+		return errors.New(i18n.T("dash.model_space"))
+	}
+	for i, r := range m.rows {
+		if r.ID == id {
+			m.cursor = i
+			return errors.New(i18n.T("dash.model_exists", id))
+		}
+	}
+	if m.catalog != nil && !m.opts.Force {
+		if err := input.ValidateModelsInCatalog([]string{id}, m.catalog); err != nil {
+			return err
+		}
+	}
+	m.rows = append(m.rows, row{ID: id, Provider: config.ClassifyModel(id)})
+	m.cursor = len(m.rows) - 1
+	m.dirty = true
+	m.setMessage(i18n.T("dash.model_added", id), false)
+	return nil
+}
+
+// removeSelected 删除当前选中的模型
+func (m *Model) removeSelected() {
+	if len(m.rows) == 0 {
+		return
+	}
+	id := m.rows[m.cursor].ID
+	m.rows = append(m.rows[:m.cursor], m.rows[m.cursor+1:]...)
+	if m.cursor >= len(m.rows) && m.cursor > 0 {
+		m.cursor--
+	}
+	m.dirty = true
+	m.setMessage(i18n.T("dash.model_removed", id), false)
+}
+
+// setURL 修改 URL（去除误粘贴的接口路径），所有模型需要重新测试
+func (m *Model) setURL(raw string) (tea.Cmd, error) {
+	if err := input.ValidateURL(raw); err != nil {
+		return nil, err
+	}
+	info := config.AnalyzeBaseURL(raw)
+	if info.URL == m.url {
+		return nil, nil
+	}
+	m.url = info.URL
+	m.credentialsChanged()
+	msg := i18n.T("dash.url_set", info.URL)
+	if info.Stripped != "" {
+		msg = i18n.T("dash.url_stripped", info.Stripped, info.URL)
+	}
+	m.setMessage(msg, false)
+	return m.loadCatalog(), nil
+}
+
+// setAPIKey 修改 API Key，所有模型需要重新测试
+func (m *Model) setAPIKey(key string) (tea.Cmd, error) {
+	if err := input.ValidateAPIKey(key); err != nil {
+		return nil, err
+	}
+	if key == m.apiKey {
+		return nil, nil
+	}
+	m.apiKey = key
+	debuglog.AddSecret(key)
+	m.credentialsChanged()
+	m.setMessage(i18n.T("dash.key_set"), false)
+	return m.loadCatalog(), nil
+}
+
+// credentialsChanged URL 或 Key 修改后清除测试结果和模型列表
+func (m *Model) credentialsChanged() {
+	m.gen++
+	m.dirty = true
+	m.catalog = nil
+	for i := range m.rows {
+		m.rows[i].Status = statusUntested
+		m.rows[i].Result = nil
+		m.rows[i].Err = nil
+	}
+}
+
+// setMessage 设置状态栏消息
+func (m *Model) setMessage(message string, isError bool) {
+	m.message = message
+	m.isError = isError
+}
+
+// result 汇总面板退出时的结果
+func (m *Model) result() Outcome {
+	out := m.outcome
+	out.Tests = nil
+	for _, r := range m.rows {
+		if r.Status == statusOK || r.Status == statusFailed {
+			out.Tests = append(out.Tests, TestOutcome{Model: r.ID, Result: r.Result, Err: r.Err})
+		}
+	}
+	return out
+}
@@ -0,0 +1,275 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"dmxapi-config/internal/api"
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/mockserver"
+)
+
+const testKey = "sk-mocktestkey1234567890"
+
+// newTestModel 启动模拟网关，基于指向它的现有配置创建面板，HOME 指向临时目录
+func newTestModel(t *testing.T, opts Options, models ...string) (*Model, *mockserver.Server) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	gw := mockserver.New(mockserver.Options{APIKey: testKey})
+	srv := httptest.NewServer(gw)
+	t.Cleanup(srv.Close)
+
+	opts.NewTester = func(url, apiKey string) *api.Tester {
+		tester := api.NewTester(url, apiKey)
+		tester.SetRetryPolicy(api.RetryPolicy{MaxRetries: 0, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+		return tester
+	}
+	existing := &config.ExistingConfig{URL: srv.URL, APIKey: testKey, Models: models, ModelProviders: map[string]string{}}
+	m := New(existing, opts)
+	t.Cleanup(m.cancel)
+	run(m, m.loadCatalog())
+	return m, gw
+}
+
+// run 执行命令并把产生的消息交给 Update，直到没有后续命令（忽略加载动画和退出）
+func run(m *Model, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	msg := cmd()
+	switch msg.(type) {
+	case nil, tea.QuitMsg:
+		return
+	case tea.BatchMsg:
+		for _, c := range msg.(tea.BatchMsg) {
+			run(m, c)
+		}
+		return
+	}
+	// tea.Sequence 返回未导出的 []tea.Cmd 类型
+	if v := reflect.ValueOf(msg); v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			run(m, v.Index(i).Interface().(tea.Cmd))
+		}
+		return
+	}
+	_, next := m.Update(msg)
+	run(m, next)
+}
+
+// press 依次发送按键，字符串按整体输入文本处理
+func press(m *Model, keys ...string) {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		typing := m.mode != modeBrowse && m.mode != modeConfirmQuit && msg.Type == tea.KeyRunes
+		_, cmd := m.Update(msg)
+		if !typing {
+			// 输入框返回的是光标闪烁计时，不需要执行
+			run(m, cmd)
+		}
+	}
+}
+
+func rowIDs(m *Model) []string {
+	ids := make([]string, len(m.rows))
+	for i, r := range m.rows {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestAddAndRemoveModels(t *testing.T) {
+	m, _ := newTestModel(t, Options{}, "gpt-5")
+	if m.catalog == nil {
+		t.Fatal("catalog not loaded from the gateway")
+	}
+
+	press(m, "a", "claude-sonnet-4-5", "enter")
+	if got := rowIDs(m); !reflect.DeepEqual(got, []string{"gpt-5", "claude-sonnet-4-5"}) {
+		t.Fatalf("rows = %v after add", got)
+	}
+	if r := m.rows[1]; r.Provider != config.ProviderAnthropic {
+		t.Errorf("added model provider = %v, want anthropic", r.Provider)
+	}
+	if !m.dirty || m.cursor != 1 {
+		t.Errorf("dirty = %v, cursor = %d after add", m.dirty, m.cursor)
+	}
+
+	// 不在网关模型列表中的模型被拒绝，停留在输入状态
+	press(m, "a", "gpt-55", "enter")
+	if m.mode != modeAddModel || !m.isError || len(m.rows) != 2 {
+		t.Errorf("unknown model: mode = %v, isError = %v, rows = %v", m.mode, m.isError, rowIDs(m))
+	}
+	press(m, "esc")
+
+	press(m, "a", "gpt-5", "enter")
+	if m.mode != modeAddModel || m.cursor != 0 {
+		t.Errorf("duplicate model: mode = %v, cursor = %d", m.mode, m.cursor)
+	}
+	press(m, "esc", "d")
+	if got := rowIDs(m); !reflect.DeepEqual(got, []string{"claude-sonnet-4-5"}) {
+		t.Errorf("rows = %v after remove", got)
+	}
+}
+
+func TestForceSkipsCatalog(t *testing.T) {
+	m, _ := newTestModel(t, Options{Force: true}, "gpt-5")
+	press(m, "a", "my-private-model", "enter")
+	if got := rowIDs(m); len(got) != 2 || got[1] != "my-private-model" {
+		t.Errorf("rows = %v, want my-private-model added with Force", got)
+	}
+}
+
+func TestTestModels(t *testing.T) {
+	m, gw := newTestModel(t, Options{}, "gpt-5", "claude-sonnet-4-5")
+	press(m, "T")
+	for _, r := range m.rows {
+		if r.Status != statusOK || r.Result == nil {
+			t.Errorf("%s status = %v (%v), want ok", r.ID, r.Status, r.Err)
+		}
+	}
+
+	gw.SetFault(mockserver.FaultUnauthorized, 0)
+	press(m, "t")
+	if r := m.rows[0]; r.Status != statusFailed || r.Err == nil {
+		t.Errorf("%s status = %v, want failed", r.ID, r.Status)
+	}
+	if got := m.result().Tests; len(got) != 2 {
+		t.Errorf("outcome tests = %d, want 2", len(got))
+	}
+
+	// 修改 API Key 后之前的测试结果失效
+	gw.SetFault(mockserver.FaultNone, 0)
+	press(m, "k", "sk-anotherkey1234567890", "enter")
+	for _, r := range m.rows {
+		if r.Status != statusUntested {
+			t.Errorf("%s status = %v after key change, want untested", r.ID, r.Status)
+		}
+	}
+	if !m.dirty || m.apiKey != "sk-anotherkey1234567890" {
+		t.Errorf("dirty = %v, apiKey = %q after key change", m.dirty, m.apiKey)
+	}
+}
+
+// readConfig 读取临时 HOME 中的 opencode.json
+func readConfig(t *testing.T) config.OpenCodeConfig {
+	t.Helper()
+	path, _ := config.GetConfigPath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read opencode.json: %v", err)
+	}
+	var cfg config.OpenCodeConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("parse opencode.json: %v", err)
+	}
+	return cfg
+}
+
+func TestSave(t *testing.T) {
+	m, _ := newTestModel(t, Options{}, "gpt-5")
+	press(m, "a", "claude-sonnet-4-5", "enter", "s")
+	if m.isError || m.dirty || !m.outcome.Saved {
+		t.Fatalf("save: message = %q, dirty = %v, saved = %v", m.message, m.dirty, m.outcome.Saved)
+	}
+	cfg := readConfig(t)
+	for id, model := range map[string]string{"dmxapi-openai-responses": "gpt-5", "dmxapi-anthropic": "claude-sonnet-4-5"} {
+		if _, ok := cfg.Provider[id].Models[model]; !ok {
+			t.Errorf("provider %s missing model %s: %+v", id, model, cfg.Provider[id])
+		}
+	}
+	authPath, _ := config.GetAuthPath()
+	if _, err := os.Stat(authPath); err != nil {
+		t.Errorf("auth.json not written: %v", err)
+	}
+
+	// 删除 provider 中的最后一个模型后，该 provider 一并删除
+	press(m, "d", "s")
+	if _, ok := readConfig(t).Provider["dmxapi-anthropic"]; ok {
+		t.Error("dmxapi-anthropic kept after its last model was removed")
+	}
+}
+
+func TestSaveOnConflict(t *testing.T) {
+	tests := []struct {
+		resolution config.ConflictResolution
+		saved      bool
+		userModel  bool // 用户自己的 provider 中是否保留同名模型
+		ourModel   bool // 写入的 dmxapi-anthropic 中是否有同名模型
+	}{
+		{"", true, true, true},
+		{config.ResolveKeepBoth, true, true, true},
+		{config.ResolvePreferDMXAPI, true, false, true},
+		{config.ResolvePreferOthers, true, true, false},
+		{config.ResolveCancel, false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.resolution), func(t *testing.T) {
+			m, _ := newTestModel(t, Options{OnConflict: tt.resolution}, "gpt-5")
+			user := config.OpenCodeConfig{Provider: map[string]config.Provider{
+				"my-claude": {NPM: "@ai-sdk/anthropic", Name: "My Claude", Models: map[string]config.Model{"claude-sonnet-4-5": {}, "claude-opus-4-5": {}}},
+			}}
+			writeJSON(t, user)
+
+			press(m, "a", "claude-sonnet-4-5", "enter", "s")
+			if m.outcome.Saved != tt.saved {
+				t.Fatalf("saved = %v, want %v (message %q)", m.outcome.Saved, tt.saved, m.message)
+			}
+			cfg := readConfig(t)
+			if _, ok := cfg.Provider["my-claude"].Models["claude-sonnet-4-5"]; ok != tt.userModel {
+				t.Errorf("my-claude has claude-sonnet-4-5 = %v, want %v", ok, tt.userModel)
+			}
+			if _, ok := cfg.Provider["dmxapi-anthropic"].Models["claude-sonnet-4-5"]; ok != tt.ourModel {
+				t.Errorf("dmxapi-anthropic has claude-sonnet-4-5 = %v, want %v", ok, tt.ourModel)
+			}
+			if _, ok := cfg.Provider["my-claude"].Models["claude-opus-4-5"]; !ok {
+				t.Error("unrelated model removed from my-claude")
+			}
+		})
+	}
+}
+
+// writeJSON 把配置写入临时 HOME 中的 opencode.json
+func writeJSON(t *testing.T, cfg config.OpenCodeConfig) {
+	t.Helper()
+	path, _ := config.GetConfigPath()
+	data, _ := json.Marshal(cfg)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeysRegisteredForRedaction(t *testing.T) {
+	const loaded, edited = "team-loaded-key-0001", "team-edited-key-0002"
+	t.Setenv("HOME", t.TempDir())
+	m := New(&config.ExistingConfig{URL: "https://www.dmxapi.cn", APIKey: loaded}, Options{Offline: true})
+	t.Cleanup(m.cancel)
+	press(m, "k", edited, "enter")
+	for _, key := range []string{loaded, edited} {
+		if got := debuglog.Redact("key " + key); got != "key [REDACTED]" {
+			t.Errorf("Redact(%q) = %q, want the key registered as a secret", key, got)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package dashboard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"dmxapi-config/internal/api"
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/i18n"
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	labelStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	headerStyle   = lipgloss.NewStyle().Bold(true).Underline(true)
+	selectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("14"))
+	okStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	warnStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+)
+
+// View 实现 tea.Model：标题、连接信息、模型表格、输入框、状态栏和按键说明
+func (m *Model) View() string {
+	var b strings.Builder
+
+	title := titleStyle.Render(i18n.T("dash.title"))
+	if m.dirty {
+		title += " " + warnStyle.Render(i18n.T("dash.unsaved"))
+	}
+	b.WriteString(title + "\n\n")
+
+	key := i18n.T("dash.no_key")
+	if m.apiKey != "" {
+		key = config.MaskAPIKey(m.apiKey)
+	}
+	b.WriteString(labelStyle.Render(i18n.T("dash.url")) + " " + m.url + "\n")
+	b.WriteString(labelStyle.Render(i18n.T("dash.key")) + " " + key + "\n\n")
+
+	b.WriteString(m.viewTable())
+
+	switch m.mode {
+	case modeAddModel, modeEditURL, modeEditKey:
+		b.WriteString("\n" + m.inputPrompt() + " " + m.input.View() + "\n")
+	}
+
+	if m.message != "" {
+		style := okStyle
+		if m.isError {
+			style = errorStyle
+		}
+		b.WriteString("\n" + style.Render(m.message) + "\n")
+	}
+
+	help := i18n.T("dash.help")
+	if m.mode == modeAddModel || m.mode == modeEditURL || m.mode == modeEditKey {
+		help = i18n.T("dash.help_input")
+	}
+	b.WriteString("\n" + labelStyle.Render(help) + "\n")
+	return b.String()
+}
+
+// viewTable 模型表格，选中的失败模型下方显示错误和处理建议
+func (m *Model) viewTable() string {
+	if len(m.rows) == 0 {
+		return labelStyle.Render(i18n.T("dash.no_models")) + "\n"
+	}
+
+	idWidth := lipgloss.Width(i18n.T("dash.col_model"))
+	for _, r := range m.rows {
+		idWidth = max(idWidth, lipgloss.Width(r.ID))
+	}
+	providerWidth := lipgloss.Width(i18n.T("dash.col_provider"))
+	for _, r := range m.rows {
+		providerWidth = max(providerWidth, lipgloss.Width(config.GetProviderInfo(r.Provider).ID))
+	}
+
+	var b strings.Builder
+	b.WriteString("  " + headerStyle.Render(pad(i18n.T("dash.col_model"), idWidth)) + "  " +
+		headerStyle.Render(pad(i18n.T("dash.col_provider"), providerWidth)) + "  " +
+		headerStyle.Render(i18n.T("dash.col_status")) + "\n")
+	for i, r := range m.rows {
+		cursor := "  "
+		id := pad(r.ID, idWidth)
+		if i == m.cursor {
+			cursor = selectedStyle.Render("> ")
+			id = selectedStyle.Render(id)
+		}
+		b.WriteString(cursor + id + "  " + pad(config.GetProviderInfo(r.Provider).ID, providerWidth) + "  " + m.viewStatus(r) + "\n")
+	}
+
+	if r := m.rows[m.cursor]; r.Status == statusFailed && r.Err != nil {
+		b.WriteString("\n" + errorStyle.Render(r.Err.Error()) + "\n")
+		if te, ok := api.AsTestError(r.Err); ok {
+			b.WriteString(labelStyle.Render(te.Hint()) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// viewStatus 单个模型的测试状态
+func (m *Model) viewStatus(r row) string {
+	switch r.Status {
+	case statusTesting:
+		return m.spinner.View() + i18n.T("dash.testing")
+	case statusOK:
+		if r.Result != nil {
+			return okStyle.Render(fmt.Sprintf("✓ %.1fs", r.Result.Duration.Seconds()))
+		}
+		return okStyle.Render("✓")
+	case statusFailed:
+		if te, ok := api.AsTestError(r.Err); ok {
+			return errorStyle.Render("✗ " + string(te.Code))
+		}
+		return errorStyle.Render("✗")
+	}
+	return labelStyle.Render(i18n.T("dash.untested"))
+}
+
+// inputPrompt 输入模式的提示文字
+func (m *Model) inputPrompt() string {
+	switch m.mode {
+	case modeEditURL:
+		return i18n.T("dash.prompt_url")
+	case modeEditKey:
+		return i18n.T("dash.prompt_key")
+	}
+	return i18n.T("dash.prompt_model")
+}
+
+// pad 按显示宽度在右侧补齐空格（中文等宽字符占两列）
+func pad(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+package debuglog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted 替换敏感信息后的占位文本
+const redacted = "[REDACTED]"
+
+// apiKeyPattern 匹配 sk- 形式的密钥，作为未登记密钥的兜底
+var apiKeyPattern = regexp.MustCompile(`sk-[A-Za-z0-9_\-]{8,}`)
+
+var (
+	mu      sync.Mutex
+	out     io.Writer // 为 nil 表示未开启调试日志
+	secrets []string  // 需要在日志中隐去的密钥
+)
+
+// Enable 开启调试日志，日志写入 w
+func Enable(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+}
+
+// Enabled 是否已开启调试日志
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return out != nil
+}
+
+// OpenFile 以追加方式打开日志文件（权限 0600，日志中可能包含模型输出等内容）
+func OpenFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+}
+
+// AddSecret 登记需要在日志中隐去的密钥（如用户输入的 API Key）
+func AddSecret(secret string) {
+	if secret == "" {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, s := range secrets {
+		if s == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+}
+
+// Redact 隐去 s 中已登记的密钥和 sk- 形式的密钥
+func Redact(s string) string {
+	mu.Lock()
+	list := secrets
+	mu.Unlock()
+	for _, secret := range list {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return apiKeyPattern.ReplaceAllString(s, redacted)
+}
+
+// Printf 按分类写入一行调试日志，未开启时不做任何事
+// 输出前统一隐去密钥
+func Printf(category, format string, args ...interface{}) {
+	if !Enabled() {
+		return
+	}
+	line := Redact(fmt.Sprintf(format, args...))
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Fprintf(out, "%s [%s] %s\n", time.Now().Format("2006-01-02T15:04:05.000"), category, line)
+}
@@ -0,0 +1,65 @@
+package debuglog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// capture 开启调试日志并返回日志内容，测试结束后关闭日志并清空登记的密钥和请求头
+func capture(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	Enable(&buf)
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		out = nil
+		secrets = nil
+		customHeaders = map[string]bool{}
+	})
+	return &buf
+}
+
+func TestRedact(t *testing.T) {
+	capture(t)
+	AddSecret("team-secret-123")
+	AddSecret("team-secret-123")
+	AddSecret("")
+
+	tests := []struct {
+		in, want string
+	}{
+		{"no secrets here", "no secrets here"},
+		{"key=team-secret-123", "key=[REDACTED]"},
+		{"team-secret-123 and again team-secret-123", "[REDACTED] and again [REDACTED]"},
+		{"Bearer sk-unregistered12345678", "Bearer [REDACTED]"},
+		{`{"apiKey":"sk-abc_DEF-12345678"}`, `{"apiKey":"[REDACTED]"}`},
+		{"sk-short", "sk-short"}, // 过短，不像密钥
+		{"task-list", "task-list"},
+	}
+	for _, tt := range tests {
+		if got := Redact(tt.in); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if len(secrets) != 1 {
+		t.Errorf("secrets = %v, want one entry", secrets)
+	}
+}
+
+func TestPrintf(t *testing.T) {
+	buf := capture(t)
+	AddSecret("team-secret-123")
+	Printf("config", "read key %s from %s", "team-secret-123", "auth.json")
+	line := buf.String()
+	if !strings.Contains(line, "[config] read key [REDACTED] from auth.json\n") || strings.Contains(line, "team-secret-123") {
+		t.Errorf("log line = %q", line)
+	}
+
+	Enable(nil)
+	Printf("config", "dropped")
+	if strings.Contains(buf.String(), "dropped") {
+		t.Error("Printf wrote while disabled")
+	}
+}
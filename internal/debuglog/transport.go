@@ -0,0 +1,208 @@
+package debuglog
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxBodyLog 日志中记录的请求 / 响应体最大字节数
+const maxBodyLog = 2048
+
+// sensitiveHeaders 值需要整体隐去的请求头（小写）
+var sensitiveHeaders = map[string]bool{
+	"authorization":  true,
+	"x-api-key":      true,
+	"x-goog-api-key": true,
+	"api-key":        true,
+	"cookie":         true,
+}
+
+// sensitiveQueryParams 值需要隐去的 URL 查询参数（小写），如 Gemini 接口的 ?key=
+var sensitiveQueryParams = map[string]bool{
+	"key":          true,
+	"api_key":      true,
+	"apikey":       true,
+	"access_token": true,
+	"token":        true,
+}
+
+// customHeaders 通过 AddSensitiveHeader 登记的请求头（小写），由 mu 保护
+var customHeaders = map[string]bool{}
+
+// AddSensitiveHeader 登记取值需要整体隐去的请求头，用于自定义请求头（取值可能是团队或代理令牌）
+func AddSensitiveHeader(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	customHeaders[strings.ToLower(name)] = true
+}
+
+// isSensitiveHeader 判断请求头的取值是否需要隐去
+func isSensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	mu.Lock()
+	defer mu.Unlock()
+	return sensitiveHeaders[name] || customHeaders[name]
+}
+
+// Transport 返回 HTTP 客户端使用的 Transport
+// 未开启调试日志时直接返回 http.DefaultTransport，开启后记录每个请求和响应
+func Transport() http.RoundTripper {
+	if !Enabled() {
+		return http.DefaultTransport
+	}
+	return &loggingTransport{base: http.DefaultTransport}
+}
+
+// loggingTransport 记录请求方法、URL、请求头、状态码、耗时和截断后的消息体
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target := redactURL(req.URL)
+	Printf("http", "--> %s %s", req.Method, target)
+	Printf("http", "    headers: %s", formatHeaders(req.Header))
+	if req.Body != nil && req.GetBody != nil {
+		// 通过 GetBody 取得请求体副本，不影响实际发送
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(io.LimitReader(body, maxBodyLog+1))
+			body.Close()
+			Printf("http", "    body: %s", formatBody(data, req.Header.Get("Content-Type"), req.ContentLength))
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		Printf("http", "<-- %s %s failed after %s: %v", req.Method, target, elapsed, err)
+		return nil, err
+	}
+
+	Printf("http", "<-- %d %s %s (%s)", resp.StatusCode, req.Method, target, elapsed)
+	Printf("http", "    headers: %s", formatHeaders(resp.Header))
+	resp.Body = &loggingBody{
+		ReadCloser:  resp.Body,
+		contentType: resp.Header.Get("Content-Type"),
+		length:      resp.ContentLength,
+	}
+	return resp, nil
+}
+
+// redactURL 返回隐去敏感查询参数取值后的 URL，未登记的密钥放在查询参数中时同样不会写入日志
+func redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	c := *u
+	params := strings.Split(c.RawQuery, "&")
+	for i, p := range params {
+		if name, _, ok := strings.Cut(p, "="); ok && sensitiveQueryParams[strings.ToLower(name)] {
+			params[i] = name + "=" + redacted
+		}
+	}
+	c.RawQuery = strings.Join(params, "&")
+	return c.String()
+}
+
+// loggingBody 在响应体被关闭时记录已读取内容的前 maxBodyLog 字节
+type loggingBody struct {
+	io.ReadCloser
+	contentType string
+	length      int64
+	head        []byte
+	read        int64
+	logged      bool
+}
+
+func (b *loggingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if room := maxBodyLog + 1 - len(b.head); room > 0 && n > 0 {
+		if room > n {
+			room = n
+		}
+		b.head = append(b.head, p[:room]...)
+	}
+	return n, err
+}
+
+func (b *loggingBody) Close() error {
+	if !b.logged {
+		b.logged = true
+		length := b.length
+		if length < 0 {
+			length = b.read
+		}
+		Printf("http", "    response body: %s", formatBody(b.head, b.contentType, length))
+	}
+	return b.ReadCloser.Close()
+}
+
+// formatHeaders 按名称排序输出请求头，敏感请求头的值整体隐去
+func formatHeaders(h http.Header) string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(h[name], ", ")
+		if isSensitiveHeader(name) {
+			value = redacted
+		}
+		parts = append(parts, name+"="+value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatBody 输出截断后的消息体，二进制内容只记录长度和类型
+func formatBody(data []byte, contentType string, length int64) string {
+	if !isText(contentType) || !utf8.Valid(trimIncompleteRune(data)) {
+		return "<" + formatLength(length, len(data)) + " bytes, " + contentType + ">"
+	}
+	s := string(trimIncompleteRune(data))
+	if len(data) > maxBodyLog {
+		s = string(trimIncompleteRune(data[:maxBodyLog])) + "...(truncated)"
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// isText 根据 Content-Type 判断消息体是否为可读文本，未声明类型时按文本处理
+func isText(contentType string) bool {
+	ct := strings.ToLower(contentType)
+	return ct == "" ||
+		strings.HasPrefix(ct, "text/") ||
+		strings.Contains(ct, "json") ||
+		strings.Contains(ct, "xml") ||
+		strings.Contains(ct, "event-stream") ||
+		strings.Contains(ct, "x-www-form-urlencoded")
+}
+
+// trimIncompleteRune 去掉截断处不完整的 UTF-8 字符
+func trimIncompleteRune(data []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(data) > 0; i++ {
+		if utf8.Valid(data) {
+			return data
+		}
+		data = data[:len(data)-1]
+	}
+	return data
+}
+
+// formatLength 优先使用 Content-Length，未知时使用实际读取的字节数
+func formatLength(length int64, read int) string {
+	if length < 0 {
+		length = int64(read)
+	}
+	return strconv.FormatInt(length, 10)
+}
@@ -0,0 +1,124 @@
+package debuglog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTransportRedaction(t *testing.T) {
+	const key = "AIzaTestKey0123456789"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body) // 原样返回请求体，响应体中同样带有密钥
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name     string
+		register func() // 请求前登记的密钥或请求头
+		path     string
+		header   map[string]string
+		body     string
+		leaks    []string // 不能出现在日志中的内容
+		keeps    []string // 应保留在日志中的内容
+	}{
+		{
+			name:  "query key",
+			path:  "/v1beta/models/gemini-2.5-pro:generateContent?alt=sse&key=" + key,
+			leaks: []string{key},
+			keeps: []string{"alt=sse&key=[REDACTED]"},
+		},
+		{
+			name:   "auth headers",
+			header: map[string]string{"Authorization": "Bearer " + key, "X-Api-Key": key, "X-Goog-Api-Key": key, "Anthropic-Version": "2023-06-01"},
+			leaks:  []string{key},
+			keeps:  []string{"Authorization=[REDACTED]", "X-Api-Key=[REDACTED]", "X-Goog-Api-Key=[REDACTED]", "Anthropic-Version=2023-06-01"},
+		},
+		{
+			name:     "custom header",
+			register: func() { AddSensitiveHeader("x-proxy-token") },
+			header:   map[string]string{"X-Proxy-Token": "proxy-token-1", "X-Team": "ops"},
+			leaks:    []string{"proxy-token-1"},
+			keeps:    []string{"X-Proxy-Token=[REDACTED]", "X-Team=ops"},
+		},
+		{
+			name:     "registered key in body",
+			register: func() { AddSecret(key) },
+			body:     `{"model":"gemini-2.5-pro","apiKey":"` + key + `"}`,
+			leaks:    []string{key},
+			keeps:    []string{`body: {"model":"gemini-2.5-pro","apiKey":"[REDACTED]"}`, `response body: {"model":"gemini-2.5-pro","apiKey":"[REDACTED]"}`},
+		},
+		{
+			name:  "sk key in body",
+			body:  `{"key":"sk-unregistered12345678"}`,
+			leaks: []string{"sk-unregistered12345678"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := capture(t)
+			if tt.register != nil {
+				tt.register()
+			}
+			method := "GET"
+			var body io.Reader
+			if tt.body != "" {
+				method, body = "POST", strings.NewReader(tt.body)
+			}
+			req, _ := http.NewRequest(method, srv.URL+tt.path, body)
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+			resp, err := (&http.Client{Transport: Transport()}).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			log := buf.String()
+			for _, s := range tt.leaks {
+				if strings.Contains(log, s) {
+					t.Errorf("log contains %q:\n%s", s, log)
+				}
+			}
+			for _, s := range tt.keeps {
+				if !strings.Contains(log, s) {
+					t.Errorf("log does not contain %q:\n%s", s, log)
+				}
+			}
+		})
+	}
+}
+
+func TestTransportDisabled(t *testing.T) {
+	if Transport() != http.DefaultTransport {
+		t.Error("Transport() wraps the default transport while logging is disabled")
+	}
+}
+
+func TestFormatBody(t *testing.T) {
+	long := strings.Repeat("a", maxBodyLog-1) + "中文"
+	tests := []struct {
+		name        string
+		data        string
+		contentType string
+		length      int64
+		want        string
+	}{
+		{"json", "{\n  \"a\": 1\n}", "application/json", 12, `{ "a": 1 }`},
+		{"no content type", "plain", "", 5, "plain"},
+		{"truncated on a rune boundary", long[:maxBodyLog+1], "text/plain", int64(len(long)), strings.Repeat("a", maxBodyLog-1) + "...(truncated)"},
+		{"binary", "\x89PNG", "image/png", 2048, "<2048 bytes, image/png>"},
+		{"unknown length", "\xff\xfe", "application/octet-stream", -1, "<2 bytes, application/octet-stream>"},
+	}
+	for _, tt := range tests {
+		if got := formatBody([]byte(tt.data), tt.contentType, tt.length); got != tt.want {
+			t.Errorf("%s: formatBody = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
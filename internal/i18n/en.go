@@ -0,0 +1,407 @@
+package i18n
+
+// en 英文消息目录
+var en = map[string]string{
+	// 命令行
+	"flag.version":          "print the version and exit",
+	"flag.channel":          "update channel: stable (releases only) or beta (includes pre-releases)",
+	"flag.offline":          "offline mode: skip update checks and balance queries, only contact the gateway during setup",
+	"flag.lang":             "interface language: zh-CN or en (defaults to LC_ALL / LANG)",
+	"flag.retries":          "max retries when the API connection test hits rate limits or transient errors (0 disables retries)",
+	"flag.output":           "output format: text (terminal) or json (a single JSON result document for scripts)",
+	"flag.verbose":          "print debug logs (HTTP requests and responses, config read/merge decisions) to stderr with API keys redacted",
+	"flag.log_file":         "append debug logs to the given file (API keys redacted)",
+	"flag.template":         "team configuration template (JSON or YAML, local path or http(s) URL); only the API key is asked",
+	"flag.force_models":     "skip model name validation (do not check models against the gateway or built-in model list)",
+	"flag.probe_auth":       "after the connection test, try both the SDK-native headers (x-api-key / x-goog-api-key) and Bearer and report which the gateway accepts",
+	"flag.probe_images":     "after the connection test, send a tiny image to each selected model and record whether it accepts image input in the model's modalities (one extra request per model)",
+	"flag.probe_reasoning":  "after the connection test, enable thinking for each selected model (Claude thinking, Gemini thinkingConfig, Responses reasoning) and set the model's reasoning field depending on whether reasoning content or tokens come back (one extra request per model)",
+	"flag.timeout":          "timeout for each request (e.g. 30s, 2m); each retry gets a fresh timeout",
+	"flag.provider_prefix":  "provider ID prefix (namespace); use a different prefix for each DMXAPI account",
+	"flag.provider_name":    "provider display name template; {type} becomes Claude, Gemini, etc. and {prefix} the prefix (default \"DMXAPI {type}\", with the prefix appended for non-default prefixes)",
+	"flag.on_conflict":      "how to handle conflicts found before writing (same model in another provider, provider ID already taken, different auth entry): keep, dmxapi, existing or cancel; asks when not set, keep in non-interactive mode",
+	"flag.header":           "custom header for every provider, as \"Name: Value\"; written to options.headers and sent during connection tests; may be repeated",
+	"flag.provider_header":  "custom header for one provider type, as \"TYPE=Name: Value\" (TYPE is anthropic, google, openai or openai-responses); overrides --header with the same name; may be repeated",
+	"usage.header":          "Usage: %s [flags] [command]",
+	"usage.commands":        "Commands:",
+	"usage.cmd_none":        "(none)",
+	"usage.cmd_wizard":      "run the setup wizard",
+	"usage.cmd_update":      "download and install the latest version",
+	"usage.cmd_support":     "bundle diagnostics (versions, paths, sanitized config and last run log)",
+	"usage.cmd_export":      "export the current DMXAPI setup as a template without the API key (default dmxapi-template.yaml, - for stdout)",
+	"usage.cmd_mock_server": "start a mock DMXAPI gateway locally for offline development and tests (see mock-server -h)",
+	"usage.cmd_dashboard":   "View, test and edit the current setup in a full-screen dashboard",
+	"usage.cmd_migrate":     "Migrate the legacy single dmxapi provider to per-model-type providers",
+	"usage.flags":           "Flags:",
+	"usage.default":         "(default %s)",
+	"main.log_file_failed":  "failed to open log file: %w",
+	"main.template_failed":  "Failed to load configuration template: %v",
+	"main.template_loaded":  "Loaded configuration template: %s (%d models)",
+	"main.template_models":  "Models in the template failed validation: %v",
+	"main.namespace":        "Using namespace %s (provider IDs like %s, display names like %s)",
+	"main.namespace_other":  "The config also contains DMXAPI providers in namespace %s (%d models), which will not be changed; use --provider-prefix %s to manage them",
+	"main.unknown_command":  "Unknown command: %s",
+
+	// 主流程
+	"main.legacy_cmd_hint":         "Tip: legacy Windows CMD detected. If text looks garbled, run:",
+	"main.legacy_cmd_suggest":      "Suggestion: use Windows Terminal for full color and character support.",
+	"main.press_enter":             "Press Enter to exit...",
+	"main.opencode_found_version":  "opencode is installed (version: %s)",
+	"main.opencode_found":          "opencode is installed",
+	"main.opencode_missing":        "opencode was not found, please install it before using this tool",
+	"main.opencode_site":           "Website: https://opencode.ai",
+	"main.low_balance":             "Your balance is running low, please top up: https://www.dmxapi.cn",
+	"main.mode_failed":             "Failed to select configuration mode: %v",
+	"main.url_failed":              "Failed to read URL: %v",
+	"main.url_set":                 "URL set: %s",
+	"main.interrupted":             "Interrupted (Ctrl+C); no config files were changed",
+	"main.interrupted_written":     "Interrupted (Ctrl+C); the config files had already been written",
+	"main.url_stripped":            "Removed the endpoint or page path %s from the URL; using base URL %s",
+	"main.url_subpath":             "Detected a subpath deployment (%s); the path will be kept",
+	"main.url_insecure":            "The URL uses http://, so the API key will be sent in plain text; https:// is recommended",
+	"main.url_checking":            "Checking that the URL is reachable...",
+	"main.url_unreachable":         "URL check failed: %v",
+	"main.api_key_failed":          "Failed to read API key: %v",
+	"main.api_key_set":             "API key set",
+	"main.models_failed":           "Failed to read models: %v",
+	"main.models_added":            "Added %d model(s)",
+	"main.catalog_gateway":         "Fetched %d available models from the gateway; model names will be checked against them",
+	"main.catalog_builtin":         "Could not fetch the gateway model list (%v); checking model names against the built-in list",
+	"main.catalog_cached":          "Could not fetch the gateway model list (%v); using the list fetched from the gateway at %s",
+	"main.catalog_offline_cached":  "Offline mode: checking model names against the model list fetched from the gateway at %s",
+	"main.catalog_offline_builtin": "Offline mode: checking model names against the built-in model list",
+	"main.testing":                 "Testing connection...",
+	"main.retrying":                "Request failed (%s), retrying in %.1f s (retry #%d)...",
+	"main.test_ok":                 "API connection test passed!",
+	"main.test_ok_retried":         "API connection test passed (after %d retries, %.1f s total)",
+	"main.auth_probing":            "Checking which authentication styles the gateway accepts...",
+	"main.auth_style_native":       "%s (used by opencode)",
+	"main.auth_style_ok":           "Auth style %s: accepted",
+	"main.auth_style_rejected":     "Auth style %s: failed (%v)",
+	"main.auth_native_rejected":    "The gateway rejects the authentication used by %s (%s), so opencode's requests will fail; contact the gateway administrator",
+	"main.image_probing":           "Checking which models accept image input...",
+	"main.image_supported":         "%s: accepts image input",
+	"main.image_unsupported":       "%s: does not accept image input (%v)",
+	"main.image_unknown":           "%s: could not determine image support (%v); modalities left unset",
+	"main.reasoning_probing":       "Checking which models return reasoning output...",
+	"main.reasoning_supported":     "%s: returns reasoning output",
+	"main.reasoning_tokens":        "%s: returns reasoning output (%d reasoning tokens)",
+	"main.reasoning_none":          "%s: no reasoning output",
+	"main.reasoning_rejected":      "%s: thinking parameters rejected (%v)",
+	"main.reasoning_unknown":       "%s: could not determine reasoning support (%v); reasoning left unset",
+	"main.gateway_unstable":        "The gateway seems unstable; if errors keep occurring, try again later",
+	"main.test_failed":             "API connection test failed: %v",
+	"main.retried_failed":          "Still failing after %d retries",
+	"main.error_code":              "Error code: %s",
+	"main.balance":                 "Account quota: %s",
+	"main.writing":                 "Writing configuration files...",
+	"main.auth_failed":             "Failed to configure authentication: %v",
+	"main.auth_done":               "Authentication configured: %s",
+	"main.config_failed":           "Failed to write configuration: %v",
+	"main.auth_restored":           "auth.json has been restored to its previous contents",
+	"main.auth_restore_failed":     "Failed to restore auth.json: %v; restore it manually from the backup in the same directory",
+	"main.dashboard_json":          "the dashboard command does not support --output json",
+	"main.dashboard_failed":        "Dashboard failed: %v",
+	"main.conflicts":               "Found %d conflicts with the existing configuration before writing:",
+	"main.conflict_failed":         "Failed to choose how to handle conflicts: %v",
+	"main.conflict_cancelled":      "Cancelled, no files were written",
+	"main.conflict_nothing_left":   "No models left to write after skipping conflicts, no files were written",
+	"main.conflict_resolved":       "Conflict handling: %s",
+	"main.headers":                 "Custom headers: %s",
+	"main.config_done":             "Configuration file written: %s",
+	"main.run_opencode":            "Run 'opencode' to get started",
+
+	// 步骤标题
+	"step.url":         "Configure DMXAPI URL",
+	"step.api_key":     "Configure API key",
+	"step.models":      "Configure models",
+	"step.test":        "Test API connection",
+	"step.auth":        "Configure authentication",
+	"step.auth_update": "Update authentication",
+	"step.config":      "Generate configuration file",
+
+	// 配置摘要
+	"summary.title":               "Summary:",
+	"summary.models":              "Models",
+	"summary.default_model":       "Default",
+	"summary.config":              "Config",
+	"summary.auth":                "Auth",
+	"summary.balance":             "Balance",
+	"summary.balance_unavailable": "unavailable",
+
+	// 界面
+	"ui.title":           "OpenCode Setup Tool",
+	"ui.slogan":          "AI within reach",
+	"ui.complete":        "Setup complete!",
+	"ui.choose_mode":     "Choose a configuration mode:",
+	"ui.existing_config": "Existing DMXAPI configuration found",
+	"ui.label_models":    "Models:",
+	"ui.label_balance":   "Quota:",
+	"ui.model_only_mode": "Models-only mode",
+	"ui.new_version":     "New version %s available (current v%s)",
+	"ui.label_download":  "Download:",
+	"ui.label_update":    "Update:",
+	"ui.unknown_output":  "unknown output format: %s (text or json)",
+	"ui.run_update":      "run the update command to upgrade automatically",
+
+	// 输入
+	"input.default":                "[default: %s]",
+	"input.read_failed":            "failed to read input: %w",
+	"input.choose_number":          "Enter option number",
+	"input.invalid_option":         "invalid option: %s (enter 1-%d)",
+	"input.cancelled":              "cancelled by user",
+	"input.mode_title":             "Choose a configuration mode",
+	"input.mode_full":              "Full setup - reconfigure everything",
+	"input.mode_model_only":        "Models only - keep the current URL and API key",
+	"input.url_title":              "Enter the DMXAPI URL",
+	"input.url_desc":               "Leave empty for the default: https://www.dmxapi.cn",
+	"input.url_fallback":           "Enter the DMXAPI URL (leave empty for https://www.dmxapi.cn)",
+	"input.url_reenter_title":      "The URL check failed. Enter the URL again?",
+	"input.url_reenter_yes":        "Re-enter",
+	"input.url_reenter_no":         "Use it anyway",
+	"input.migrate_title":          "Migrate now?",
+	"input.migrate_yes":            "Migrate",
+	"input.migrate_no":             "Not now",
+	"input.migrate_fallback":       "Migrate now? (y/N)",
+	"input.conflict_title":         "How should these conflicts be handled?",
+	"input.conflict_keep":          "Keep both (replace providers and auth entries with the same ID)",
+	"input.conflict_dmxapi":        "Prefer DMXAPI (remove the same models from other providers)",
+	"input.conflict_existing":      "Keep the existing config (skip conflicting models, providers and auth entries)",
+	"input.conflict_cancel":        "Cancel, write nothing",
+	"input.api_key_title":          "Enter your API key",
+	"input.api_key_desc":           "Get one at: https://www.dmxapi.cn/token",
+	"input.api_key_plaintext":      "Note: non-interactive mode, the API key will be shown in plain text",
+	"input.models_title":           "Enter model names, separated by commas",
+	"input.models_desc":            "Available models: https://www.dmxapi.cn/rmb",
+	"input.models_fallback":        "Enter model names (comma separated, e.g. claude-opus-4-5-20251101,DeepSeek-V3.2-Fast)",
+	"input.models_fallback_search": "Enter model names (comma separated; type ?keyword to search the model catalog, e.g. ?claude, ?reasoning)",
+	"input.models_browse_title":    "Select models",
+	"input.models_browse_desc":     "Press / to search (model, vendor, capability), space to select, enter to confirm; models not in the catalog can be entered next",
+	"input.models_extra_title":     "Other models (optional), separated by commas",
+	"input.models_extra_desc":      "Selected models: %d. Press enter to continue",
+	"input.catalog_no_match":       "No model in the catalog matches %q",
+	"input.catalog_more":           "... %d more, use a more specific keyword",
+	"catalog.vendor_other":         "Other",
+	"catalog.cap_image":            "image",
+	"catalog.cap_reasoning":        "reasoning",
+	"catalog.cap_tools":            "tools",
+	"catalog.tier_budget":          "budget",
+	"catalog.tier_standard":        "standard",
+	"catalog.tier_premium":         "premium",
+
+	// 校验
+	"validate.url_empty":             "URL must not be empty",
+	"validate.url_invalid":           "invalid URL: %w",
+	"validate.url_scheme":            "URL must start with http:// or https://",
+	"validate.url_host":              "URL must contain a valid host name",
+	"validate.api_key_empty":         "API key must not be empty",
+	"validate.api_key_short":         "API key is too short",
+	"validate.api_key_space":         "API key must not contain spaces or line breaks",
+	"validate.models_empty":          "at least one model is required",
+	"validate.model_blank":           "model name must not be empty",
+	"validate.model_unknown":         "unknown model %s",
+	"validate.model_unknown_suggest": "unknown model %s, did you mean %s?",
+	"validate.separator":             "; ",
+	"validate.model_force_hint":      " (if the name is correct, use --force-models to skip this check)",
+
+	// API 测试
+	"common.create_request_failed": "failed to create request: %w",
+	"api.marshal_failed":           "failed to encode request: %w",
+	"api.send_failed":              "failed to send request: %v",
+	"api.parse_failed":             "failed to parse response: %v",
+	"api.parse_failed_plain":       "failed to parse response",
+	"api.models_empty":             "the gateway returned an empty model list",
+	"api.empty_content":            "invalid API response: no content returned",
+	"api.empty_candidates":         "invalid API response: no candidates returned",
+	"api.empty_output":             "invalid API response: no output returned",
+	"api.error_status":             "API error (%d): %s",
+	"api.html_response":            "the server returned an HTML page",
+	"api.models_path_not_found":    "%s returned 404; no API found at this address",
+	"api.timeout":                  "no response within %s",
+	"api.cancelled":                "request cancelled",
+
+	// 错误处理建议
+	"hint.auth_invalid":         "The API key is invalid or disabled. Check its status at https://www.dmxapi.cn/token and copy it again",
+	"hint.insufficient_balance": "Insufficient balance or token quota. Top up or raise the token limit at https://www.dmxapi.cn",
+	"hint.model_not_found":      "The model name is wrong or not enabled for this token's group. Check the name at https://www.dmxapi.cn/rmb",
+	"hint.rate_limited":         "Too many requests, please try again later",
+	"hint.upstream_error":       "The gateway or upstream service is temporarily unavailable. Try again later, or contact DMXAPI support if it persists",
+	"hint.bad_request":          "The request was rejected. Check that the model name matches the selected protocol",
+	"hint.wrong_base_path":      "The URL returned a web page instead of the API. Enter only the domain (e.g. https://www.dmxapi.cn) without a page path",
+	"hint.dns_error":            "Could not resolve the host name. Check the URL spelling and your network / DNS settings",
+	"hint.tls_error":            "TLS certificate verification failed. Check the system clock, proxy or corporate HTTPS inspection settings",
+	"hint.timeout":              "The request timed out. Check your network or proxy settings and try again; use --timeout to wait longer for slow gateways",
+	"hint.network_error":        "Could not connect to the server. Check the URL, network and proxy settings",
+	"hint.invalid_response":     "The server returned an unrecognized response. Make sure the URL points to the DMXAPI gateway",
+	"hint.cancelled":            "The test was interrupted (Ctrl+C); no config files were changed",
+	"hint.unknown":              "Please try again later; if it keeps failing, contact DMXAPI support with the error code",
+
+	// 账户额度
+	"account.balance_unavailable": "balance information unavailable",
+	"account.unlimited":           "unlimited (used $%.2f)",
+	"account.remaining":           "$%.2f left of $%.2f",
+	"account.expires":             ", expires %s",
+
+	// 配置文件
+	"config.home_failed":          "failed to get home directory: %w",
+	"config.mkdir_failed":         "failed to create directory: %w",
+	"config.windows_perm":         "Note: Windows does not enforce Unix file permissions (0600/0755); make sure access to the config directory is restricted.",
+	"config.backup_config_failed": "Warning: failed to back up existing config: %v",
+	"config.backup_auth_failed":   "Warning: failed to back up existing auth config: %v",
+	"config.merge_failed":         "failed to merge configuration: %w",
+	"config.marshal_failed":       "failed to encode configuration: %w",
+	"config.write_failed":         "failed to write configuration file: %w",
+	"config.marshal_auth_failed":  "failed to encode auth configuration: %w",
+	"config.write_auth_failed":    "failed to write auth file: %w",
+	"config.read_original_failed": "failed to read original file: %w",
+	"config.backup_failed":        "failed to create backup: %w",
+	"config.backed_up":            "Backed up existing config to: %s",
+	"namespace.bad_prefix":        "invalid provider prefix %q: use lowercase letters and digits joined by single hyphens (e.g. dmxapi, work, dmxapi-team)",
+	"namespace.reserved_prefix":   "provider prefix %q is the ID of a default provider; choose another prefix (e.g. work, dmxapi-team)",
+	"namespace.bad_name":          "provider name template %q must contain {type}, otherwise all providers of the account share one display name",
+
+	// 更新
+	"update.checking":              "Checking for the latest version (%s channel)...",
+	"update.check_failed":          "Update check failed: %v",
+	"update.up_to_date":            "Already on the latest version v%s",
+	"update.downloading":           "New version v%s available (current v%s), downloading...",
+	"update.failed":                "Update failed: %v",
+	"update.manual":                "You can also download it manually: %s",
+	"update.done":                  "Updated to v%s: %s",
+	"updater.unknown_channel":      "unknown update channel: %s (stable or beta)",
+	"updater.fetch_failed":         "failed to fetch releases: %w",
+	"updater.fetch_status":         "failed to fetch releases, status code: %d",
+	"updater.parse_failed":         "failed to parse releases: %w",
+	"updater.no_release":           "no release found on the %s channel",
+	"semver.invalid":               "invalid version: %s",
+	"selfupdate.no_asset":          "version %s has no package for this platform (%s/%s)",
+	"selfupdate.no_checksums":      "version %s does not provide %s, cannot verify the download; update cancelled",
+	"selfupdate.exe_path_failed":   "failed to locate the executable: %w",
+	"selfupdate.checksums_failed":  "failed to download checksums: %w",
+	"selfupdate.checksums_status":  "failed to download checksums, status code: %d",
+	"selfupdate.checksum_missing":  "no checksum entry for %s; update cancelled",
+	"selfupdate.download_failed":   "download failed: %w",
+	"selfupdate.download_status":   "download failed, status code: %d",
+	"selfupdate.temp_failed":       "failed to create temporary file: %w",
+	"selfupdate.interrupted":       "download interrupted: %w",
+	"selfupdate.write_failed":      "failed to write temporary file: %w",
+	"selfupdate.checksum_mismatch": "SHA-256 mismatch (expected %s, got %s); the file may be corrupted or tampered with",
+	"selfupdate.verify_failed":     "the new version cannot run on this system; update cancelled: %w",
+	"selfupdate.replace_failed":    "failed to replace the executable (check directory write permission): %w",
+	"selfupdate.rollback_failed":   "replacement and rollback both failed, please rename %s back to %s manually: %v (rollback error: %v)",
+	"selfupdate.rolled_back":       "failed to replace the executable, rolled back to the previous version: %w",
+	"selfupdate.progress":          "Downloading %3d%% (%.1f/%.1f MB)",
+	"selfupdate.progress_unknown":  "Downloading %.1f MB",
+
+	// 诊断包
+	"support.collecting":    "Collecting diagnostic information...",
+	"support.failed":        "Failed to create support bundle: %v",
+	"support.done":          "Support bundle created: %s",
+	"support.keys_masked":   "API keys and other secrets are masked; the bundle is safe to attach to a ticket",
+	"support.secret_leak":   "safety check failed: %s still contains a raw key; bundle not created",
+	"support.create_failed": "failed to create archive: %w",
+
+	// 配置模板
+	"template.read_failed":        "failed to read template %s: %w",
+	"template.download_status":    "failed to download template %s, status code: %d",
+	"template.parse_failed":       "failed to parse template %s: %w",
+	"template.invalid":            "invalid template %s: %w",
+	"template.duplicate_model":    "model %s is listed more than once",
+	"template.unknown_provider":   "unknown provider type %s (%s); use anthropic, google, openai or openai-responses",
+	"template.bad_pattern":        "invalid routing pattern: %s",
+	"template.default_not_listed": "default model %s is not in the model list",
+
+	// 导出模板
+	"export.no_config":      "no DMXAPI configuration found to export; run the setup wizard first",
+	"export.marshal_failed": "failed to generate template: %w",
+	"export.secret_leak":    "safety check failed: the template still contains the API key; export cancelled",
+	"export.write_failed":   "failed to write template %s: %w",
+	"export.failed":         "Failed to export template: %v",
+	"export.done":           "Template exported: %s (%d models)",
+	"export.key_removed":    "The template contains no API key; teammates enter their own key when importing it with --template",
+
+	// Legacy config migration
+	"migrate.none":            "No legacy dmxapi provider found, nothing to migrate",
+	"migrate.detected":        "Found the legacy single dmxapi provider. This version splits models into one provider per model type; keeping the old entry makes models appear twice in opencode",
+	"migrate.plan":            "The migration will make these changes:",
+	"migrate.move":            "%s: dmxapi → %s",
+	"migrate.duplicate":       "%s: already in %s, the duplicate in dmxapi is removed",
+	"migrate.default_model":   "Default model becomes %s",
+	"migrate.small_model":     "Small model becomes %s",
+	"migrate.remove_provider": "Remove provider dmxapi from opencode.json",
+	"migrate.remove_auth":     "Remove the dmxapi entry from auth.json",
+	"migrate.backup_note":     "opencode.json and auth.json are backed up before they are changed",
+	"migrate.skipped":         "Not migrated; run the migrate command later to finish the migration",
+	"migrate.failed":          "Migration failed: %v",
+	"migrate.done":            "Migration complete: %d models moved to %s",
+
+	// 写入前的冲突检查
+	"conflict.model":               "Model %s is in both provider %s and %s; the opencode model picker will show it twice",
+	"conflict.provider":            "Provider %s already exists (%s) and was not created by this tool; it will be replaced",
+	"conflict.auth":                "auth.json already has other credentials for %s (type %s); they will be replaced",
+	"conflict.unknown_resolution":  "Unknown conflict handling: %s (choose keep, dmxapi, existing or cancel)",
+	"conflict.resolution_keep":     "keep both copies of the same models, replace providers and auth entries with the same ID",
+	"conflict.resolution_dmxapi":   "prefer DMXAPI, remove the same models from other providers",
+	"conflict.resolution_existing": "keep the existing config, skip conflicting models, providers and auth entries",
+	"conflict.resolution_cancel":   "cancel",
+
+	// 自定义请求头
+	"headers.bad_format":   "Invalid header: %q (expected \"Name: Value\")",
+	"headers.bad_provider": "Invalid --provider-header: %q (expected \"TYPE=Name: Value\", TYPE is anthropic, google, openai or openai-responses)",
+	"headers.bad_name":     "Invalid header name: %q",
+	"headers.reserved":     "Header %s is set by this tool or the SDK and cannot be customised",
+	"headers.bad_value":    "The value of header %s must not contain line breaks",
+
+	// 模拟网关
+	"mock.usage":            "Usage: mock-server [flags]",
+	"mock.flag_addr":        "listen address",
+	"mock.flag_key":         "API key that requests must carry (not checked when empty)",
+	"mock.flag_models":      "comma separated models to serve (default: built-in model list)",
+	"mock.flag_text_only":   "comma separated models that reject image input (requests with images get a 400)",
+	"mock.flag_reasoning":   "comma separated reasoning models (return thinking content and reasoning tokens; other models get a 400 for thinking parameters)",
+	"mock.flag_fault":       "fault to inject: %s",
+	"mock.flag_fault_count": "inject the fault into the first N requests only (0 means every request)",
+	"mock.flag_delay":       "response delay for the slow fault",
+	"mock.flag_header":      "header that requests must carry, as \"Name: Value\" (400 when missing or different); may be repeated",
+	"mock.unknown_fault":    "unknown fault %s; available: %s",
+	"mock.listen_failed":    "cannot listen on %s: %v",
+	"mock.listening":        "Mock gateway running at %s (%d models)",
+	"mock.key_required":     "Requests must carry the API key: %s",
+	"mock.fault_enabled":    "Fault injection enabled: %s (first %d requests, 0 means all)",
+	"mock.try":              "Run the setup wizard in another terminal and enter the URL %s",
+	"mock.stop":             "Press Ctrl+C to stop",
+	"mock.stopped":          "Mock gateway stopped after %d requests",
+
+	// Dashboard
+	"dash.not_terminal":        "the dashboard must run in a terminal (both stdin and stdout must be terminals)",
+	"dash.title":               "DMXAPI Dashboard",
+	"dash.unsaved":             "[unsaved]",
+	"dash.url":                 "URL:",
+	"dash.key":                 "Key:",
+	"dash.no_key":              "(not set, press k to enter)",
+	"dash.no_models":           "No models yet, press a to add one",
+	"dash.col_model":           "Model",
+	"dash.col_provider":        "Provider",
+	"dash.col_status":          "Status",
+	"dash.untested":            "untested",
+	"dash.testing":             "testing",
+	"dash.help":                "↑/↓ select  a add  d delete  t test  T test all  u edit URL  k edit key  s save  q quit",
+	"dash.help_input":          "Enter confirm  Esc cancel",
+	"dash.prompt_model":        "Model name:",
+	"dash.prompt_url":          "URL:",
+	"dash.prompt_key":          "API Key:",
+	"dash.placeholder_model":   "e.g. claude-sonnet-4-5",
+	"dash.model_space":         "model names cannot contain spaces",
+	"dash.model_exists":        "model %s is already in the list",
+	"dash.model_added":         "Added model %s (press s to save)",
+	"dash.model_removed":       "Removed model %s (press s to save)",
+	"dash.url_set":             "URL changed to %s, test results cleared",
+	"dash.url_stripped":        "Removed the endpoint path %s from the URL, using %s; test results cleared",
+	"dash.key_set":             "API key changed, test results cleared",
+	"dash.key_missing":         "Press k to enter an API key first",
+	"dash.confirm_quit":        "There are unsaved changes. Quit anyway? (y/N)",
+	"dash.saved":               "Configuration saved to %s",
+	"dash.backed_up":           "(%d files backed up)",
+	"dash.conflicts":           "(%d conflicts with other providers, handled as: %s)",
+	"dash.conflicts_cancelled": "Found %d conflicts with the existing configuration; not saved because of --on-conflict cancel",
+}
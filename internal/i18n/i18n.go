@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Locale 界面语言
+type Locale string
+
+const (
+	ZhCN Locale = "zh-CN" // 简体中文（默认）
+	En   Locale = "en"    // 英文
+)
+
+// catalogs 各语言的消息目录，键为消息 ID，值为 fmt 格式字符串
+var catalogs = map[Locale]map[string]string{
+	ZhCN: zhCN,
+	En:   en,
+}
+
+var (
+	mu      sync.RWMutex
+	current = ZhCN
+)
+
+// SetLocale 设置当前界面语言
+func SetLocale(l Locale) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = l
+}
+
+// Current 返回当前界面语言
+func Current() Locale {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// ParseLocale 解析语言标识，支持 "en"、"en_US.UTF-8"、"zh-CN"、"zh_CN.UTF-8" 等形式
+// 无法识别时返回 false
+func ParseLocale(s string) (Locale, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(s, ".@"); i >= 0 {
+		s = s[:i]
+	}
+	switch {
+	case s == "en" || strings.HasPrefix(s, "en_") || strings.HasPrefix(s, "en-"):
+		return En, true
+	case s == "zh" || strings.HasPrefix(s, "zh_") || strings.HasPrefix(s, "zh-"):
+		return ZhCN, true
+	}
+	return "", false
+}
+
+// Detect 按优先级确定界面语言：--lang 参数 > LC_ALL > LC_MESSAGES > LANG，均无法识别时使用简体中文
+func Detect(flagValue string) Locale {
+	candidates := []string{flagValue, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	for _, c := range candidates {
+		if l, ok := ParseLocale(c); ok {
+			return l
+		}
+	}
+	return ZhCN
+}
+
+// T 返回当前语言下的消息，args 非空时按 fmt.Sprintf 格式化
+// 当前语言缺少该消息时回退到简体中文，仍缺失则返回消息 ID 本身
+func T(key string, args ...interface{}) string {
+	format, ok := catalogs[Current()][key]
+	if !ok {
+		if format, ok = zhCN[key]; !ok {
+			format = key
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Error 按当前语言输出错误信息的哨兵错误，适用于包级 error 变量
+// （包初始化时语言尚未确定，因此在 Error() 调用时才取消息）
+type Error string
+
+// Error 实现 error 接口
+func (e Error) Error() string {
+	return T(string(e))
+}
@@ -0,0 +1,158 @@
+package i18n
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Locale
+		ok   bool
+	}{
+		{"en", En, true},
+		{"EN", En, true},
+		{"en_US.UTF-8", En, true},
+		{"en-GB", En, true},
+		{" en_US@euro ", En, true},
+		{"zh", ZhCN, true},
+		{"zh-CN", ZhCN, true},
+		{"zh_CN.UTF-8", ZhCN, true},
+		{"zh_TW.UTF-8", ZhCN, true},
+		{"", "", false},
+		{"C", "", false},
+		{"POSIX", "", false},
+		{"fr_FR.UTF-8", "", false},
+		{"english", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseLocale(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseLocale(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		flag, lcAll, lcMessages, lang string
+		want                          Locale
+	}{
+		{"", "", "", "", ZhCN},
+		{"", "", "", "en_US.UTF-8", En},
+		{"", "", "en_US.UTF-8", "zh_CN.UTF-8", En},
+		{"", "zh_CN.UTF-8", "en_US.UTF-8", "en_US.UTF-8", ZhCN},
+		{"en", "zh_CN.UTF-8", "", "", En},
+		{"zh", "", "", "en_US.UTF-8", ZhCN},
+		// 无法识别的取值跳过，继续按优先级查找
+		{"fr", "C", "", "en_US.UTF-8", En},
+		{"", "C.UTF-8", "POSIX", "de_DE.UTF-8", ZhCN},
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_MESSAGES", tt.lcMessages)
+		t.Setenv("LANG", tt.lang)
+		if got := Detect(tt.flag); got != tt.want {
+			t.Errorf("Detect(%q) with LC_ALL=%q LC_MESSAGES=%q LANG=%q = %q, want %q",
+				tt.flag, tt.lcAll, tt.lcMessages, tt.lang, got, tt.want)
+		}
+	}
+}
+
+// useLocale 在测试期间切换界面语言
+func useLocale(t *testing.T, l Locale) {
+	t.Helper()
+	prev := Current()
+	SetLocale(l)
+	t.Cleanup(func() { SetLocale(prev) })
+}
+
+func TestTFallback(t *testing.T) {
+	// 临时加入只有中文的消息
+	zhCN["test.zh_only"] = "仅中文 %d"
+	en["test.both"] = "english %s"
+	zhCN["test.both"] = "中文 %s"
+	t.Cleanup(func() {
+		delete(zhCN, "test.zh_only")
+		delete(zhCN, "test.both")
+		delete(en, "test.both")
+	})
+
+	useLocale(t, En)
+	if got := T("test.both", "x"); got != "english x" {
+		t.Errorf("T(test.both) in en = %q, want %q", got, "english x")
+	}
+	if got := T("test.zh_only", 3); got != "仅中文 3" {
+		t.Errorf("T(test.zh_only) in en = %q, want the zh-CN fallback %q", got, "仅中文 3")
+	}
+	if got := T("test.missing"); got != "test.missing" {
+		t.Errorf("T(test.missing) = %q, want the key itself", got)
+	}
+
+	SetLocale(ZhCN)
+	if got := T("test.both", "x"); got != "中文 x" {
+		t.Errorf("T(test.both) in zh-CN = %q, want %q", got, "中文 x")
+	}
+
+	// 未知语言同样回退到简体中文
+	SetLocale("fr")
+	if got := T("test.both", "x"); got != "中文 x" {
+		t.Errorf("T(test.both) in fr = %q, want the zh-CN fallback %q", got, "中文 x")
+	}
+}
+
+func TestError(t *testing.T) {
+	zhCN["test.err"] = "出错了"
+	en["test.err"] = "failed"
+	t.Cleanup(func() {
+		delete(zhCN, "test.err")
+		delete(en, "test.err")
+	})
+
+	// 哨兵错误在 Error() 调用时才按当前语言取消息
+	err := error(Error("test.err"))
+	useLocale(t, En)
+	if err.Error() != "failed" {
+		t.Errorf("Error() in en = %q, want %q", err.Error(), "failed")
+	}
+	SetLocale(ZhCN)
+	if err.Error() != "出错了" {
+		t.Errorf("Error() in zh-CN = %q, want %q", err.Error(), "出错了")
+	}
+	if !errors.Is(err, Error("test.err")) {
+		t.Error("errors.Is did not match the same sentinel key")
+	}
+}
+
+// verbPattern 匹配 fmt 格式动词，匹配前先去掉 %%
+var verbPattern = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z]`)
+
+func TestCatalogParity(t *testing.T) {
+	for key := range zhCN {
+		if _, ok := en[key]; !ok {
+			t.Errorf("key %q is missing from the en catalog", key)
+		}
+	}
+	for key := range en {
+		if _, ok := zhCN[key]; !ok {
+			t.Errorf("key %q is missing from the zh-CN catalog", key)
+		}
+	}
+
+	// 两种语言的格式动词必须一致，否则切换语言后参数会错位
+	for key, zh := range zhCN {
+		translated, ok := en[key]
+		if !ok {
+			continue
+		}
+		zhVerbs := verbPattern.FindAllString(strings.ReplaceAll(zh, "%%", ""), -1)
+		enVerbs := verbPattern.FindAllString(strings.ReplaceAll(translated, "%%", ""), -1)
+		if !slices.Equal(zhVerbs, enVerbs) {
+			t.Errorf("key %q format verbs differ: zh-CN %v, en %v", key, zhVerbs, enVerbs)
+		}
+	}
+}
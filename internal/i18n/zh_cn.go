@@ -0,0 +1,407 @@
+package i18n
+
+// zhCN 简体中文消息目录（默认语言，其他语言缺失的消息会回退到这里）
+var zhCN = map[string]string{
+	// 命令行
+	"flag.version":          "显示版本号并退出",
+	"flag.channel":          "更新通道：stable（仅正式版）或 beta（包含预发布版）",
+	"flag.offline":          "离线模式：不检查更新、不查询余额，只访问配置过程中必须的网关地址",
+	"flag.lang":             "界面语言：zh-CN 或 en（默认按 LC_ALL / LANG 自动选择）",
+	"flag.retries":          "API 连接测试遇到限流或临时错误时的最大重试次数（0 表示不重试）",
+	"flag.output":           "输出格式：text（终端文本）或 json（单个 JSON 结果文档，便于脚本解析）",
+	"flag.verbose":          "输出调试日志（HTTP 请求与响应、配置读写决策）到标准错误，API Key 会被隐去",
+	"flag.log_file":         "将调试日志追加写入指定文件（API Key 会被隐去）",
+	"flag.template":         "团队配置模板（JSON 或 YAML，本地路径或 http(s) URL），只需输入 API Key",
+	"flag.force_models":     "跳过模型名称校验（不检查模型是否在网关模型列表或内置列表中）",
+	"flag.probe_auth":       "连接测试后分别用 SDK 原生请求头（x-api-key / x-goog-api-key）和 Bearer 测试，报告网关接受哪种认证方式",
+	"flag.probe_images":     "连接测试后用一张小图片逐个测试所选模型能否接收图片输入，并将结果写入模型的 modalities（每个模型额外发送一次请求）",
+	"flag.probe_reasoning":  "连接测试后开启思考逐个测试所选模型（Claude thinking、Gemini thinkingConfig、Responses reasoning），根据是否返回思考内容或推理 token 写入模型的 reasoning 字段（每个模型额外发送一次请求）",
+	"flag.timeout":          "单次请求的超时时间（如 30s、2m），重试时每次重新计时",
+	"flag.provider_prefix":  "provider ID 前缀（命名空间），同时配置多个 DMXAPI 账号时为每个账号使用不同的前缀",
+	"flag.provider_name":    "provider 显示名称模板，{type} 替换为 Claude、Gemini 等，{prefix} 替换为前缀（默认 \"DMXAPI {type}\"，非默认前缀时附加前缀）",
+	"flag.on_conflict":      "写入前发现冲突（其他 provider 中的同名模型、被占用的 provider ID、不同的认证条目）时的处理方式：keep、dmxapi、existing 或 cancel，未指定时交互询问，非交互环境下为 keep",
+	"flag.header":           "所有 provider 的自定义请求头，格式 \"Name: Value\"，写入 options.headers 并在连接测试时发送，可重复指定",
+	"flag.provider_header":  "某类 provider 的自定义请求头，格式 \"TYPE=Name: Value\"（TYPE 为 anthropic、google、openai 或 openai-responses），同名时覆盖 --header，可重复指定",
+	"usage.header":          "用法: %s [参数] [命令]",
+	"usage.commands":        "命令:",
+	"usage.cmd_none":        "(无)",
+	"usage.cmd_wizard":      "运行配置向导",
+	"usage.cmd_update":      "下载并安装最新版本",
+	"usage.cmd_support":     "打包诊断信息（版本、路径、脱敏后的配置和最近一次运行日志）",
+	"usage.cmd_export":      "导出当前 DMXAPI 配置为不含 API Key 的模板（默认 dmxapi-template.yaml，- 表示标准输出）",
+	"usage.cmd_mock_server": "在本地启动模拟 DMXAPI 网关，用于离线开发和测试（mock-server -h 查看参数）",
+	"usage.cmd_dashboard":   "在全屏面板中查看、测试和修改现有配置",
+	"usage.cmd_migrate":     "把旧版本的单一 dmxapi provider 迁移为按模型类型拆分的 provider",
+	"usage.flags":           "参数:",
+	"usage.default":         "(默认 %s)",
+	"main.log_file_failed":  "打开日志文件失败: %w",
+	"main.template_failed":  "加载配置模板失败: %v",
+	"main.template_loaded":  "已加载配置模板: %s（%d 个模型）",
+	"main.template_models":  "模板中的模型未通过校验: %v",
+	"main.namespace":        "使用命名空间 %s（provider ID 如 %s，显示名称如 %s）",
+	"main.namespace_other":  "配置文件中还有命名空间 %s 的 DMXAPI 配置（%d 个模型），本次不会修改；使用 --provider-prefix %s 管理",
+	"main.unknown_command":  "未知命令: %s",
+
+	// 主流程
+	"main.legacy_cmd_hint":         "提示: 检测到旧版 Windows CMD，如出现中文或字符乱码，请先运行:",
+	"main.legacy_cmd_suggest":      "建议: 使用 Windows Terminal 可获得完整的颜色和字符显示支持。",
+	"main.press_enter":             "按 Enter 键退出...",
+	"main.opencode_found_version":  "已检测到 opencode 已安装（版本：%s）",
+	"main.opencode_found":          "已检测到 opencode 已安装",
+	"main.opencode_missing":        "未检测到 opencode，请先安装后再使用本工具",
+	"main.opencode_site":           "官网地址：https://opencode.ai",
+	"main.low_balance":             "账户余额较低，请及时充值: https://www.dmxapi.cn",
+	"main.mode_failed":             "选择配置模式失败: %v",
+	"main.url_failed":              "读取URL失败: %v",
+	"main.url_set":                 "URL 已设置: %s",
+	"main.interrupted":             "已中断（Ctrl+C），配置文件未修改",
+	"main.interrupted_written":     "已中断（Ctrl+C），配置文件已写入完成",
+	"main.url_stripped":            "已去除 URL 中的接口或页面路径 %s，使用基础地址 %s",
+	"main.url_subpath":             "检测到子路径部署（%s），将保留该路径",
+	"main.url_insecure":            "URL 使用 http://，API Key 将以明文传输，建议改用 https://",
+	"main.url_checking":            "正在检查地址是否可以访问...",
+	"main.url_unreachable":         "地址检查未通过: %v",
+	"main.api_key_failed":          "读取API Key失败: %v",
+	"main.api_key_set":             "API Key 已设置",
+	"main.models_failed":           "读取模型失败: %v",
+	"main.models_added":            "已添加 %d 个模型",
+	"main.catalog_gateway":         "已获取网关的 %d 个可用模型，将据此校验模型名称",
+	"main.catalog_builtin":         "无法获取网关模型列表（%v），改用内置模型列表校验模型名称",
+	"main.catalog_cached":          "无法获取网关模型列表（%v），改用 %s 从网关获取的模型列表",
+	"main.catalog_offline_cached":  "离线模式：使用 %s 从网关获取的模型列表校验模型名称",
+	"main.catalog_offline_builtin": "离线模式：使用内置模型列表校验模型名称",
+	"main.testing":                 "正在测试连接...",
+	"main.retrying":                "请求失败（%s），%.1f 秒后进行第 %d 次重试...",
+	"main.test_ok":                 "API 连接测试成功！",
+	"main.test_ok_retried":         "API 连接测试成功（重试 %d 次后成功，耗时 %.1f 秒）",
+	"main.auth_probing":            "正在检查网关接受的认证方式...",
+	"main.auth_style_native":       "%s（opencode 使用）",
+	"main.auth_style_ok":           "认证方式 %s: 网关接受",
+	"main.auth_style_rejected":     "认证方式 %s: 失败（%v）",
+	"main.auth_native_rejected":    "网关不接受 %s 使用的认证方式（%s），opencode 的请求将失败，请联系网关管理员",
+	"main.image_probing":           "正在检测模型是否支持图片输入...",
+	"main.image_supported":         "%s: 支持图片输入",
+	"main.image_unsupported":       "%s: 不支持图片输入（%v）",
+	"main.image_unknown":           "%s: 无法判断是否支持图片输入（%v），配置中不设置 modalities",
+	"main.reasoning_probing":       "正在检测模型是否返回思考内容...",
+	"main.reasoning_supported":     "%s: 返回思考内容",
+	"main.reasoning_tokens":        "%s: 返回思考内容（%d 个推理 token）",
+	"main.reasoning_none":          "%s: 没有返回思考内容",
+	"main.reasoning_rejected":      "%s: 不支持思考参数（%v）",
+	"main.reasoning_unknown":       "%s: 无法判断是否返回思考内容（%v），配置中不设置 reasoning",
+	"main.gateway_unstable":        "网关响应不稳定，如使用中频繁报错请稍后再试",
+	"main.test_failed":             "API 连接测试失败: %v",
+	"main.retried_failed":          "已重试 %d 次仍然失败",
+	"main.error_code":              "错误代码: %s",
+	"main.balance":                 "账户额度: %s",
+	"main.writing":                 "正在写入配置文件...",
+	"main.auth_failed":             "认证配置失败: %v",
+	"main.auth_done":               "认证配置完成: %s",
+	"main.config_failed":           "写入配置失败: %v",
+	"main.auth_restored":           "已将 auth.json 恢复为写入前的内容",
+	"main.auth_restore_failed":     "恢复 auth.json 失败: %v，请从同目录下的备份文件手动恢复",
+	"main.dashboard_json":          "dashboard 命令不支持 --output json",
+	"main.dashboard_failed":        "配置面板运行失败: %v",
+	"main.conflicts":               "写入前发现 %d 处与现有配置的冲突:",
+	"main.conflict_failed":         "选择冲突处理方式失败: %v",
+	"main.conflict_cancelled":      "已取消，没有写入任何文件",
+	"main.conflict_nothing_left":   "跳过有冲突的项目后没有可写入的模型，没有写入任何文件",
+	"main.conflict_resolved":       "冲突处理方式: %s",
+	"main.headers":                 "自定义请求头: %s",
+	"main.config_done":             "配置文件已生成: %s",
+	"main.run_opencode":            "运行 'opencode' 启动程序",
+
+	// 步骤标题
+	"step.url":         "配置 DMXAPI URL",
+	"step.api_key":     "配置 API Key",
+	"step.models":      "配置模型",
+	"step.test":        "测试 API 连接",
+	"step.auth":        "配置认证信息",
+	"step.auth_update": "更新认证信息",
+	"step.config":      "生成配置文件",
+
+	// 配置摘要
+	"summary.title":               "配置摘要:",
+	"summary.models":              "模型",
+	"summary.default_model":       "默认",
+	"summary.config":              "配置",
+	"summary.auth":                "认证",
+	"summary.balance":             "余额",
+	"summary.balance_unavailable": "暂不可用",
+
+	// 界面
+	"ui.title":           "OpenCode 配置工具",
+	"ui.slogan":          "让 AI 触手可及",
+	"ui.complete":        "配置完成！",
+	"ui.choose_mode":     "请选择配置模式：",
+	"ui.existing_config": "检测到现有 DMXAPI 配置",
+	"ui.label_models":    "模型:",
+	"ui.label_balance":   "余额:",
+	"ui.model_only_mode": "仅配置模型模式",
+	"ui.new_version":     "发现新版本 %s（当前 v%s）",
+	"ui.label_download":  "下载:",
+	"ui.label_update":    "更新:",
+	"ui.unknown_output":  "未知的输出格式: %s（可选 text、json）",
+	"ui.run_update":      "运行 update 命令自动更新",
+
+	// 输入
+	"input.default":                "[默认: %s]",
+	"input.read_failed":            "读取输入失败: %w",
+	"input.choose_number":          "请输入选项编号",
+	"input.invalid_option":         "无效的选项: %s（请输入 1-%d）",
+	"input.cancelled":              "用户取消",
+	"input.mode_title":             "请选择配置模式",
+	"input.mode_full":              "完整配置 - 重新配置所有选项",
+	"input.mode_model_only":        "仅配置模型 - 保留现有 URL 和 API Key",
+	"input.url_title":              "请输入 DMXAPI URL",
+	"input.url_desc":               "留空使用默认值: https://www.dmxapi.cn",
+	"input.url_fallback":           "请输入 DMXAPI URL（留空使用默认值 https://www.dmxapi.cn）",
+	"input.url_reenter_title":      "地址检查未通过，是否重新输入 URL？",
+	"input.url_reenter_yes":        "重新输入",
+	"input.url_reenter_no":         "仍然使用",
+	"input.migrate_title":          "是否现在迁移？",
+	"input.migrate_yes":            "迁移",
+	"input.migrate_no":             "暂不迁移",
+	"input.migrate_fallback":       "是否现在迁移？(y/N)",
+	"input.conflict_title":         "如何处理这些冲突？",
+	"input.conflict_keep":          "保留两边（替换同 ID 的 provider 和认证条目）",
+	"input.conflict_dmxapi":        "以 DMXAPI 为准（从其他 provider 中删除同名模型）",
+	"input.conflict_existing":      "保留现有配置（跳过有冲突的模型、provider 和认证条目）",
+	"input.conflict_cancel":        "取消，不写入任何文件",
+	"input.api_key_title":          "请输入 API Key",
+	"input.api_key_desc":           "获取地址: https://www.dmxapi.cn/token",
+	"input.api_key_plaintext":      "注意: 非交互模式，API Key 将以明文显示",
+	"input.models_title":           "请输入模型名称，多个用逗号分隔",
+	"input.models_desc":            "可用模型: https://www.dmxapi.cn/rmb",
+	"input.models_fallback":        "请输入模型名称（多个用逗号分隔，如 claude-opus-4-5-20251101,DeepSeek-V3.2-Fast）",
+	"input.models_fallback_search": "请输入模型名称（多个用逗号分隔；输入 ?关键词 搜索模型目录，如 ?claude、?推理）",
+	"input.models_browse_title":    "选择模型",
+	"input.models_browse_desc":     "按 / 搜索（模型、厂商、能力），空格选择，回车确认；目录中没有的模型可以在下一步输入",
+	"input.models_extra_title":     "其他模型（可选），多个用逗号分隔",
+	"input.models_extra_desc":      "已选择 %d 个模型，直接回车即可继续",
+	"input.catalog_no_match":       "模型目录中没有与 %q 匹配的模型",
+	"input.catalog_more":           "……还有 %d 个，请使用更具体的关键词",
+	"catalog.vendor_other":         "其他",
+	"catalog.cap_image":            "图片",
+	"catalog.cap_reasoning":        "推理",
+	"catalog.cap_tools":            "工具",
+	"catalog.tier_budget":          "低价",
+	"catalog.tier_standard":        "标准",
+	"catalog.tier_premium":         "高价",
+
+	// 校验
+	"validate.url_empty":             "URL不能为空",
+	"validate.url_invalid":           "URL格式无效: %w",
+	"validate.url_scheme":            "URL必须以 http:// 或 https:// 开头",
+	"validate.url_host":              "URL必须包含有效的主机名",
+	"validate.api_key_empty":         "API Key不能为空",
+	"validate.api_key_short":         "API Key 长度过短",
+	"validate.api_key_space":         "API Key 不能包含空格或换行符",
+	"validate.models_empty":          "至少需要指定一个模型",
+	"validate.model_blank":           "模型名称不能为空",
+	"validate.model_unknown":         "未知模型 %s",
+	"validate.model_unknown_suggest": "未知模型 %s，您是否想输入 %s？",
+	"validate.separator":             "；",
+	"validate.model_force_hint":      "（确认名称无误可使用 --force-models 跳过检查）",
+
+	// API 测试
+	"common.create_request_failed": "创建请求失败: %w",
+	"api.marshal_failed":           "序列化请求失败: %w",
+	"api.send_failed":              "发送请求失败: %v",
+	"api.parse_failed":             "解析响应失败: %v",
+	"api.parse_failed_plain":       "解析响应失败",
+	"api.models_empty":             "网关返回的模型列表为空",
+	"api.empty_content":            "API响应无效：没有返回任何内容",
+	"api.empty_candidates":         "API响应无效：没有返回任何 candidates",
+	"api.empty_output":             "API响应无效：没有返回任何输出",
+	"api.error_status":             "API错误 (%d): %s",
+	"api.html_response":            "服务器返回了 HTML 页面",
+	"api.models_path_not_found":    "%s 返回 404，该地址下没有找到 API",
+	"api.timeout":                  "请求在 %s 内没有响应",
+	"api.cancelled":                "请求已取消",
+
+	// 错误处理建议（键为 hint.<错误代码>）
+	"hint.auth_invalid":         "API Key 无效或已被禁用，请到 https://www.dmxapi.cn/token 确认令牌状态后重新复制",
+	"hint.insufficient_balance": "账户余额或令牌额度不足，请到 https://www.dmxapi.cn 充值或调整令牌额度",
+	"hint.model_not_found":      "模型名称有误或当前令牌分组未开通该模型，请对照 https://www.dmxapi.cn/rmb 检查模型名称",
+	"hint.rate_limited":         "请求过于频繁，请稍后重试",
+	"hint.upstream_error":       "网关或上游服务暂时不可用，请稍后重试；如持续出现请联系 DMXAPI 客服",
+	"hint.bad_request":          "请求被拒绝，请检查模型名称是否与所选协议匹配",
+	"hint.wrong_base_path":      "URL 返回的是网页而不是 API，请只填写域名（如 https://www.dmxapi.cn），不要包含页面路径",
+	"hint.dns_error":            "无法解析域名，请检查 URL 拼写和网络 / DNS 设置",
+	"hint.tls_error":            "TLS 证书校验失败，请检查系统时间、代理或公司网络的 HTTPS 拦截设置",
+	"hint.timeout":              "请求超时，请检查网络或代理设置后重试；网关响应较慢时可用 --timeout 延长超时时间",
+	"hint.network_error":        "无法连接到服务器，请检查 URL、网络和代理设置",
+	"hint.invalid_response":     "服务器返回了无法识别的响应，请确认 URL 指向 DMXAPI 网关",
+	"hint.cancelled":            "测试被用户中断（Ctrl+C），配置文件未修改",
+	"hint.unknown":              "请稍后重试；如持续失败请联系 DMXAPI 客服并提供错误代码",
+
+	// 账户额度
+	"account.balance_unavailable": "余额信息暂不可用",
+	"account.unlimited":           "无限额度（已用 $%.2f）",
+	"account.remaining":           "剩余 $%.2f / 总额 $%.2f",
+	"account.expires":             "，%s 过期",
+
+	// 配置文件
+	"config.home_failed":          "获取用户目录失败: %w",
+	"config.mkdir_failed":         "创建目录失败: %w",
+	"config.windows_perm":         "注意: Windows 不支持 Unix 文件权限 (0600/0755)，请确保配置文件所在目录的访问权限受限。",
+	"config.backup_config_failed": "警告: 备份现有配置失败: %v",
+	"config.backup_auth_failed":   "警告: 备份现有认证配置失败: %v",
+	"config.merge_failed":         "合并配置失败: %w",
+	"config.marshal_failed":       "序列化配置失败: %w",
+	"config.write_failed":         "写入配置文件失败: %w",
+	"config.marshal_auth_failed":  "序列化认证配置失败: %w",
+	"config.write_auth_failed":    "写入认证文件失败: %w",
+	"config.read_original_failed": "读取原文件失败: %w",
+	"config.backup_failed":        "创建备份失败: %w",
+	"config.backed_up":            "已备份现有配置到: %s",
+	"namespace.bad_prefix":        "provider 前缀 %q 无效：只能包含小写字母和数字，用单个 - 连接（如 dmxapi、work、dmxapi-team）",
+	"namespace.reserved_prefix":   "provider 前缀 %q 与默认的 provider ID 相同，请换一个前缀（如 work、dmxapi-team）",
+	"namespace.bad_name":          "provider 名称模板 %q 必须包含 {type}，否则同一账号的各个 provider 显示名称相同",
+
+	// 更新
+	"update.checking":              "正在检查最新版本（%s 通道）...",
+	"update.check_failed":          "检查更新失败: %v",
+	"update.up_to_date":            "当前已是最新版本 v%s",
+	"update.downloading":           "发现新版本 v%s（当前 v%s），开始下载...",
+	"update.failed":                "更新失败: %v",
+	"update.manual":                "也可以手动下载: %s",
+	"update.done":                  "已更新到 v%s: %s",
+	"updater.unknown_channel":      "未知的更新通道: %s（可选 stable、beta）",
+	"updater.fetch_failed":         "获取版本列表失败: %w",
+	"updater.fetch_status":         "获取版本列表失败，状态码: %d",
+	"updater.parse_failed":         "解析版本列表失败: %w",
+	"updater.no_release":           "未找到 %s 通道的发布版本",
+	"semver.invalid":               "无效的版本号: %s",
+	"selfupdate.no_asset":          "版本 %s 未提供当前平台（%s/%s）的安装包",
+	"selfupdate.no_checksums":      "版本 %s 未提供 %s，无法校验下载文件，已取消更新",
+	"selfupdate.exe_path_failed":   "获取程序路径失败: %w",
+	"selfupdate.checksums_failed":  "下载校验文件失败: %w",
+	"selfupdate.checksums_status":  "下载校验文件失败，状态码: %d",
+	"selfupdate.checksum_missing":  "校验文件中没有 %s 的记录，已取消更新",
+	"selfupdate.download_failed":   "下载失败: %w",
+	"selfupdate.download_status":   "下载失败，状态码: %d",
+	"selfupdate.temp_failed":       "创建临时文件失败: %w",
+	"selfupdate.interrupted":       "下载中断: %w",
+	"selfupdate.write_failed":      "写入临时文件失败: %w",
+	"selfupdate.checksum_mismatch": "SHA-256 校验失败（期望 %s，实际 %s），文件可能已损坏或被篡改",
+	"selfupdate.verify_failed":     "新版本无法在当前系统运行，已取消更新: %w",
+	"selfupdate.replace_failed":    "替换程序失败（请检查目录写权限）: %w",
+	"selfupdate.rollback_failed":   "替换程序失败且回滚失败，请手动将 %s 改回 %s: %v（回滚错误: %v）",
+	"selfupdate.rolled_back":       "替换程序失败，已回滚到原版本: %w",
+	"selfupdate.progress":          "下载中 %3d%% (%.1f/%.1f MB)",
+	"selfupdate.progress_unknown":  "下载中 %.1f MB",
+
+	// 诊断包
+	"support.collecting":    "正在收集诊断信息...",
+	"support.failed":        "生成诊断包失败: %v",
+	"support.done":          "诊断包已生成: %s",
+	"support.keys_masked":   "API Key 等密钥已全部遮蔽，可以直接附在工单中",
+	"support.secret_leak":   "安全检查未通过：%s 中仍包含原始密钥，已取消生成",
+	"support.create_failed": "创建归档失败: %w",
+
+	// 配置模板
+	"template.read_failed":        "读取模板 %s 失败: %w",
+	"template.download_status":    "下载模板 %s 失败，状态码: %d",
+	"template.parse_failed":       "解析模板 %s 失败: %w",
+	"template.invalid":            "模板 %s 无效: %w",
+	"template.duplicate_model":    "模型 %s 重复出现",
+	"template.unknown_provider":   "未知的 provider 类型 %s（%s），可选 anthropic、google、openai、openai-responses",
+	"template.bad_pattern":        "无效的路由规则: %s",
+	"template.default_not_listed": "默认模型 %s 不在模型列表中",
+
+	// 导出模板
+	"export.no_config":      "没有找到可导出的 DMXAPI 配置，请先运行配置向导",
+	"export.marshal_failed": "生成模板失败: %w",
+	"export.secret_leak":    "安全检查未通过：模板中仍包含 API Key，已取消导出",
+	"export.write_failed":   "写入模板 %s 失败: %w",
+	"export.failed":         "导出模板失败: %v",
+	"export.done":           "模板已导出: %s（%d 个模型）",
+	"export.key_removed":    "模板不包含 API Key，团队成员使用 --template 导入时需输入自己的 Key",
+
+	// 迁移旧版本配置
+	"migrate.none":            "没有发现旧版本的 dmxapi provider，无需迁移",
+	"migrate.detected":        "发现旧版本的单一 dmxapi provider。新版本按模型类型拆分为多个 provider，保留旧条目会导致模型在 opencode 中重复显示",
+	"migrate.plan":            "迁移将进行以下修改:",
+	"migrate.move":            "%s: dmxapi → %s",
+	"migrate.duplicate":       "%s: 已在 %s 中，删除 dmxapi 中的重复条目",
+	"migrate.default_model":   "默认模型改为 %s",
+	"migrate.small_model":     "轻量模型改为 %s",
+	"migrate.remove_provider": "从 opencode.json 删除 provider dmxapi",
+	"migrate.remove_auth":     "从 auth.json 删除 dmxapi 的认证条目",
+	"migrate.backup_note":     "修改前会自动备份 opencode.json 和 auth.json",
+	"migrate.skipped":         "未迁移，之后可以运行 migrate 命令完成迁移",
+	"migrate.failed":          "迁移失败: %v",
+	"migrate.done":            "迁移完成：%d 个模型已移至 %s",
+
+	// 写入前的冲突检查
+	"conflict.model":               "模型 %s 同时存在于 provider %s 和 %s，opencode 的模型列表中会出现两个同名模型",
+	"conflict.provider":            "provider %s 已存在（%s），不是本工具创建的，写入时会被替换",
+	"conflict.auth":                "auth.json 中已有 %s 的其他认证信息（类型 %s），写入时会被替换",
+	"conflict.unknown_resolution":  "未知的冲突处理方式: %s（可选 keep、dmxapi、existing、cancel）",
+	"conflict.resolution_keep":     "保留两边的同名模型，替换同 ID 的 provider 和认证条目",
+	"conflict.resolution_dmxapi":   "以 DMXAPI 为准，从其他 provider 中删除同名模型",
+	"conflict.resolution_existing": "保留现有配置，跳过有冲突的模型、provider 和认证条目",
+	"conflict.resolution_cancel":   "取消",
+
+	// 自定义请求头
+	"headers.bad_format":   "请求头格式错误: %q（应为 \"Name: Value\"）",
+	"headers.bad_provider": "--provider-header 格式错误: %q（应为 \"TYPE=Name: Value\"，TYPE 为 anthropic、google、openai 或 openai-responses）",
+	"headers.bad_name":     "请求头名称无效: %q",
+	"headers.reserved":     "请求头 %s 由本工具或 SDK 设置，不能自定义",
+	"headers.bad_value":    "请求头 %s 的取值不能包含换行",
+
+	// 模拟网关
+	"mock.usage":            "用法: mock-server [参数]",
+	"mock.flag_addr":        "监听地址",
+	"mock.flag_key":         "要求请求携带的 API Key（为空时不校验）",
+	"mock.flag_models":      "提供的模型列表，逗号分隔（默认使用内置模型列表）",
+	"mock.flag_text_only":   "不支持图片输入的模型，逗号分隔（请求中带图片时返回 400）",
+	"mock.flag_reasoning":   "推理模型，逗号分隔（返回思考内容和推理 token；其他模型收到思考参数时返回 400）",
+	"mock.flag_fault":       "注入的故障：%s",
+	"mock.flag_fault_count": "只对前 N 个请求注入故障（0 表示全部请求）",
+	"mock.flag_delay":       "slow 故障的响应延迟",
+	"mock.flag_header":      "要求请求携带的请求头，格式 \"Name: Value\"，缺少或取值不同时返回 400，可重复指定",
+	"mock.unknown_fault":    "未知的故障类型 %s，可选: %s",
+	"mock.listen_failed":    "无法监听 %s: %v",
+	"mock.listening":        "模拟网关已启动: %s（%d 个模型）",
+	"mock.key_required":     "请求需要携带 API Key: %s",
+	"mock.fault_enabled":    "已开启故障注入: %s（前 %d 个请求，0 表示全部）",
+	"mock.try":              "在另一个终端运行配置向导并输入 URL %s 即可使用",
+	"mock.stop":             "按 Ctrl+C 停止",
+	"mock.stopped":          "模拟网关已停止，共处理 %d 个请求",
+
+	// 配置面板
+	"dash.not_terminal":        "配置面板需要在终端中运行（标准输入和输出都必须是终端）",
+	"dash.title":               "DMXAPI 配置面板",
+	"dash.unsaved":             "[未保存]",
+	"dash.url":                 "URL:",
+	"dash.key":                 "Key:",
+	"dash.no_key":              "(未设置，按 k 输入)",
+	"dash.no_models":           "还没有模型，按 a 添加",
+	"dash.col_model":           "模型",
+	"dash.col_provider":        "Provider",
+	"dash.col_status":          "状态",
+	"dash.untested":            "未测试",
+	"dash.testing":             "测试中",
+	"dash.help":                "↑/↓ 选择  a 添加  d 删除  t 测试  T 全部测试  u 修改 URL  k 修改 Key  s 保存  q 退出",
+	"dash.help_input":          "Enter 确认  Esc 取消",
+	"dash.prompt_model":        "模型名称:",
+	"dash.prompt_url":          "URL:",
+	"dash.prompt_key":          "API Key:",
+	"dash.placeholder_model":   "如 claude-sonnet-4-5",
+	"dash.model_space":         "模型名称不能包含空格",
+	"dash.model_exists":        "模型 %s 已在列表中",
+	"dash.model_added":         "已添加模型 %s（按 s 保存）",
+	"dash.model_removed":       "已删除模型 %s（按 s 保存）",
+	"dash.url_set":             "URL 已修改为 %s，测试结果已清除",
+	"dash.url_stripped":        "已去除 URL 中的接口路径 %s，使用 %s，测试结果已清除",
+	"dash.key_set":             "API Key 已修改，测试结果已清除",
+	"dash.key_missing":         "请先按 k 输入 API Key",
+	"dash.confirm_quit":        "有未保存的修改，确定退出吗？(y/N)",
+	"dash.saved":               "配置已保存到 %s",
+	"dash.backed_up":           "（已备份 %d 个文件）",
+	"dash.conflicts":           "（%d 处与其他 provider 冲突，处理方式：%s）",
+	"dash.conflicts_cancelled": "发现 %d 处与现有配置的冲突，按 --on-conflict cancel 未保存",
+}
@@ -0,0 +1,93 @@
+package input
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/i18n"
+)
+
+// maxSearchResults 非 TTY 环境下搜索模型目录时最多显示的结果数
+const maxSearchResults = 30
+
+// describeModel 模型目录中一个模型的单行说明：ID、厂商、上下文长度、能力和价格档位
+func describeModel(m config.CatalogModel) string {
+	vendor := m.Vendor
+	if vendor == "" {
+		vendor = i18n.T("catalog.vendor_other")
+	}
+	var details []string
+	if m.Context > 0 {
+		details = append(details, formatTokens(m.Context))
+	}
+	var caps []string
+	for _, c := range m.Capabilities {
+		caps = append(caps, i18n.T("catalog.cap_"+c))
+	}
+	if len(caps) > 0 {
+		details = append(details, strings.Join(caps, " "))
+	}
+	if m.Tier != "" {
+		details = append(details, i18n.T("catalog.tier_"+m.Tier))
+	}
+	return strings.TrimRight(fmt.Sprintf("%-28s  %-10s  %s", m.ID, vendor, strings.Join(details, " · ")), " ")
+}
+
+// formatTokens 将 token 数格式化为 200K、1M 等简写
+func formatTokens(n int) string {
+	if n >= 1_000_000 {
+		return fmt.Sprintf("%gM", math.Round(float64(n)/100_000)/10)
+	}
+	return fmt.Sprintf("%dK", n/1000)
+}
+
+// searchCatalog 返回说明文字（见 describeModel）包含查询中所有关键词的模型（不区分大小写）
+// 与 TTY 环境下的筛选一致，可以按模型、厂商、能力或价格档位搜索
+func searchCatalog(models []config.CatalogModel, query string) []config.CatalogModel {
+	terms := strings.Fields(strings.ToLower(query))
+	var found []config.CatalogModel
+	for _, m := range models {
+		text := strings.ToLower(describeModel(m))
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+// printCatalogMatches 非 TTY 环境下输出模型目录的搜索结果
+func (c *Collector) printCatalogMatches(query string) {
+	found := searchCatalog(c.browse.Models, query)
+	if len(found) == 0 {
+		fmt.Fprintf(c.out, "  %s\n", i18n.T("input.catalog_no_match", query))
+		return
+	}
+	for i, m := range found {
+		if i == maxSearchResults {
+			fmt.Fprintf(c.out, "  %s\n", i18n.T("input.catalog_more", len(found)-maxSearchResults))
+			break
+		}
+		fmt.Fprintf(c.out, "    %s\n", describeModel(m))
+	}
+}
+
+// mergeModels 合并从目录中选择的模型和手动输入的模型，去除重复项
+func mergeModels(selected, typed []string) []string {
+	models := append([]string(nil), selected...)
+	for _, m := range typed {
+		if !slices.Contains(models, m) {
+			models = append(models, m)
+		}
+	}
+	return models
+}
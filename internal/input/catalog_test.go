@@ -0,0 +1,66 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/i18n"
+)
+
+func TestFormatTokens(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1048576, "1M"},
+		{1000000, "1M"},
+		{2000000, "2M"},
+		{1500000, "1.5M"},
+		{200000, "200K"},
+		{128000, "128K"},
+		{8192, "8K"},
+	}
+	for _, tt := range tests {
+		if got := formatTokens(tt.n); got != tt.want {
+			t.Errorf("formatTokens(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestSearchCatalog(t *testing.T) {
+	locale := i18n.Current()
+	t.Cleanup(func() { i18n.SetLocale(locale) })
+
+	models := []config.CatalogModel{
+		{ID: "claude-sonnet-4-5", Vendor: "Anthropic", Context: 200000, Capabilities: []string{"image", "reasoning", "tools"}, Tier: "standard"},
+		{ID: "claude-haiku-4-5", Vendor: "Anthropic", Context: 200000, Capabilities: []string{"image", "tools"}, Tier: "budget"},
+		{ID: "gemini-2.5-pro", Vendor: "Google", Context: 1048576, Capabilities: []string{"image", "reasoning"}, Tier: "premium"},
+		{ID: "my-model"},
+	}
+	tests := []struct {
+		locale i18n.Locale
+		query  string
+		want   []string
+	}{
+		{i18n.En, "claude", []string{"claude-sonnet-4-5", "claude-haiku-4-5"}},
+		{i18n.En, "ANTHROPIC reasoning", []string{"claude-sonnet-4-5"}},
+		{i18n.En, "reasoning 1M", []string{"gemini-2.5-pro"}},
+		{i18n.En, "budget  tools", []string{"claude-haiku-4-5"}},
+		{i18n.En, "other", []string{"my-model"}},
+		{i18n.En, "claude premium", nil},
+		{i18n.En, "", []string{"claude-sonnet-4-5", "claude-haiku-4-5", "gemini-2.5-pro", "my-model"}},
+		{i18n.ZhCN, "推理 anthropic", []string{"claude-sonnet-4-5"}},
+		{i18n.ZhCN, "低价", []string{"claude-haiku-4-5"}},
+	}
+	for _, tt := range tests {
+		i18n.SetLocale(tt.locale)
+		var got []string
+		for _, m := range searchCatalog(models, tt.query) {
+			got = append(got, m.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("[%s] searchCatalog(%q) = %v, want %v", tt.locale, tt.query, got, tt.want)
+		}
+	}
+}
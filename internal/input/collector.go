@@ -4,11 +4,15 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/mattn/go-isatty"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/i18n"
 )
 
 // ConfigMode 配置模式
@@ -19,12 +23,64 @@ const (
 	ConfigModeModelOnly ConfigMode = 2 // 仅配置模型
 )
 
+// ErrUserCancelled 用户在交互界面中取消输入（如按下 Ctrl+C）
+var ErrUserCancelled error = i18n.Error("input.cancelled")
+
 // Collector 用户输入收集器
-type Collector struct{}
+type Collector struct {
+	out     io.Writer       // 提示信息和交互界面的输出位置
+	in      *bufio.Reader   // 非 TTY 环境下共用的输入缓冲，避免多次读取时丢失已缓冲的行
+	catalog []string        // 用于校验模型名称的模型列表，为 nil 时不校验
+	browse  *config.Catalog // 输入模型时可以浏览和搜索的模型目录，为 nil 时只能手动输入
+}
 
 // NewCollector 创建新的输入收集器
 func NewCollector() *Collector {
-	return &Collector{}
+	return &Collector{
+		out: os.Stdout,
+		in:  bufio.NewReader(os.Stdin),
+	}
+}
+
+// SetOutput 设置提示信息和交互界面的输出位置
+// JSON 输出模式下改为标准错误，避免混入结果文档
+func (c *Collector) SetOutput(w io.Writer) {
+	c.out = w
+}
+
+// SetModelCatalog 设置校验模型名称使用的模型列表（网关 /v1/models 或内置列表）
+// 设置后 CollectModels 拒绝不在列表中的模型；传入 nil 关闭校验
+func (c *Collector) SetModelCatalog(models []string) {
+	c.catalog = models
+}
+
+// SetModelBrowser 设置输入模型时可以浏览和搜索的模型目录；传入 nil 时只能手动输入
+func (c *Collector) SetModelBrowser(catalog *config.Catalog) {
+	if catalog != nil && len(catalog.Models) == 0 {
+		catalog = nil
+	}
+	c.browse = catalog
+}
+
+// validateModels 验证模型列表，设置了模型列表时同时检查模型是否存在
+func (c *Collector) validateModels(models []string) error {
+	if c.catalog == nil {
+		return ValidateModels(models)
+	}
+	return ValidateModelsInCatalog(models, c.catalog)
+}
+
+// ValidateModels 按 CollectModels 的规则验证不经交互输入的模型（如模板中的模型）
+func (c *Collector) ValidateModels(models []string) error {
+	return c.validateModels(models)
+}
+
+// run 运行单个 huh 输入项，输出到 c.out
+func (c *Collector) run(field huh.Field) error {
+	return huh.NewForm(huh.NewGroup(field)).
+		WithShowHelp(false).
+		WithOutput(c.out).
+		Run()
 }
 
 // isTerminal 检测标准输入是否为终端设备（问题5修复）
@@ -49,16 +105,15 @@ func isTTYError(err error) bool {
 
 // fallbackInput 在非 TTY 环境下使用 bufio 读取一行输入
 // 当 huh 不可用时（如脚本重定向、旧版 Windows）提供基础输入能力
-func fallbackInput(prompt, defaultVal string) (string, error) {
+func (c *Collector) fallbackInput(prompt, defaultVal string) (string, error) {
 	if defaultVal != "" {
-		fmt.Printf("  %s [默认: %s]: ", prompt, defaultVal)
+		fmt.Fprintf(c.out, "  %s %s: ", prompt, i18n.T("input.default", defaultVal))
 	} else {
-		fmt.Printf("  %s: ", prompt)
+		fmt.Fprintf(c.out, "  %s: ", prompt)
 	}
-	reader := bufio.NewReader(os.Stdin)
-	line, err := reader.ReadString('\n')
+	line, err := c.in.ReadString('\n')
 	if err != nil {
-		return "", fmt.Errorf("读取输入失败: %w", err)
+		return "", fmt.Errorf(i18n.T("input.read_failed"), err)
 	}
 	line = strings.TrimSpace(line)
 	if line == "" {
@@ -68,21 +123,20 @@ func fallbackInput(prompt, defaultVal string) (string, error) {
 }
 
 // fallbackSelect 在非 TTY 环境下使用数字编号替代交互式下拉菜单
-func fallbackSelect(prompt string, options []string) (int, error) {
-	fmt.Printf("  %s\n", prompt)
+func (c *Collector) fallbackSelect(prompt string, options []string) (int, error) {
+	fmt.Fprintf(c.out, "  %s\n", prompt)
 	for i, opt := range options {
-		fmt.Printf("    %d) %s\n", i+1, opt)
+		fmt.Fprintf(c.out, "    %d) %s\n", i+1, opt)
 	}
-	fmt.Print("  请输入选项编号: ")
-	reader := bufio.NewReader(os.Stdin)
-	line, err := reader.ReadString('\n')
+	fmt.Fprint(c.out, "  "+i18n.T("input.choose_number")+": ")
+	line, err := c.in.ReadString('\n')
 	if err != nil {
-		return 0, fmt.Errorf("读取输入失败: %w", err)
+		return 0, fmt.Errorf(i18n.T("input.read_failed"), err)
 	}
 	line = strings.TrimSpace(line)
 	var n int
 	if _, err := fmt.Sscanf(line, "%d", &n); err != nil || n < 1 || n > len(options) {
-		return 0, fmt.Errorf("无效的选项: %s（请输入 1-%d）", line, len(options))
+		return 0, fmt.Errorf(i18n.T("input.invalid_option"), line, len(options))
 	}
 	return n - 1, nil
 }
@@ -94,18 +148,17 @@ func (c *Collector) CollectConfigMode() (ConfigMode, error) {
 		return c.collectConfigModeFallback()
 	}
 	var mode ConfigMode
-	err := huh.NewSelect[ConfigMode]().
-		Title("请选择配置模式").
+	err := c.run(huh.NewSelect[ConfigMode]().
+		Title(i18n.T("input.mode_title")).
 		Options(
-			huh.NewOption("完整配置 - 重新配置所有选项", ConfigModeFull),
-			huh.NewOption("仅配置模型 - 保留现有 URL 和 API Key", ConfigModeModelOnly),
+			huh.NewOption(i18n.T("input.mode_full"), ConfigModeFull),
+			huh.NewOption(i18n.T("input.mode_model_only"), ConfigModeModelOnly),
 		).
-		Value(&mode).
-		Run()
+		Value(&mode))
 	if err != nil {
 		// 问题4修复：huh 失败时（如旧版 Windows）fallback 到简单输入
 		if errors.Is(err, huh.ErrUserAborted) {
-			return 0, fmt.Errorf("用户取消")
+			return 0, ErrUserCancelled
 		}
 		if isTTYError(err) {
 			return c.collectConfigModeFallback()
@@ -116,9 +169,9 @@ func (c *Collector) CollectConfigMode() (ConfigMode, error) {
 }
 
 func (c *Collector) collectConfigModeFallback() (ConfigMode, error) {
-	idx, err := fallbackSelect("请选择配置模式", []string{
-		"完整配置 - 重新配置所有选项",
-		"仅配置模型 - 保留现有 URL 和 API Key",
+	idx, err := c.fallbackSelect(i18n.T("input.mode_title"), []string{
+		i18n.T("input.mode_full"),
+		i18n.T("input.mode_model_only"),
 	})
 	if err != nil {
 		return 0, err
@@ -132,9 +185,9 @@ func (c *Collector) CollectURL() (string, error) {
 		return c.collectURLFallback()
 	}
 	var rawURL string
-	err := huh.NewInput().
-		Title("请输入 DMXAPI URL").
-		Description("留空使用默认值: https://www.dmxapi.cn").
+	err := c.run(huh.NewInput().
+		Title(i18n.T("input.url_title")).
+		Description(i18n.T("input.url_desc")).
 		Placeholder("https://www.dmxapi.cn").
 		Validate(func(s string) error {
 			if s == "" {
@@ -142,11 +195,10 @@ func (c *Collector) CollectURL() (string, error) {
 			}
 			return ValidateURL(s)
 		}).
-		Value(&rawURL).
-		Run()
+		Value(&rawURL))
 	if err != nil {
 		if errors.Is(err, huh.ErrUserAborted) {
-			return "", fmt.Errorf("用户取消")
+			return "", ErrUserCancelled
 		}
 		if isTTYError(err) {
 			return c.collectURLFallback()
@@ -160,7 +212,7 @@ func (c *Collector) CollectURL() (string, error) {
 }
 
 func (c *Collector) collectURLFallback() (string, error) {
-	rawURL, err := fallbackInput("请输入 DMXAPI URL（留空使用默认值 https://www.dmxapi.cn）", "https://www.dmxapi.cn")
+	rawURL, err := c.fallbackInput(i18n.T("input.url_fallback"), "https://www.dmxapi.cn")
 	if err != nil {
 		return "", err
 	}
@@ -170,22 +222,105 @@ func (c *Collector) collectURLFallback() (string, error) {
 	return strings.TrimSuffix(rawURL, "/"), nil
 }
 
+// ConfirmReenterURL 地址检查未通过时询问是否重新输入 URL
+// 非交互模式下不询问（避免读走后续的输入行），直接返回 false
+func (c *Collector) ConfirmReenterURL() (bool, error) {
+	if !isTerminal() {
+		return false, nil
+	}
+	reenter := true
+	err := c.run(huh.NewConfirm().
+		Title(i18n.T("input.url_reenter_title")).
+		Affirmative(i18n.T("input.url_reenter_yes")).
+		Negative(i18n.T("input.url_reenter_no")).
+		Value(&reenter))
+	if err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return false, ErrUserCancelled
+		}
+		if isTTYError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return reenter, nil
+}
+
+// ConfirmMigration 询问是否迁移旧版本的 dmxapi provider
+// 非交互模式下 scripted 为 true 时读取一行 y/N（migrate 命令），否则不询问直接返回 false（避免读走配置向导后续的输入行）
+func (c *Collector) ConfirmMigration(scripted bool) (bool, error) {
+	if !isTerminal() {
+		if !scripted {
+			return false, nil
+		}
+		answer, err := c.fallbackInput(i18n.T("input.migrate_fallback"), "")
+		if err != nil {
+			return false, err
+		}
+		answer = strings.ToLower(answer)
+		return answer == "y" || answer == "yes", nil
+	}
+	migrate := true
+	err := c.run(huh.NewConfirm().
+		Title(i18n.T("input.migrate_title")).
+		Affirmative(i18n.T("input.migrate_yes")).
+		Negative(i18n.T("input.migrate_no")).
+		Value(&migrate))
+	if err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return false, ErrUserCancelled
+		}
+		if isTTYError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return migrate, nil
+}
+
+// CollectConflictResolution 写入前发现冲突时询问处理方式
+// 非交互模式下不询问（避免读走后续的输入行），按计划写入并保留两边的同名模型
+func (c *Collector) CollectConflictResolution() (config.ConflictResolution, error) {
+	if !isTerminal() {
+		return config.ResolveKeepBoth, nil
+	}
+	resolution := config.ResolveKeepBoth
+	err := c.run(huh.NewSelect[config.ConflictResolution]().
+		Title(i18n.T("input.conflict_title")).
+		Options(
+			huh.NewOption(i18n.T("input.conflict_keep"), config.ResolveKeepBoth),
+			huh.NewOption(i18n.T("input.conflict_dmxapi"), config.ResolvePreferDMXAPI),
+			huh.NewOption(i18n.T("input.conflict_existing"), config.ResolvePreferOthers),
+			huh.NewOption(i18n.T("input.conflict_cancel"), config.ResolveCancel),
+		).
+		Value(&resolution))
+	if err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return "", ErrUserCancelled
+		}
+		if isTTYError(err) {
+			return config.ResolveKeepBoth, nil
+		}
+		return "", err
+	}
+	return resolution, nil
+}
+
 // CollectAPIKey 收集API Key输入
 func (c *Collector) CollectAPIKey() (string, error) {
 	if !isTerminal() {
 		return c.collectAPIKeyFallback()
 	}
 	var apiKey string
-	err := huh.NewInput().
-		Title("请输入 API Key").
-		Description("获取地址: https://www.dmxapi.cn/token").
+	err := c.run(huh.NewInput().
+		Title(i18n.T("input.api_key_title")).
+		Description(i18n.T("input.api_key_desc")).
 		EchoMode(huh.EchoModePassword).
 		Validate(ValidateAPIKey).
-		Value(&apiKey).
-		Run()
+		Value(&apiKey))
 	if err != nil {
 		if errors.Is(err, huh.ErrUserAborted) {
-			return "", fmt.Errorf("用户取消")
+			return "", ErrUserCancelled
 		}
 		if isTTYError(err) {
 			return c.collectAPIKeyFallback()
@@ -196,8 +331,8 @@ func (c *Collector) CollectAPIKey() (string, error) {
 }
 
 func (c *Collector) collectAPIKeyFallback() (string, error) {
-	fmt.Println("  注意: 非交互模式，API Key 将以明文显示")
-	apiKey, err := fallbackInput("请输入 API Key", "")
+	fmt.Fprintln(c.out, "  "+i18n.T("input.api_key_plaintext"))
+	apiKey, err := c.fallbackInput(i18n.T("input.api_key_title"), "")
 	if err != nil {
 		return "", err
 	}
@@ -208,23 +343,26 @@ func (c *Collector) collectAPIKeyFallback() (string, error) {
 }
 
 // CollectModels 收集模型名称输入
+// 设置了模型目录时先在目录中选择（支持搜索），再手动补充目录中没有的模型
 func (c *Collector) CollectModels() ([]string, error) {
 	if !isTerminal() {
 		return c.collectModelsFallback()
 	}
+	if c.browse != nil {
+		return c.collectModelsBrowse()
+	}
 	var line string
-	err := huh.NewInput().
-		Title("请输入模型名称，多个用逗号分隔").
-		Description("可用模型: https://www.dmxapi.cn/rmb").
+	err := c.run(huh.NewInput().
+		Title(i18n.T("input.models_title")).
+		Description(i18n.T("input.models_desc")).
 		Placeholder("claude-opus-4-5-20251101,DeepSeek-V3.2-Fast").
 		Validate(func(s string) error {
-			return ValidateModels(parseModels(s))
+			return c.validateModels(parseModels(s))
 		}).
-		Value(&line).
-		Run()
+		Value(&line))
 	if err != nil {
 		if errors.Is(err, huh.ErrUserAborted) {
-			return nil, fmt.Errorf("用户取消")
+			return nil, ErrUserCancelled
 		}
 		if isTTYError(err) {
 			return c.collectModelsFallback()
@@ -234,16 +372,72 @@ func (c *Collector) CollectModels() ([]string, error) {
 	return parseModels(line), nil
 }
 
-func (c *Collector) collectModelsFallback() ([]string, error) {
-	line, err := fallbackInput("请输入模型名称（多个用逗号分隔，如 claude-opus-4-5-20251101,DeepSeek-V3.2-Fast）", "")
+// collectModelsBrowse 在模型目录中多选模型（输入 / 搜索），然后手动输入其他模型
+func (c *Collector) collectModelsBrowse() ([]string, error) {
+	options := make([]huh.Option[string], len(c.browse.Models))
+	for i, m := range c.browse.Models {
+		options[i] = huh.NewOption(describeModel(m), m.ID)
+	}
+	var selected []string
+	err := c.run(huh.NewMultiSelect[string]().
+		Title(i18n.T("input.models_browse_title")).
+		Description(i18n.T("input.models_browse_desc")).
+		Options(options...).
+		Filterable(true).
+		Height(16).
+		Value(&selected))
 	if err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return nil, ErrUserCancelled
+		}
+		if isTTYError(err) {
+			return c.collectModelsFallback()
+		}
 		return nil, err
 	}
-	models := parseModels(line)
-	if err := ValidateModels(models); err != nil {
+
+	title, desc := i18n.T("input.models_title"), i18n.T("input.models_desc")
+	if len(selected) > 0 {
+		title, desc = i18n.T("input.models_extra_title"), i18n.T("input.models_extra_desc", len(selected))
+	}
+	var line string
+	err = c.run(huh.NewInput().
+		Title(title).
+		Description(desc).
+		Validate(func(s string) error {
+			return c.validateModels(mergeModels(selected, parseModels(s)))
+		}).
+		Value(&line))
+	if err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return nil, ErrUserCancelled
+		}
 		return nil, err
 	}
-	return models, nil
+	return mergeModels(selected, parseModels(line)), nil
+}
+
+// collectModelsFallback 非 TTY 环境下输入模型名称，设置了模型目录时输入 ?关键词 可以搜索
+func (c *Collector) collectModelsFallback() ([]string, error) {
+	prompt := i18n.T("input.models_fallback")
+	if c.browse != nil {
+		prompt = i18n.T("input.models_fallback_search")
+	}
+	for {
+		line, err := c.fallbackInput(prompt, "")
+		if err != nil {
+			return nil, err
+		}
+		if query, ok := strings.CutPrefix(line, "?"); ok && c.browse != nil {
+			c.printCatalogMatches(query)
+			continue
+		}
+		models := parseModels(line)
+		if err := c.validateModels(models); err != nil {
+			return nil, err
+		}
+		return models, nil
+	}
 }
 
 // parseModels 解析逗号分隔的模型名称
@@ -256,4 +450,3 @@ func parseModels(s string) []string {
 	}
 	return models
 }
-
@@ -1,28 +1,34 @@
 package input
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"dmxapi-config/internal/i18n"
 )
 
 // ValidateURL 验证URL格式
 func ValidateURL(input string) error {
 	if input == "" {
-		return fmt.Errorf("URL不能为空")
+		return errors.New(i18n.T("validate.url_empty"))
 	}
 
 	parsed, err := url.ParseRequestURI(input)
 	if err != nil {
-		return fmt.Errorf("URL格式无效: %w", err)
+		return fmt.Errorf(i18n.T("validate.url_invalid"), err)
 	}
 
 	if parsed.Scheme != "http" && parsed.Scheme != "https" {
-		return fmt.Errorf("URL必须以 http:// 或 https:// 开头")
+		return errors.New(i18n.T("validate.url_scheme"))
 	}
 
 	if parsed.Host == "" {
-		return fmt.Errorf("URL必须包含有效的主机名")
+		return errors.New(i18n.T("validate.url_host"))
 	}
 
 	return nil
@@ -31,17 +37,17 @@ func ValidateURL(input string) error {
 // ValidateAPIKey 验证API Key格式
 func ValidateAPIKey(key string) error {
 	if key == "" {
-		return fmt.Errorf("API Key不能为空")
+		return errors.New(i18n.T("validate.api_key_empty"))
 	}
 
 	// API Key 通常以 sk- 开头，但不强制要求
 	if len(key) < 8 {
-		return fmt.Errorf("API Key 长度过短")
+		return errors.New(i18n.T("validate.api_key_short"))
 	}
 
 	// 检查是否包含空格或特殊字符
 	if strings.ContainsAny(key, " \t\n\r") {
-		return fmt.Errorf("API Key 不能包含空格或换行符")
+		return errors.New(i18n.T("validate.api_key_space"))
 	}
 
 	return nil
@@ -50,15 +56,129 @@ func ValidateAPIKey(key string) error {
 // ValidateModels 验证模型列表
 func ValidateModels(models []string) error {
 	if len(models) == 0 {
-		return fmt.Errorf("至少需要指定一个模型")
+		return errors.New(i18n.T("validate.models_empty"))
 	}
 
 	for _, model := range models {
 		if strings.TrimSpace(model) == "" {
-			return fmt.Errorf("模型名称不能为空")
+			return errors.New(i18n.T("validate.model_blank"))
 		}
 	}
 
 	return nil
 }
 
+// maxSuggestions "您是否想输入" 中最多给出的候选模型数
+const maxSuggestions = 3
+
+// ValidateModelsInCatalog 验证模型列表，并要求每个模型都在 catalog 中（区分大小写）
+// 不在 catalog 中的模型给出相近名称作为建议
+func ValidateModelsInCatalog(models, catalog []string) error {
+	if err := ValidateModels(models); err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(catalog))
+	for _, m := range catalog {
+		known[m] = true
+	}
+	var problems []string
+	for _, model := range models {
+		if known[model] {
+			continue
+		}
+		if suggestions := SuggestModels(model, catalog); len(suggestions) > 0 {
+			problems = append(problems, i18n.T("validate.model_unknown_suggest", model, strings.Join(suggestions, ", ")))
+		} else {
+			problems = append(problems, i18n.T("validate.model_unknown", model))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, i18n.T("validate.separator")) + i18n.T("validate.model_force_hint"))
+	}
+	return nil
+}
+
+// SuggestModels 返回 catalog 中与 name 最相近的模型（按相似度排序，最多 maxSuggestions 个）
+// 比较时忽略大小写和 - _ . 空格等分隔符，如 gemini-2.5pro 与 gemini-2.5-pro 视为相同
+func SuggestModels(name string, catalog []string) []string {
+	target := normalizeModelName(name)
+	n := utf8.RuneCountInString(target)
+	threshold := suggestThreshold(n)
+
+	type candidate struct {
+		model    string
+		distance int
+	}
+	var candidates []candidate
+	for _, m := range catalog {
+		normalized := normalizeModelName(m)
+		d := levenshtein(target, normalized)
+		if n >= 2 && strings.HasPrefix(normalized, target) {
+			// 省略了日期等后缀，如 claude-sonnet-4 与 claude-sonnet-4-20250514、o3 与 o3-mini
+			d = min(d, threshold)
+		}
+		if d <= threshold {
+			candidates = append(candidates, candidate{m, d})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].model < candidates[j].model
+	})
+
+	var out []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		out = append(out, candidates[i].model)
+	}
+	return out
+}
+
+// suggestThreshold 返回长度为 n 的模型名允许的编辑距离
+// 很短的名称改动一两个字符就会变成另一个模型（如 o1 与 o3），只接受大小写和分隔符的差异或前缀匹配
+func suggestThreshold(n int) int {
+	switch {
+	case n <= 3:
+		return 0
+	case n <= 5:
+		return 1
+	case n < 12:
+		return 2
+	}
+	return n / 4
+}
+
+// normalizeModelName 转为小写并去掉分隔符，用于模糊比较
+func normalizeModelName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', '.', ' ', '/':
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, s)
+}
+
+// levenshtein 计算两个字符串的编辑距离
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
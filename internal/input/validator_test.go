@@ -0,0 +1,87 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testCatalog = []string{
+	"claude-sonnet-4-5",
+	"claude-sonnet-4-20250514",
+	"claude-opus-4-5-20251101",
+	"gemini-2.5-pro",
+	"gemini-2.5-flash",
+	"gpt-4o",
+	"gpt-4o-mini",
+	"gpt-5",
+	"o1",
+	"o3",
+	"o3-mini",
+	"DeepSeek-V3",
+	"qwen-max",
+}
+
+func TestSuggestModels(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		// 拼写错误
+		{"claude-sonet-4-5", []string{"claude-sonnet-4-5"}},
+		{"gemini-2.5-prp", []string{"gemini-2.5-pro"}},
+		{"gemnii-2.5-flash", []string{"gemini-2.5-flash"}},
+		{"qwen-mix", []string{"qwen-max"}},
+		// 大小写和分隔符
+		{"GPT-4O", []string{"gpt-4o", "gpt-4o-mini"}},
+		{"gpt5", []string{"gpt-5"}},
+		{"deepseek-v3", []string{"DeepSeek-V3"}},
+		{"gemini-2.5pro", []string{"gemini-2.5-pro"}},
+		// 省略后缀
+		{"claude-sonnet-4", []string{"claude-sonnet-4-5", "claude-sonnet-4-20250514"}},
+		{"claude-opus-4-5", []string{"claude-opus-4-5-20251101"}},
+		{"o3", []string{"o3", "o3-mini"}},
+		{"gpt-4", []string{"gpt-4o", "gpt-4o-mini", "gpt-5"}},
+		// 很短的名称不做模糊匹配
+		{"x", nil},
+		{"o", nil},
+		{"o2", nil},
+		{"o4", nil},
+		{"gtp", nil},
+		// 相差太大
+		{"llama-3-70b", nil},
+		{"claude-haiku-4-5", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := SuggestModels(tt.name, testCatalog); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SuggestModels(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestModelsLimit(t *testing.T) {
+	catalog := []string{"gpt-4o-a", "gpt-4o-b", "gpt-4o-c", "gpt-4o-d", "gpt-4o-e"}
+	if got := SuggestModels("gpt-4o", catalog); len(got) != maxSuggestions {
+		t.Errorf("SuggestModels returned %d suggestions, want %d", len(got), maxSuggestions)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"gpt5", "gpt5", 0},
+		{"sonet", "sonnet", 1},
+		{"模型", "模形", 1},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// mockReply 模拟网关固定返回的回复内容
+const mockReply = "Hello from the mock DMXAPI gateway."
+
+// mockThinking 推理模型返回的思考内容
+const mockThinking = "The user sent a short message, so a brief greeting is enough."
+
+// mockReasoningTokens 推理模型报告的推理 token 数
+const mockReasoningTokens = 12
+
+// dialect 接口格式，决定错误响应和流式事件的结构
+type dialect int
+
+const (
+	dialectOpenAI dialect = iota
+	dialectAnthropic
+	dialectGoogle
+)
+
+// dialectOf 根据请求路径判断接口格式
+func dialectOf(path string) dialect {
+	switch {
+	case strings.HasPrefix(path, "/v1/messages"):
+		return dialectAnthropic
+	case strings.HasPrefix(path, "/v1beta/"):
+		return dialectGoogle
+	}
+	return dialectOpenAI
+}
+
+// writeError 按接口格式输出错误响应
+func writeError(w http.ResponseWriter, d dialect, status int, message string) {
+	switch d {
+	case dialectAnthropic:
+		writeJSON(w, status, map[string]interface{}{
+			"type":  "error",
+			"error": map[string]string{"type": anthropicErrorType(status), "message": message},
+		})
+	case dialectGoogle:
+		writeJSON(w, status, map[string]interface{}{
+			"error": map[string]interface{}{"code": status, "message": message, "status": googleErrorStatus(status)},
+		})
+	default:
+		writeJSON(w, status, map[string]interface{}{
+			"error": map[string]string{"message": message, "type": openAIErrorType(status), "code": openAIErrorType(status)},
+		})
+	}
+}
+
+// anthropicErrorType Anthropic 错误类型
+func anthropicErrorType(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return "authentication_error"
+	case http.StatusNotFound:
+		return "not_found_error"
+	case http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case http.StatusBadRequest, http.StatusPaymentRequired:
+		return "invalid_request_error"
+	}
+	return "api_error"
+}
+
+// googleErrorStatus Google API 错误状态
+func googleErrorStatus(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return "UNAUTHENTICATED"
+	case http.StatusNotFound:
+		return "NOT_FOUND"
+	case http.StatusTooManyRequests:
+		return "RESOURCE_EXHAUSTED"
+	case http.StatusBadRequest, http.StatusPaymentRequired:
+		return "INVALID_ARGUMENT"
+	}
+	return "UNAVAILABLE"
+}
+
+// openAIErrorType OpenAI 错误类型
+func openAIErrorType(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return "invalid_api_key"
+	case http.StatusNotFound:
+		return "model_not_found"
+	case http.StatusTooManyRequests:
+		return "rate_limit_exceeded"
+	case http.StatusPaymentRequired:
+		return "insufficient_quota"
+	case http.StatusBadRequest:
+		return "invalid_request_error"
+	}
+	return "server_error"
+}
+
+// handleAnthropic POST /v1/messages
+func (s *Server) handleAnthropic(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Model    string `json:"model"`
+		Stream   bool   `json:"stream"`
+		Thinking *struct {
+			Type string `json:"type"`
+		} `json:"thinking"`
+	}
+	body, ok := decodeRequest(w, r, dialectAnthropic, &req)
+	thinking := req.Thinking != nil && req.Thinking.Type == "enabled"
+	if !ok || !s.checkModel(w, dialectAnthropic, req.Model) || !s.checkImageInput(w, dialectAnthropic, req.Model, body) ||
+		!s.checkThinking(w, dialectAnthropic, req.Model, thinking) {
+		return
+	}
+	id := newID("msg")
+	usage := map[string]int{"input_tokens": 8, "output_tokens": 9}
+
+	if !req.Stream {
+		content := []map[string]string{{"type": "text", "text": mockReply}}
+		if thinking {
+			content = append([]map[string]string{{"type": "thinking", "thinking": mockThinking, "signature": "mock-signature"}}, content...)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":          id,
+			"type":        "message",
+			"role":        "assistant",
+			"model":       req.Model,
+			"content":     content,
+			"stop_reason": "end_turn",
+			"usage":       usage,
+		})
+		return
+	}
+
+	sse := newSSEWriter(w)
+	sse.event("message_start", map[string]interface{}{
+		"type": "message_start",
+		"message": map[string]interface{}{
+			"id": id, "type": "message", "role": "assistant", "model": req.Model,
+			"content": []interface{}{}, "usage": map[string]int{"input_tokens": 8, "output_tokens": 0},
+		},
+	})
+	index := 0
+	if thinking {
+		sse.event("content_block_start", map[string]interface{}{
+			"type": "content_block_start", "index": index,
+			"content_block": map[string]string{"type": "thinking", "thinking": ""},
+		})
+		sse.event("content_block_delta", map[string]interface{}{
+			"type": "content_block_delta", "index": index,
+			"delta": map[string]string{"type": "thinking_delta", "thinking": mockThinking},
+		})
+		sse.event("content_block_delta", map[string]interface{}{
+			"type": "content_block_delta", "index": index,
+			"delta": map[string]string{"type": "signature_delta", "signature": "mock-signature"},
+		})
+		sse.event("content_block_stop", map[string]interface{}{"type": "content_block_stop", "index": index})
+		index++
+	}
+	sse.event("content_block_start", map[string]interface{}{
+		"type": "content_block_start", "index": index,
+		"content_block": map[string]string{"type": "text", "text": ""},
+	})
+	for _, chunk := range replyChunks() {
+		sse.event("content_block_delta", map[string]interface{}{
+			"type": "content_block_delta", "index": index,
+			"delta": map[string]string{"type": "text_delta", "text": chunk},
+		})
+	}
+	sse.event("content_block_stop", map[string]interface{}{"type": "content_block_stop", "index": index})
+	sse.event("message_delta", map[string]interface{}{
+		"type":  "message_delta",
+		"delta": map[string]string{"stop_reason": "end_turn"},
+		"usage": map[string]int{"output_tokens": 9},
+	})
+	sse.event("message_stop", map[string]string{"type": "message_stop"})
+}
+
+// handleGoogle POST /v1beta/models/{model}:generateContent 和 :streamGenerateContent
+func (s *Server) handleGoogle(w http.ResponseWriter, r *http.Request) {
+	model, method, ok := strings.Cut(r.PathValue("action"), ":")
+	if !ok || (method != "generateContent" && method != "streamGenerateContent") {
+		writeError(w, dialectGoogle, http.StatusNotFound, "unknown method: "+r.PathValue("action"))
+		return
+	}
+	var req struct {
+		Contents         []json.RawMessage `json:"contents"`
+		GenerationConfig struct {
+			ThinkingConfig *struct {
+				IncludeThoughts bool `json:"includeThoughts"`
+			} `json:"thinkingConfig"`
+		} `json:"generationConfig"`
+	}
+	body, ok := decodeRequest(w, r, dialectGoogle, &req)
+	thinkingConfig := req.GenerationConfig.ThinkingConfig
+	if !ok || !s.checkModel(w, dialectGoogle, model) || !s.checkImageInput(w, dialectGoogle, model, body) ||
+		!s.checkThinking(w, dialectGoogle, model, thinkingConfig != nil) {
+		return
+	}
+	// 与 Gemini 2.5 一致：推理模型总是消耗推理 token，includeThoughts 时才返回思考摘要
+	includeThoughts := thinkingConfig != nil && thinkingConfig.IncludeThoughts
+	usage := map[string]int{"promptTokenCount": 8, "candidatesTokenCount": 9, "totalTokenCount": 17}
+	if s.thinking[model] {
+		usage["thoughtsTokenCount"] = mockReasoningTokens
+		usage["totalTokenCount"] += mockReasoningTokens
+	}
+
+	candidate := func(parts ...map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"candidates": []map[string]interface{}{{
+				"content": map[string]interface{}{
+					"role":  "model",
+					"parts": parts,
+				},
+				"finishReason": "STOP",
+				"index":        0,
+			}},
+			"usageMetadata": usage,
+			"modelVersion":  model,
+		}
+	}
+	text := func(text string) map[string]interface{} { return map[string]interface{}{"text": text} }
+	thought := map[string]interface{}{"text": mockThinking, "thought": true}
+
+	if method == "generateContent" {
+		if includeThoughts {
+			writeJSON(w, http.StatusOK, candidate(thought, text(mockReply)))
+		} else {
+			writeJSON(w, http.StatusOK, candidate(text(mockReply)))
+		}
+		return
+	}
+	var chunks []map[string]interface{}
+	if includeThoughts {
+		chunks = append(chunks, candidate(thought))
+	}
+	for _, chunk := range replyChunks() {
+		chunks = append(chunks, candidate(text(chunk)))
+	}
+	// 与 Gemini API 一致：?alt=sse 时为 SSE，否则为 JSON 数组
+	if r.URL.Query().Get("alt") != "sse" {
+		writeJSON(w, http.StatusOK, chunks)
+		return
+	}
+	sse := newSSEWriter(w)
+	for _, chunk := range chunks {
+		sse.data(chunk)
+	}
+}
+
+// handleResponses POST /v1/responses
+func (s *Server) handleResponses(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Model     string `json:"model"`
+		Stream    bool   `json:"stream"`
+		Reasoning *struct {
+			Summary string `json:"summary"`
+		} `json:"reasoning"`
+	}
+	body, ok := decodeRequest(w, r, dialectOpenAI, &req)
+	if !ok || !s.checkModel(w, dialectOpenAI, req.Model) || !s.checkImageInput(w, dialectOpenAI, req.Model, body) ||
+		!s.checkThinking(w, dialectOpenAI, req.Model, req.Reasoning != nil) {
+		return
+	}
+	id := newID("resp")
+	msgID := newID("msg")
+	message := map[string]interface{}{
+		"type":    "message",
+		"id":      msgID,
+		"status":  "completed",
+		"role":    "assistant",
+		"content": []map[string]interface{}{{"type": "output_text", "text": mockReply, "annotations": []interface{}{}}},
+	}
+	output := []map[string]interface{}{message}
+	usage := map[string]interface{}{"input_tokens": 8, "output_tokens": 9, "total_tokens": 17}
+	// 与 OpenAI 一致：推理模型总是报告推理 token，请求 reasoning.summary 时才返回思考摘要
+	if s.thinking[req.Model] {
+		usage["output_tokens"] = 9 + mockReasoningTokens
+		usage["total_tokens"] = 17 + mockReasoningTokens
+		usage["output_tokens_details"] = map[string]int{"reasoning_tokens": mockReasoningTokens}
+		if req.Reasoning != nil && req.Reasoning.Summary != "" {
+			output = append([]map[string]interface{}{{
+				"type":    "reasoning",
+				"id":      newID("rs"),
+				"summary": []map[string]string{{"type": "summary_text", "text": mockThinking}},
+			}}, output...)
+		}
+	}
+	response := func(status string, output interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"id":         id,
+			"object":     "response",
+			"created_at": time.Now().Unix(),
+			"status":     status,
+			"model":      req.Model,
+			"output":     output,
+			"usage":      usage,
+		}
+	}
+
+	if !req.Stream {
+		writeJSON(w, http.StatusOK, response("completed", output))
+		return
+	}
+
+	sse := newSSEWriter(w)
+	sse.event("response.created", map[string]interface{}{"type": "response.created", "response": response("in_progress", []interface{}{})})
+	// 思考摘要作为完整的输出项发送，不逐字输出
+	index := len(output) - 1
+	for i, item := range output[:index] {
+		sse.event("response.output_item.added", map[string]interface{}{"type": "response.output_item.added", "output_index": i, "item": item})
+		sse.event("response.output_item.done", map[string]interface{}{"type": "response.output_item.done", "output_index": i, "item": item})
+	}
+	sse.event("response.output_item.added", map[string]interface{}{
+		"type": "response.output_item.added", "output_index": index,
+		"item": map[string]interface{}{"type": "message", "id": msgID, "status": "in_progress", "role": "assistant", "content": []interface{}{}},
+	})
+	for _, chunk := range replyChunks() {
+		sse.event("response.output_text.delta", map[string]interface{}{
+			"type": "response.output_text.delta", "item_id": msgID, "output_index": index, "content_index": 0, "delta": chunk,
+		})
+	}
+	sse.event("response.output_text.done", map[string]interface{}{
+		"type": "response.output_text.done", "item_id": msgID, "output_index": index, "content_index": 0, "text": mockReply,
+	})
+	sse.event("response.output_item.done", map[string]interface{}{"type": "response.output_item.done", "output_index": index, "item": message})
+	sse.event("response.completed", map[string]interface{}{"type": "response.completed", "response": response("completed", output)})
+}
+
+// handleChat POST /v1/chat/completions
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Model  string `json:"model"`
+		Stream bool   `json:"stream"`
+	}
+	body, ok := decodeRequest(w, r, dialectOpenAI, &req)
+	if !ok || !s.checkModel(w, dialectOpenAI, req.Model) || !s.checkImageInput(w, dialectOpenAI, req.Model, body) {
+		return
+	}
+	id := newID("chatcmpl")
+	created := time.Now().Unix()
+	// 与 DeepSeek-R1 等推理模型一致：总是在 reasoning_content 中返回思考内容
+	thinking := s.thinking[req.Model]
+
+	if !req.Stream {
+		message := map[string]string{"role": "assistant", "content": mockReply}
+		usage := map[string]interface{}{"prompt_tokens": 8, "completion_tokens": 9, "total_tokens": 17}
+		if thinking {
+			message["reasoning_content"] = mockThinking
+			usage["completion_tokens"] = 9 + mockReasoningTokens
+			usage["total_tokens"] = 17 + mockReasoningTokens
+			usage["completion_tokens_details"] = map[string]int{"reasoning_tokens": mockReasoningTokens}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":      id,
+			"object":  "chat.completion",
+			"created": created,
+			"model":   req.Model,
+			"choices": []map[string]interface{}{{
+				"index":         0,
+				"message":       message,
+				"finish_reason": "stop",
+			}},
+			"usage": usage,
+		})
+		return
+	}
+
+	chunk := func(delta map[string]string, finish interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   req.Model,
+			"choices": []map[string]interface{}{{"index": 0, "delta": delta, "finish_reason": finish}},
+		}
+	}
+	sse := newSSEWriter(w)
+	sse.data(chunk(map[string]string{"role": "assistant", "content": ""}, nil))
+	if thinking {
+		sse.data(chunk(map[string]string{"reasoning_content": mockThinking}, nil))
+	}
+	for _, c := range replyChunks() {
+		sse.data(chunk(map[string]string{"content": c}, nil))
+	}
+	sse.data(chunk(map[string]string{}, "stop"))
+	sse.done()
+}
+
+// replyChunks 将回复拆分为多个流式片段（按单词）
+func replyChunks() []string {
+	return strings.SplitAfter(mockReply, " ")
+}
+
+// newID 生成带前缀的响应 ID
+func newID(prefix string) string {
+	return fmt.Sprintf("%s_mock%d", prefix, time.Now().UnixNano())
+}
+
+// sseWriter 输出 Server-Sent Events，每个事件后立即刷新
+type sseWriter struct {
+	w http.ResponseWriter
+}
+
+// newSSEWriter 写入 SSE 响应头
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	return &sseWriter{w: w}
+}
+
+// event 输出带事件名的 SSE 事件（Anthropic、Responses 格式）
+func (s *sseWriter) event(name string, v interface{}) {
+	fmt.Fprintf(s.w, "event: %s\n", name)
+	s.data(v)
+}
+
+// data 输出只有数据的 SSE 事件（Chat Completions、Gemini 格式）
+func (s *sseWriter) data(v interface{}) {
+	b, _ := json.Marshal(v)
+	fmt.Fprintf(s.w, "data: %s\n\n", b)
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// done 输出 Chat Completions 流的结束标记
+func (s *sseWriter) done() {
+	fmt.Fprint(s.w, "data: [DONE]\n\n")
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
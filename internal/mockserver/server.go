@@ -0,0 +1,360 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"dmxapi-config/internal/config"
+)
+
+// Fault 注入的故障类型
+type Fault string
+
+const (
+	FaultNone         Fault = ""          // 正常响应
+	FaultUnauthorized Fault = "401"       // API Key 无效
+	FaultRateLimited  Fault = "429"       // 限流，附带 Retry-After 响应头
+	FaultServerError  Fault = "5xx"       // 网关错误（503）
+	FaultSlow         Fault = "slow"      // 延迟 Delay 后再正常响应
+	FaultMalformed    Fault = "malformed" // 返回无法解析的 JSON
+	FaultBalance      Fault = "balance"   // 余额不足（402）
+	FaultHTML         Fault = "html"      // 返回网页（URL 填写成了控制台地址）
+	FaultNotFound     Fault = "model"     // 模型不存在（404）
+)
+
+// defaultSlowDelay FaultSlow 的默认延迟时间
+const defaultSlowDelay = 5 * time.Second
+
+// faults 命令行和模板中可用的故障名称
+var faults = []Fault{FaultUnauthorized, FaultRateLimited, FaultServerError, FaultSlow, FaultMalformed, FaultBalance, FaultHTML, FaultNotFound}
+
+// ParseFault 解析故障名称，空字符串表示不注入故障
+func ParseFault(name string) (Fault, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "none" {
+		return FaultNone, true
+	}
+	for _, f := range faults {
+		if string(f) == name {
+			return f, true
+		}
+	}
+	return FaultNone, false
+}
+
+// FaultNames 返回所有故障名称，用于帮助信息
+func FaultNames() []string {
+	names := make([]string, 0, len(faults))
+	for _, f := range faults {
+		names = append(names, string(f))
+	}
+	return names
+}
+
+// Options 模拟网关的配置
+type Options struct {
+	APIKey     string        // 非空时校验请求携带的 API Key
+	Models     []string      // /v1/models 返回的模型，同时用于判断模型是否存在；为空时使用内置模型列表
+	Fault      Fault         // 注入的故障
+	FaultCount int           // 只对前 N 个请求注入故障，0 表示每个请求都注入
+	Delay      time.Duration // FaultSlow 的延迟时间，0 时为 5 秒
+	StrictAuth bool          // 只接受各接口原生的认证方式（Anthropic 为 x-api-key + anthropic-version，Google 为 x-goog-api-key）
+	TextOnly   []string      // 不支持图片输入的模型，请求中带图片时返回 400
+	Reasoning  []string      // 推理模型：返回思考内容和推理 token；其他模型收到思考参数时返回 400
+	QuotaUSD   float64       // 令牌总额度（美元），0 表示无限额度
+	UsedUSD    float64       // 已使用额度（美元）
+	ExpiresAt  time.Time     // 令牌过期时间，零值表示永不过期
+
+	RequireHeaders map[string]string // 要求每个请求携带的自定义请求头，缺少或取值不同时返回 400
+}
+
+// Server 模拟 DMXAPI 网关，实现 Anthropic、Google、OpenAI Chat Completions / Responses 四种接口
+// 用于离线开发和测试，可以直接作为 http.Handler 交给 httptest.NewServer
+type Server struct {
+	mu       sync.Mutex
+	opts     Options
+	models   map[string]bool
+	textOnly map[string]bool
+	thinking map[string]bool
+	requests int // 已处理的请求数（含注入故障的请求）
+	injected int // 已注入故障的请求数
+	mux      *http.ServeMux
+}
+
+// New 创建模拟网关
+func New(opts Options) *Server {
+	if len(opts.Models) == 0 {
+		opts.Models = config.BuiltinModels()
+	}
+	if opts.Delay <= 0 {
+		opts.Delay = defaultSlowDelay
+	}
+	s := &Server{opts: opts, models: make(map[string]bool, len(opts.Models)), textOnly: make(map[string]bool, len(opts.TextOnly)), thinking: make(map[string]bool, len(opts.Reasoning))}
+	for _, m := range opts.Models {
+		s.models[m] = true
+	}
+	for _, m := range opts.TextOnly {
+		s.textOnly[m] = true
+	}
+	for _, m := range opts.Reasoning {
+		s.thinking[m] = true
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("GET /v1/models", s.handleModels)
+	s.mux.HandleFunc("POST /v1/messages", s.handleAnthropic)
+	s.mux.HandleFunc("POST /v1beta/models/{action}", s.handleGoogle)
+	s.mux.HandleFunc("POST /v1/responses", s.handleResponses)
+	s.mux.HandleFunc("POST /v1/chat/completions", s.handleChat)
+	s.mux.HandleFunc("GET /v1/dashboard/billing/subscription", s.handleSubscription)
+	s.mux.HandleFunc("GET /v1/dashboard/billing/usage", s.handleUsage)
+	return s
+}
+
+// SetFault 更换注入的故障，并重新计算 count（只对之后的前 count 个请求注入，0 表示全部）
+func (s *Server) SetFault(fault Fault, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opts.Fault = fault
+	s.opts.FaultCount = count
+	s.injected = 0
+}
+
+// Requests 返回已处理的请求数
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// Models 返回模拟网关提供的模型列表
+func (s *Server) Models() []string {
+	return append([]string(nil), s.opts.Models...)
+}
+
+// ServeHTTP 实现 http.Handler 接口：先按配置注入故障，再校验 API Key 和自定义请求头，最后分发到各接口
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d := dialectOf(r.URL.Path)
+	fault := s.nextFault()
+
+	switch fault {
+	case FaultSlow:
+		// 先读完请求体：net/http 只有在请求体读完后才会检测客户端断开，
+		// 否则客户端取消请求后这里仍会等满 Delay
+		body, _ := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		select {
+		case <-time.After(s.opts.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	case FaultUnauthorized:
+		writeError(w, d, http.StatusUnauthorized, "invalid api key")
+		return
+	case FaultRateLimited:
+		w.Header().Set("Retry-After", "1")
+		writeError(w, d, http.StatusTooManyRequests, "rate limit exceeded, please retry later")
+		return
+	case FaultServerError:
+		writeError(w, d, http.StatusServiceUnavailable, "upstream service unavailable")
+		return
+	case FaultBalance:
+		writeError(w, d, http.StatusPaymentRequired, "insufficient_user_quota: 额度不足")
+		return
+	case FaultHTML:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, "<!DOCTYPE html><html><head><title>DMXAPI</title></head><body></body></html>")
+		return
+	case FaultNotFound:
+		writeError(w, d, http.StatusNotFound, "model_not_found: no available channel for this model")
+		return
+	case FaultMalformed:
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id": "mock", "choices": [`)
+		return
+	}
+
+	if s.opts.APIKey != "" && apiKeyOf(r, d, s.opts.StrictAuth) != s.opts.APIKey {
+		writeError(w, d, http.StatusUnauthorized, "invalid api key")
+		return
+	}
+	for name, value := range s.opts.RequireHeaders {
+		if r.Header.Get(name) != value {
+			writeError(w, d, http.StatusBadRequest, "missing or invalid header "+name)
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// nextFault 返回当前请求应注入的故障，并更新计数
+func (s *Server) nextFault() Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if s.opts.Fault == FaultNone {
+		return FaultNone
+	}
+	if s.opts.FaultCount > 0 && s.injected >= s.opts.FaultCount {
+		return FaultNone
+	}
+	s.injected++
+	return s.opts.Fault
+}
+
+// checkModel 检查模型是否存在，不存在时写入 404 错误并返回 false
+func (s *Server) checkModel(w http.ResponseWriter, d dialect, model string) bool {
+	if s.models[model] {
+		return true
+	}
+	writeError(w, d, http.StatusNotFound, fmt.Sprintf("model_not_found: model %s does not exist", model))
+	return false
+}
+
+// checkImageInput 检查不支持图片的模型是否收到了图片，收到时写入 400 错误并返回 false
+func (s *Server) checkImageInput(w http.ResponseWriter, d dialect, model string, body []byte) bool {
+	if !s.textOnly[model] {
+		return true
+	}
+	var v interface{}
+	if json.Unmarshal(body, &v) != nil || !containsImage(v) {
+		return true
+	}
+	writeError(w, d, http.StatusBadRequest, fmt.Sprintf("image input is not supported by model %s", model))
+	return false
+}
+
+// checkThinking 检查请求的思考参数：非推理模型收到思考参数时写入 400 错误并返回 false
+func (s *Server) checkThinking(w http.ResponseWriter, d dialect, model string, requested bool) bool {
+	if !requested || s.thinking[model] {
+		return true
+	}
+	writeError(w, d, http.StatusBadRequest, fmt.Sprintf("thinking is not supported by model %s", model))
+	return false
+}
+
+// containsImage 检查请求中是否包含图片内容（四种接口格式）
+func containsImage(v interface{}) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		switch v["type"] {
+		case "image", "image_url", "input_image":
+			return true
+		}
+		if _, ok := v["inlineData"]; ok {
+			return true
+		}
+		for _, child := range v {
+			if containsImage(child) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if containsImage(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// handleModels GET /v1/models（OpenAI 兼容格式）
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	type model struct {
+		ID      string `json:"id"`
+		Object  string `json:"object"`
+		OwnedBy string `json:"owned_by"`
+	}
+	data := make([]model, 0, len(s.opts.Models))
+	for _, m := range s.opts.Models {
+		data = append(data, model{ID: m, Object: "model", OwnedBy: "dmxapi"})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"object": "list", "data": data})
+}
+
+// unlimitedQuotaUSD 无限额度令牌返回的额度值（one-api / new-api 约定）
+const unlimitedQuotaUSD = 100000000
+
+// handleSubscription GET /v1/dashboard/billing/subscription（令牌总额度和过期时间）
+func (s *Server) handleSubscription(w http.ResponseWriter, r *http.Request) {
+	quota := s.opts.QuotaUSD
+	if quota <= 0 {
+		quota = unlimitedQuotaUSD
+	}
+	var accessUntil int64
+	if !s.opts.ExpiresAt.IsZero() {
+		accessUntil = s.opts.ExpiresAt.Unix()
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"object":                "billing_subscription",
+		"has_payment_method":    true,
+		"soft_limit_usd":        quota,
+		"hard_limit_usd":        quota,
+		"system_hard_limit_usd": quota,
+		"access_until":          accessUntil,
+	})
+}
+
+// handleUsage GET /v1/dashboard/billing/usage（已使用额度，单位为美分）
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"object":      "list",
+		"total_usage": s.opts.UsedUSD * 100,
+	})
+}
+
+// apiKeyOf 读取请求携带的 API Key，兼容 Bearer、x-api-key、x-goog-api-key 和 ?key= 四种方式
+// strict 为 true 时只读取接口原生的认证方式（/v1/models 仍为 Bearer）
+func apiKeyOf(r *http.Request, d dialect, strict bool) string {
+	if strict {
+		switch {
+		case d == dialectAnthropic:
+			if r.Header.Get("anthropic-version") == "" {
+				return ""
+			}
+			return r.Header.Get("x-api-key")
+		case d == dialectGoogle:
+			if key := r.Header.Get("x-goog-api-key"); key != "" {
+				return key
+			}
+			return r.URL.Query().Get("key")
+		}
+		return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	for _, h := range []string{"x-api-key", "x-goog-api-key"} {
+		if v := r.Header.Get(h); v != "" {
+			return v
+		}
+	}
+	return r.URL.Query().Get("key")
+}
+
+// decodeRequest 解析 JSON 请求体并返回原始内容，失败时写入 400 错误并返回 false
+func decodeRequest(w http.ResponseWriter, r *http.Request, d dialect, v interface{}) ([]byte, bool) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err == nil {
+		err = json.Unmarshal(body, v)
+	}
+	if err != nil {
+		writeError(w, d, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return nil, false
+	}
+	return body, true
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,286 @@
+package support
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/i18n"
+	"dmxapi-config/internal/ui"
+)
+
+// sensitiveFields 配置文件中值需要遮蔽的字段名（小写比较）
+var sensitiveFields = map[string]bool{
+	"apikey":         true,
+	"api_key":        true,
+	"key":            true,
+	"token":          true,
+	"access":         true,
+	"refresh":        true,
+	"secret":         true,
+	"password":       true,
+	"authorization":  true,
+	"x-api-key":      true,
+	"x-goog-api-key": true,
+}
+
+// proxyEnvVars 影响网络连接的代理环境变量（只记录是否设置，不记录取值）
+var proxyEnvVars = []string{"HTTPS_PROXY", "HTTP_PROXY", "ALL_PROXY", "NO_PROXY"}
+
+// entry 归档中的单个文件
+type entry struct {
+	name string
+	data []byte
+}
+
+// DefaultBundleName 返回默认的归档文件名，如 dmxapi-support-20260101-150405.zip
+func DefaultBundleName(now time.Time) string {
+	return "dmxapi-support-" + now.Format("20060102-150405") + ".zip"
+}
+
+// CreateBundle 收集诊断信息并写入 zip 归档 dest
+// 配置中的密钥全部遮蔽，写入前逐个文件检查，发现原始密钥时放弃生成并返回错误
+func CreateBundle(dest string) error {
+	var entries []entry
+	var notes []string
+
+	secrets := &secretSet{}
+	for _, src := range []struct {
+		name string
+		path func() (string, error)
+	}{
+		{"opencode.json", config.GetConfigPath},
+		{"auth.json", config.GetAuthPath},
+	} {
+		path, err := src.path()
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%s: %v", src.name, err))
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%s: %s (%v)", src.name, path, err))
+			continue
+		}
+		sanitized, err := sanitizeJSON(data, secrets)
+		if err != nil {
+			// 无法解析时无法可靠地找出密钥，不收录原文
+			notes = append(notes, fmt.Sprintf("%s: %s is not valid JSON, omitted (%v)", src.name, path, err))
+			continue
+		}
+		entries = append(entries, entry{src.name, sanitized})
+	}
+
+	for _, name := range []string{LastRunReportFile, LastRunLogFile} {
+		path, err := lastRunPath(name)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%s: not found (run the setup wizard first)", name))
+			continue
+		}
+		entries = append(entries, entry{name, data})
+	}
+
+	entries = append([]entry{{"system.txt", []byte(systemInfo(notes))}}, entries...)
+
+	// 先确认所有文件不含任何原始密钥：日志在写入时已经脱敏，仍含原始密钥说明脱敏有遗漏，不能只在这里补救
+	// 再统一隐去 sk- 形式的其他密钥
+	for i := range entries {
+		if secrets.foundIn(entries[i].data) {
+			return fmt.Errorf(i18n.T("support.secret_leak"), entries[i].name)
+		}
+		entries[i].data = []byte(debuglog.Redact(string(entries[i].data)))
+	}
+
+	return writeZip(dest, entries)
+}
+
+// systemInfo 生成工具版本、系统环境、opencode 版本和路径信息
+func systemInfo(notes []string) string {
+	var b strings.Builder
+	line := func(k, v string) { fmt.Fprintf(&b, "%-16s %s\n", k+":", v) }
+
+	line("tool version", ui.Version)
+	line("os/arch", runtime.GOOS+"/"+runtime.GOARCH)
+	line("go version", runtime.Version())
+	line("locale", string(i18n.Current()))
+	line("created at", time.Now().Format(time.RFC3339))
+
+	installed, version := ui.CheckOpencode()
+	switch {
+	case !installed:
+		line("opencode", "not found in PATH")
+	case version == "":
+		line("opencode", "installed (version unknown)")
+	default:
+		line("opencode", version)
+	}
+
+	for _, p := range []struct {
+		name string
+		path func() (string, error)
+	}{
+		{"config path", config.GetConfigPath},
+		{"auth path", config.GetAuthPath},
+		{"state dir", config.GetStateDir},
+	} {
+		if path, err := p.path(); err != nil {
+			line(p.name, err.Error())
+		} else {
+			line(p.name, path)
+		}
+	}
+
+	var namespaces []string
+	for _, u := range config.NewReader().ReadNamespaces() {
+		namespaces = append(namespaces, fmt.Sprintf("%s (%d models)", u.Namespace.Prefix, u.Models))
+	}
+	if len(namespaces) == 0 {
+		line("namespaces", "none")
+	} else {
+		line("namespaces", strings.Join(namespaces, ", "))
+	}
+
+	var proxies []string
+	for _, name := range proxyEnvVars {
+		if os.Getenv(name) != "" || os.Getenv(strings.ToLower(name)) != "" {
+			proxies = append(proxies, name)
+		}
+	}
+	if len(proxies) == 0 {
+		line("proxy env", "none")
+	} else {
+		line("proxy env", strings.Join(proxies, ", ")+" (values omitted)")
+	}
+
+	if len(notes) > 0 {
+		b.WriteString("\nnotes:\n")
+		for _, n := range notes {
+			b.WriteString("  - " + n + "\n")
+		}
+	}
+	return b.String()
+}
+
+// sanitizeJSON 遮蔽 JSON 中敏感字段的值，并把原值登记到 secrets
+func sanitizeJSON(data []byte, secrets *secretSet) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	v = maskValue(v, secrets)
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// maskValue 递归遮蔽敏感字段
+func maskValue(v interface{}, secrets *secretSet) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if s, ok := child.(string); ok && sensitiveFields[strings.ToLower(k)] {
+				secrets.add(s)
+				t[k] = config.MaskAPIKey(s)
+				continue
+			}
+			if headers, ok := child.(map[string]interface{}); ok && k == "headers" {
+				// 自定义请求头的取值可能是代理令牌等凭据，全部遮蔽
+				for name, value := range headers {
+					if s, ok := value.(string); ok {
+						secrets.addHeaderValue(s)
+						headers[name] = config.MaskAPIKey(s)
+					}
+				}
+				continue
+			}
+			t[k] = maskValue(child, secrets)
+		}
+	case []interface{}:
+		for i, child := range t {
+			t[i] = maskValue(child, secrets)
+		}
+	}
+	return v
+}
+
+// secretSet 从配置文件中收集到的原始密钥
+type secretSet struct {
+	list []string
+}
+
+// minHeaderSecret 作为密钥检查的请求头取值最小长度
+// 较短的取值（如 "ops"）多为普通标识，只遮蔽不检查，避免在路径等内容中误报
+const minHeaderSecret = 8
+
+// add 登记敏感字段的值，不论长短都作为密钥检查
+func (s *secretSet) add(secret string) {
+	if secret == "" || slices.Contains(s.list, secret) {
+		return
+	}
+	s.list = append(s.list, secret)
+}
+
+// addHeaderValue 登记自定义请求头的取值，过短的取值不作为密钥检查
+func (s *secretSet) addHeaderValue(value string) {
+	if len(value) < minHeaderSecret {
+		return
+	}
+	s.add(value)
+}
+
+// foundIn 判断 data 中是否含有任意原始密钥
+func (s *secretSet) foundIn(data []byte) bool {
+	text := string(data)
+	for _, secret := range s.list {
+		if strings.Contains(text, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeZip 将文件写入 zip 归档（权限 0600），失败时删除不完整的归档
+func writeZip(dest string, entries []entry) (err error) {
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf(i18n.T("support.create_failed"), err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf(i18n.T("support.create_failed"), cerr)
+		}
+		if err != nil {
+			os.Remove(dest)
+		}
+	}()
+
+	zw := zip.NewWriter(f)
+	prefix := strings.TrimSuffix(filepath.Base(dest), filepath.Ext(dest))
+	for _, e := range entries {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     prefix + "/" + e.name,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf(i18n.T("support.create_failed"), err)
+		}
+		if _, err := w.Write(e.data); err != nil {
+			return fmt.Errorf(i18n.T("support.create_failed"), err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf(i18n.T("support.create_failed"), err)
+	}
+	return nil
+}
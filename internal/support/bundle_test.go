@@ -0,0 +1,173 @@
+package support
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/i18n"
+)
+
+const (
+	providerKey = "sk-providerkey1234567890"
+	modelKey    = "sk-modeloptionkey12345678"
+	authKey     = "sk-authkey1234567890abcd"
+	accessToken = "oauth-access-token-abcdef"
+	refreshKey  = "oauth-refresh-token-123456"
+	proxyToken  = "proxy-token-7f3a9c"
+	shortKey    = "k3y42"
+)
+
+// setupHome 把 HOME 指向临时目录，写入带有已知密钥的 opencode.json、auth.json 和 last-run.log
+func setupHome(t *testing.T, lastRunLog string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	opencode := map[string]interface{}{
+		"$schema": "https://opencode.ai/config.json",
+		"provider": map[string]interface{}{
+			"dmxapi-anthropic": map[string]interface{}{
+				"npm": "@ai-sdk/anthropic",
+				"options": map[string]interface{}{
+					"baseURL": "https://www.dmxapi.cn/v1",
+					"apiKey":  providerKey,
+					"headers": map[string]interface{}{"X-Proxy-Token": proxyToken, "X-Team": "ops"},
+				},
+				"models": map[string]interface{}{
+					"claude-sonnet-4-5": map[string]interface{}{
+						"name":    "claude-sonnet-4-5",
+						"options": map[string]interface{}{"apiKey": modelKey},
+					},
+				},
+			},
+		},
+	}
+	auth := map[string]interface{}{
+		"dmxapi-anthropic": map[string]interface{}{"type": "api", "key": authKey},
+		"dmxapi-google":    map[string]interface{}{"type": "api", "key": shortKey},
+		"github-copilot":   map[string]interface{}{"type": "oauth", "access": accessToken, "refresh": refreshKey, "expires": 0},
+	}
+	for _, f := range []struct {
+		path func() (string, error)
+		v    interface{}
+	}{{config.GetConfigPath, opencode}, {config.GetAuthPath, auth}} {
+		p, _ := f.path()
+		data, _ := json.Marshal(f.v)
+		writeFile(t, p, data)
+	}
+	logPath, _ := lastRunPath(LastRunLogFile)
+	writeFile(t, logPath, []byte(lastRunLog))
+	return home
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readZip 返回归档中每个文件（去掉目录前缀）的内容
+func readZip(t *testing.T, path string) map[string]string {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name[strings.IndexByte(f.Name, '/')+1:]] = string(data)
+	}
+	return files
+}
+
+func TestCreateBundle(t *testing.T) {
+	home := setupHome(t, "12:00:00 [http] --> POST https://www.dmxapi.cn/v1/messages\n"+
+		"12:00:00 [http]     headers: X-Api-Key=[REDACTED]\n"+
+		"12:00:01 [config] unregistered sk-unregisteredkey123456 in body\n")
+	dest := filepath.Join(home, "bundle.zip")
+	if err := CreateBundle(dest); err != nil {
+		t.Fatalf("CreateBundle error: %v", err)
+	}
+
+	files := readZip(t, dest)
+	for _, name := range []string{"system.txt", "opencode.json", "auth.json", LastRunLogFile} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("%s missing from the bundle", name)
+		}
+	}
+	for name, data := range files {
+		for _, secret := range []string{providerKey, modelKey, authKey, accessToken, refreshKey, proxyToken, shortKey, "sk-unregisteredkey123456"} {
+			if strings.Contains(data, secret) {
+				t.Errorf("%s contains the raw secret %q", name, secret)
+			}
+		}
+	}
+
+	// 非敏感字段保留原样，便于排查
+	var cfg struct {
+		Provider map[string]struct {
+			Options struct {
+				BaseURL string            `json:"baseURL"`
+				APIKey  string            `json:"apiKey"`
+				Headers map[string]string `json:"headers"`
+			} `json:"options"`
+		} `json:"provider"`
+	}
+	if err := json.Unmarshal([]byte(files["opencode.json"]), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	opts := cfg.Provider["dmxapi-anthropic"].Options
+	if opts.BaseURL != "https://www.dmxapi.cn/v1" || opts.APIKey != config.MaskAPIKey(providerKey) {
+		t.Errorf("provider options = %+v", opts)
+	}
+	if opts.Headers["X-Team"] != config.MaskAPIKey("ops") {
+		t.Errorf("header values not masked: %v", opts.Headers)
+	}
+	if !strings.Contains(files["auth.json"], `"oauth"`) {
+		t.Errorf("auth.json lost the entry type:\n%s", files["auth.json"])
+	}
+}
+
+func TestCreateBundleLeak(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+	}{
+		{"api key", "12:00:00 [http]     headers: Authorization=Bearer " + authKey + "\n"},
+		{"short key", "12:00:00 [config] key=" + shortKey + "\n"},
+		{"oauth token", "12:00:00 [http]     body: {\"refresh\": \"" + refreshKey + "\"}\n"},
+		{"header value", "12:00:00 [http]     headers: X-Proxy-Token=" + proxyToken + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := setupHome(t, tt.log)
+			dest := filepath.Join(home, "bundle.zip")
+			err := CreateBundle(dest)
+			if want := fmt.Sprintf(i18n.T("support.secret_leak"), LastRunLogFile); err == nil || err.Error() != want {
+				t.Fatalf("CreateBundle error = %v, want %q", err, want)
+			}
+			if _, err := os.Stat(dest); !os.IsNotExist(err) {
+				t.Error("bundle written despite the leak")
+			}
+		})
+	}
+}
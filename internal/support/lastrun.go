@@ -0,0 +1,54 @@
+package support
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/debuglog"
+)
+
+const (
+	// LastRunLogFile 最近一次配置向导的调试日志（位于状态目录，每次运行覆盖）
+	LastRunLogFile = "last-run.log"
+	// LastRunReportFile 最近一次配置向导的结果文档（位于状态目录，每次运行覆盖）
+	LastRunReportFile = "last-run.json"
+)
+
+// lastRunPath 返回状态目录下的文件路径
+func lastRunPath(name string) (string, error) {
+	dir, err := config.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// OpenLastRunLog 创建（覆盖）最近一次运行的调试日志文件
+func OpenLastRunLog() (*os.File, error) {
+	path, err := lastRunPath(LastRunLogFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.EnsureDir(path); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+}
+
+// SaveLastRunReport 保存最近一次运行的结果文档，写入前隐去密钥
+func SaveLastRunReport(report interface{}) error {
+	path, err := lastRunPath(LastRunReportFile)
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(path); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(debuglog.Redact(string(data))), 0600)
+}
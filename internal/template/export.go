@@ -0,0 +1,149 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/i18n"
+)
+
+// secretFields 导出时从模型配置中删除的字段名（小写比较）
+var secretFields = map[string]bool{
+	"apikey":         true,
+	"api_key":        true,
+	"authorization":  true,
+	"x-api-key":      true,
+	"x-goog-api-key": true,
+}
+
+// FromExisting 根据现有 DMXAPI 配置生成模板，不包含 API Key；使用非默认命名空间时一并记录
+// 模型所在 provider 与按名称自动判断的结果不同时，在模型上记录 provider 以保留路由
+func FromExisting(existing *config.ExistingConfig) *Template {
+	t := &Template{
+		URL:          existing.URL,
+		Models:       make([]ModelEntry, 0, len(existing.Models)),
+		DefaultModel: existing.DefaultModel,
+		SmallModel:   existing.SmallModel,
+	}
+	if ns := config.CurrentNamespace(); !ns.IsDefault() {
+		t.ProviderPrefix = ns.Prefix
+		t.ProviderName = ns.Name
+	} else if ns.Name != "" && ns.Name != config.DefaultNamespace.Name {
+		t.ProviderName = ns.Name
+	}
+	for _, id := range existing.Models {
+		entry := ModelEntry{ID: id, Model: existing.ModelConfigs[id]}
+		if pType, ok := config.ProviderTypeByID(existing.ModelProviders[id]); ok && pType != config.ClassifyModel(id) {
+			entry.Provider = pType.String()
+		}
+		entry.Model.Options = stripSecrets(entry.Model.Options, existing.APIKey)
+		entry.Model.Extra = stripSecrets(entry.Model.Extra, existing.APIKey)
+		t.Models = append(t.Models, entry)
+	}
+	t.setHeaders(existing)
+	return t
+}
+
+// setHeaders 记录现有配置中的自定义请求头：所有 provider 相同的写入 headers，其余按 provider 类型写入 provider_headers
+// 与 API Key 相关的请求头不导出
+func (t *Template) setHeaders(existing *config.ExistingConfig) {
+	if headers := stripSecretHeaders(existing.Headers.Global, existing.APIKey); len(headers) > 0 {
+		t.Headers = headers
+	}
+	for pType, headers := range existing.Headers.Provider {
+		headers = stripSecretHeaders(headers, existing.APIKey)
+		if len(headers) == 0 {
+			continue
+		}
+		if t.ProviderHeaders == nil {
+			t.ProviderHeaders = make(map[string]map[string]string)
+		}
+		t.ProviderHeaders[pType.String()] = headers
+	}
+}
+
+// stripSecretHeaders 删除取值包含 API Key 的请求头
+func stripSecretHeaders(headers map[string]string, apiKey string) map[string]string {
+	out := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if secretFields[strings.ToLower(name)] || (apiKey != "" && strings.Contains(value, apiKey)) {
+			continue
+		}
+		out[name] = value
+	}
+	return out
+}
+
+// stripSecrets 递归删除敏感字段以及取值等于 API Key 的字段
+func stripSecrets(m map[string]interface{}, apiKey string) map[string]interface{} {
+	if len(m) == 0 {
+		return m
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if secretFields[strings.ToLower(k)] {
+			continue
+		}
+		switch val := v.(type) {
+		case string:
+			if apiKey != "" && strings.Contains(val, apiKey) {
+				continue
+			}
+		case map[string]interface{}:
+			v = stripSecrets(val, apiKey)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// Marshal 按格式（"json" 或 "yaml"）序列化模板
+func (t *Template) Marshal(format string) ([]byte, error) {
+	if format == "json" {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(t); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(t); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Export 根据现有配置生成模板内容，format 为空时按 YAML 输出
+// 输出前再次确认内容中不含 API Key
+func Export(existing *config.ExistingConfig, format string) ([]byte, error) {
+	data, err := FromExisting(existing).Marshal(format)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("export.marshal_failed"), err)
+	}
+	if existing.APIKey != "" && bytes.Contains(data, []byte(existing.APIKey)) {
+		return nil, i18n.Error("export.secret_leak")
+	}
+	return data, nil
+}
+
+// FormatOf 根据文件名判断导出格式：.json 为 JSON，其余为 YAML
+func FormatOf(name string) string {
+	if formatOf(name) == "json" {
+		return "json"
+	}
+	return "yaml"
+}
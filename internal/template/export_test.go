@@ -0,0 +1,155 @@
+package template
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"dmxapi-config/internal/config"
+)
+
+const testKey = "sk-templatetestkey1234567890"
+
+// testExisting 返回包含路由覆盖、模型附加字段和自定义请求头的现有配置，其中多处带有 API Key
+func testExisting() *config.ExistingConfig {
+	return &config.ExistingConfig{
+		URL:    "https://www.dmxapi.cn",
+		APIKey: testKey,
+		Models: []string{"claude-sonnet-4-5", "gpt-5", "my-proxy-model"},
+		ModelConfigs: map[string]config.Model{
+			"claude-sonnet-4-5": {Name: "claude-sonnet-4-5"},
+			"gpt-5": {Name: "GPT-5", Options: map[string]interface{}{
+				"reasoningEffort": "high",
+				"apiKey":          testKey,
+				"headers":         map[string]interface{}{"Authorization": "Bearer " + testKey, "X-Trace": "1"},
+			}},
+			"my-proxy-model": {Name: "my-proxy-model", Extra: map[string]interface{}{
+				"limit":  map[string]interface{}{"context": float64(200000)},
+				"secret": "prefix-" + testKey,
+			}},
+		},
+		ModelProviders: map[string]string{
+			"claude-sonnet-4-5": "dmxapi-anthropic",
+			"gpt-5":             "dmxapi-openai-responses",
+			"my-proxy-model":    "dmxapi-anthropic",
+		},
+		Headers: config.Headers{
+			Global: map[string]string{"X-Team": "ops", "X-Token": testKey},
+			Provider: map[config.ProviderType]map[string]string{
+				config.ProviderAnthropic: {"X-Region": "us", "x-api-key": testKey},
+				config.ProviderGoogle:    {"X-Auth": "Bearer " + testKey},
+			},
+		},
+		DefaultModel: "claude-sonnet-4-5",
+		SmallModel:   "gpt-5",
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	existing := testExisting()
+	want := &Template{
+		URL: "https://www.dmxapi.cn",
+		Models: []ModelEntry{
+			// 名称与 ID 相同时不导出
+			{ID: "claude-sonnet-4-5"},
+			{ID: "gpt-5", Model: config.Model{Name: "GPT-5", Options: map[string]interface{}{
+				"reasoningEffort": "high",
+				"headers":         map[string]interface{}{"X-Trace": "1"},
+			}}},
+			{ID: "my-proxy-model", Provider: "anthropic", Model: config.Model{Extra: map[string]interface{}{
+				"limit": map[string]interface{}{"context": float64(200000)},
+			}}},
+		},
+		DefaultModel:    "claude-sonnet-4-5",
+		SmallModel:      "gpt-5",
+		Headers:         map[string]string{"X-Team": "ops"},
+		ProviderHeaders: map[string]map[string]string{"anthropic": {"X-Region": "us"}},
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			data, err := Export(existing, format)
+			if err != nil {
+				t.Fatalf("Export error: %v", err)
+			}
+			if strings.Contains(string(data), testKey) || strings.Contains(string(data), "apiKey") {
+				t.Errorf("exported template contains the API key:\n%s", data)
+			}
+			got, err := Parse(data, format)
+			if err != nil {
+				t.Fatalf("Parse error: %v\n%s", err, data)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip =\n%+v\nwant\n%+v\n%s", got, want, data)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("exported template is invalid: %v", err)
+			}
+
+			// 导入导出的模板得到与原配置相同的路由
+			cfg := got.Config(existing.URL, testKey)
+			for id, provider := range existing.ModelProviders {
+				if _, ok := cfg.Provider[provider].Models[id]; !ok {
+					t.Errorf("%s not routed to %s after import", id, provider)
+				}
+			}
+			if cfg.Model != "dmxapi-anthropic/claude-sonnet-4-5" || cfg.SmallModel != "dmxapi-openai-responses/gpt-5" {
+				t.Errorf("model = %q, small_model = %q", cfg.Model, cfg.SmallModel)
+			}
+		})
+	}
+}
+
+func TestExportNamespace(t *testing.T) {
+	t.Cleanup(func() { config.UseNamespace(config.DefaultNamespace) })
+	existing := &config.ExistingConfig{URL: "https://www.dmxapi.cn", APIKey: testKey, Models: []string{"gpt-5"}}
+
+	tests := []struct {
+		ns     config.Namespace
+		prefix string
+		name   string
+	}{
+		{config.DefaultNamespace, "", ""},
+		{config.Namespace{Prefix: "dmxapi", Name: "Gateway {type}"}, "", "Gateway {type}"},
+		{config.Namespace{Prefix: "team", Name: "Team {type}"}, "team", "Team {type}"},
+	}
+	for _, tt := range tests {
+		config.UseNamespace(tt.ns)
+		data, err := Export(existing, "yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Parse(data, "yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ProviderPrefix != tt.prefix || got.ProviderName != tt.name {
+			t.Errorf("%+v: exported prefix %q, name %q; want %q, %q", tt.ns, got.ProviderPrefix, got.ProviderName, tt.prefix, tt.name)
+		}
+	}
+}
+
+func TestExportRefusesLeak(t *testing.T) {
+	existing := &config.ExistingConfig{URL: "https://www.dmxapi.cn/?key=" + testKey, APIKey: testKey, Models: []string{"gpt-5"}}
+	for _, format := range []string{"json", "yaml"} {
+		if data, err := Export(existing, format); err == nil {
+			t.Errorf("Export(%s) wrote a template containing the API key:\n%s", format, data)
+		}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := map[string]string{
+		"team.json":         "json",
+		"TEAM.JSON":         "json",
+		"team.yaml":         "yaml",
+		"team.yml":          "yaml",
+		"dmxapi-template":   "yaml",
+		"team.json.example": "yaml",
+	}
+	for name, want := range tests {
+		if got := FormatOf(name); got != want {
+			t.Errorf("FormatOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
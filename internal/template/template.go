@@ -0,0 +1,359 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/i18n"
+	"dmxapi-config/internal/input"
+)
+
+// maxTemplateSize 模板文件大小上限
+const maxTemplateSize = 1 << 20
+
+// Template 团队共享的配置模板，描述除 API Key 以外的全部配置
+type Template struct {
+	URL          string            `json:"url,omitempty" yaml:"url,omitempty"`                     // DMXAPI 地址，为空时由用户输入
+	Models       []ModelEntry      `json:"models" yaml:"models"`                                   // 模型列表
+	DefaultModel string            `json:"default_model,omitempty" yaml:"default_model,omitempty"` // 默认模型 ID
+	SmallModel   string            `json:"small_model,omitempty" yaml:"small_model,omitempty"`     // 轻量任务使用的模型 ID
+	Routing      map[string]string `json:"routing,omitempty" yaml:"routing,omitempty"`             // 模型 ID（支持 * 通配）到 provider 类型的路由覆盖
+
+	ProviderPrefix string `json:"provider_prefix,omitempty" yaml:"provider_prefix,omitempty"` // provider ID 前缀（命名空间），为空时使用默认的 dmxapi
+	ProviderName   string `json:"provider_name,omitempty" yaml:"provider_name,omitempty"`     // provider 显示名称模板，{type} 替换为 Claude、Gemini 等
+
+	Headers         map[string]string            `json:"headers,omitempty" yaml:"headers,omitempty"`                   // 所有 provider 的自定义请求头
+	ProviderHeaders map[string]map[string]string `json:"provider_headers,omitempty" yaml:"provider_headers,omitempty"` // provider 类型到该类型的自定义请求头，同名时覆盖 headers
+}
+
+// ModelEntry 模板中的单个模型
+// 可以直接写模型 ID 字符串，也可以写对象：id 必填，provider 指定路由，其余字段原样写入 opencode.json
+type ModelEntry struct {
+	ID       string
+	Provider string
+	Model    config.Model
+}
+
+// UnmarshalJSON 支持字符串和对象两种写法
+func (e *ModelEntry) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*e = ModelEntry{ID: id}
+		return nil
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*e = ModelEntry{}
+	e.ID, _ = raw["id"].(string)
+	e.Provider, _ = raw["provider"].(string)
+	delete(raw, "id")
+	delete(raw, "provider")
+
+	rest, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(rest, &e.Model)
+}
+
+// simple 判断模型是否只有 ID，没有路由和附加字段
+func (e ModelEntry) simple() bool {
+	return e.Provider == "" && (e.Model.Name == "" || e.Model.Name == e.ID) && len(e.Model.Options) == 0 && len(e.Model.Extra) == 0
+}
+
+// MarshalJSON 没有附加字段时输出为字符串，否则输出为对象
+func (e ModelEntry) MarshalJSON() ([]byte, error) {
+	if e.simple() {
+		return json.Marshal(e.ID)
+	}
+	data, err := json.Marshal(e.Model)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	if e.Model.Name == "" || e.Model.Name == e.ID {
+		delete(out, "name")
+	}
+	out["id"] = e.ID
+	if e.Provider != "" {
+		out["provider"] = e.Provider
+	}
+	return json.Marshal(out)
+}
+
+// MarshalYAML 与 MarshalJSON 规则一致
+func (e ModelEntry) MarshalYAML() (interface{}, error) {
+	if e.simple() {
+		return e.ID, nil
+	}
+	data, err := e.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Load 从本地路径或 http(s) URL 读取模板，按扩展名识别 JSON / YAML（无法判断时依次尝试）
+func Load(source string) (*Template, error) {
+	data, err := read(source)
+	if err != nil {
+		return nil, err
+	}
+	t, err := Parse(data, formatOf(source))
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("template.parse_failed"), source, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, fmt.Errorf(i18n.T("template.invalid"), source, err)
+	}
+	return t, nil
+}
+
+// isRemote 判断模板来源是否为 URL
+func isRemote(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// read 读取模板内容
+func read(source string) ([]byte, error) {
+	if !isRemote(source) {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("template.read_failed"), source, err)
+		}
+		return data, nil
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second, Transport: debuglog.Transport()}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("template.read_failed"), source, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(i18n.T("template.download_status"), source, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTemplateSize))
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("template.read_failed"), source, err)
+	}
+	return data, nil
+}
+
+// formatOf 根据扩展名判断模板格式，返回 "json"、"yaml" 或空字符串（未知）
+func formatOf(source string) string {
+	name := source
+	if isRemote(source) {
+		name = strings.SplitN(strings.SplitN(source, "?", 2)[0], "#", 2)[0]
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	return ""
+}
+
+// Parse 解析模板内容，format 为空时先按 JSON 再按 YAML 解析
+// YAML 先转换为 JSON 再解码，保证两种格式的字段规则完全一致
+func Parse(data []byte, format string) (*Template, error) {
+	if format == "json" || (format == "" && json.Valid(data)) {
+		var t Template
+		if err := json.Unmarshal(data, &t); err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
+
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var t Template
+	if err := json.Unmarshal(jsonData, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Validate 检查模板内容：URL 格式、命名空间、模型列表、路由中的 provider 类型和默认模型
+func (t *Template) Validate() error {
+	if t.URL != "" {
+		if err := input.ValidateURL(t.URL); err != nil {
+			return err
+		}
+	}
+	if t.ProviderPrefix != "" || t.ProviderName != "" {
+		ns := config.Namespace{Prefix: t.ProviderPrefix, Name: t.ProviderName}
+		if ns.Prefix == "" {
+			ns.Prefix = config.DefaultNamespace.Prefix
+		}
+		if err := ns.Validate(); err != nil {
+			return err
+		}
+	}
+
+	ids := make([]string, 0, len(t.Models))
+	seen := make(map[string]bool, len(t.Models))
+	for _, m := range t.Models {
+		if seen[m.ID] {
+			return fmt.Errorf(i18n.T("template.duplicate_model"), m.ID)
+		}
+		seen[m.ID] = true
+		ids = append(ids, m.ID)
+		if m.Provider != "" {
+			if _, ok := config.ParseProviderType(m.Provider); !ok {
+				return fmt.Errorf(i18n.T("template.unknown_provider"), m.Provider, m.ID)
+			}
+		}
+	}
+	if err := input.ValidateModels(ids); err != nil {
+		return err
+	}
+
+	for pattern, provider := range t.Routing {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf(i18n.T("template.bad_pattern"), pattern)
+		}
+		if _, ok := config.ParseProviderType(provider); !ok {
+			return fmt.Errorf(i18n.T("template.unknown_provider"), provider, pattern)
+		}
+	}
+
+	for _, m := range []string{t.DefaultModel, t.SmallModel} {
+		if m != "" && !seen[m] {
+			return fmt.Errorf(i18n.T("template.default_not_listed"), m)
+		}
+	}
+
+	for name, value := range t.Headers {
+		if err := config.ValidateHeader(name, value); err != nil {
+			return err
+		}
+	}
+	for provider, headers := range t.ProviderHeaders {
+		if _, ok := config.ParseProviderType(provider); !ok {
+			return fmt.Errorf(i18n.T("template.unknown_provider"), provider, "provider_headers")
+		}
+		for name, value := range headers {
+			if err := config.ValidateHeader(name, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CustomHeaders 返回模板中的自定义请求头
+func (t *Template) CustomHeaders() config.Headers {
+	var h config.Headers
+	for name, value := range t.Headers {
+		h.Set(nil, name, value)
+	}
+	for provider, headers := range t.ProviderHeaders {
+		pType, ok := config.ParseProviderType(provider)
+		if !ok {
+			continue
+		}
+		for name, value := range headers {
+			h.Set(&pType, name, value)
+		}
+	}
+	return h
+}
+
+// ModelIDs 返回模板中的模型 ID 列表
+func (t *Template) ModelIDs() []string {
+	ids := make([]string, 0, len(t.Models))
+	for _, m := range t.Models {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+// providerFor 返回模型的路由覆盖：模型自身的 provider 优先，其次是 routing 中的规则
+// routing 精确匹配优先，多个通配规则都匹配时取最长（最具体）的规则
+func (t *Template) providerFor(m ModelEntry) *config.ProviderType {
+	name := m.Provider
+	if name == "" {
+		name = t.Routing[m.ID]
+	}
+	if name == "" {
+		best := ""
+		for pattern, provider := range t.Routing {
+			if ok, _ := path.Match(pattern, m.ID); !ok {
+				continue
+			}
+			if len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+				best, name = pattern, provider
+			}
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	pType, ok := config.ParseProviderType(name)
+	if !ok {
+		return nil
+	}
+	return &pType
+}
+
+// ProviderOf 返回模型最终路由到的 provider 类型
+func (t *Template) ProviderOf(id string) config.ProviderType {
+	for _, m := range t.Models {
+		if m.ID == id {
+			if pType := t.providerFor(m); pType != nil {
+				return *pType
+			}
+		}
+	}
+	return config.ClassifyModel(id)
+}
+
+// Config 按模板生成 opencode 配置，url 为最终使用的地址（模板未指定时由用户输入）
+func (t *Template) Config(url, apiKey string) *config.OpenCodeConfig {
+	specs := make([]config.ModelSpec, 0, len(t.Models))
+	for _, m := range t.Models {
+		specs = append(specs, config.ModelSpec{
+			ID:       m.ID,
+			Model:    m.Model,
+			Provider: t.providerFor(m),
+		})
+	}
+	cfg := config.NewDMXAPIConfigFromSpecs(url, apiKey, specs)
+	cfg.ApplyHeaders(t.CustomHeaders())
+	if id, ok := cfg.QualifiedModelID(t.DefaultModel); ok {
+		cfg.Model = id
+	}
+	if id, ok := cfg.QualifiedModelID(t.SmallModel); ok {
+		cfg.SmallModel = id
+	}
+	return cfg
+}
@@ -0,0 +1,197 @@
+package template
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"dmxapi-config/internal/config"
+)
+
+const jsonTemplate = `{
+  "url": "https://www.dmxapi.cn",
+  "models": [
+    "claude-sonnet-4-5",
+    {"id": "my-proxy-model", "provider": "anthropic", "name": "Proxy", "limit": {"context": 200000}},
+    "gpt-5"
+  ],
+  "default_model": "claude-sonnet-4-5",
+  "small_model": "gpt-5",
+  "routing": {"my-*": "google"},
+  "provider_prefix": "team",
+  "headers": {"X-Team": "ops"},
+  "provider_headers": {"anthropic": {"X-Region": "us"}}
+}`
+
+const yamlTemplate = `url: https://www.dmxapi.cn
+models:
+  - claude-sonnet-4-5
+  - id: my-proxy-model
+    provider: anthropic
+    name: Proxy
+    limit:
+      context: 200000
+  - gpt-5
+default_model: claude-sonnet-4-5
+small_model: gpt-5
+routing:
+  "my-*": google
+provider_prefix: team
+headers:
+  X-Team: ops
+provider_headers:
+  anthropic:
+    X-Region: us
+`
+
+func TestParse(t *testing.T) {
+	want := &Template{
+		URL: "https://www.dmxapi.cn",
+		Models: []ModelEntry{
+			{ID: "claude-sonnet-4-5"},
+			{ID: "my-proxy-model", Provider: "anthropic", Model: config.Model{Name: "Proxy", Extra: map[string]interface{}{"limit": map[string]interface{}{"context": float64(200000)}}}},
+			{ID: "gpt-5"},
+		},
+		DefaultModel:    "claude-sonnet-4-5",
+		SmallModel:      "gpt-5",
+		Routing:         map[string]string{"my-*": "google"},
+		ProviderPrefix:  "team",
+		Headers:         map[string]string{"X-Team": "ops"},
+		ProviderHeaders: map[string]map[string]string{"anthropic": {"X-Region": "us"}},
+	}
+	tests := []struct {
+		name, data, format string
+	}{
+		{"json", jsonTemplate, "json"},
+		{"yaml", yamlTemplate, "yaml"},
+		{"json detected", jsonTemplate, ""},
+		{"yaml detected", yamlTemplate, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse =\n%+v\nwant\n%+v", got, want)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("Validate error: %v", err)
+			}
+		})
+	}
+
+	if _, err := Parse([]byte("models: [a"), "yaml"); err == nil {
+		t.Error("Parse accepted malformed YAML")
+	}
+	if _, err := Parse([]byte(`{"models": "gpt-5"}`), "json"); err == nil {
+		t.Error("Parse accepted models as a string")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	models := func(ids ...string) []ModelEntry {
+		entries := make([]ModelEntry, len(ids))
+		for i, id := range ids {
+			entries[i] = ModelEntry{ID: id}
+		}
+		return entries
+	}
+	tests := []struct {
+		name string
+		t    Template
+		ok   bool
+	}{
+		{"minimal", Template{Models: models("gpt-5")}, true},
+		{"with url", Template{URL: "http://localhost:8089", Models: models("gpt-5")}, true},
+		{"empty models", Template{URL: "https://www.dmxapi.cn"}, false},
+		{"blank model", Template{Models: models("gpt-5", " ")}, false},
+		{"duplicate model", Template{Models: models("gpt-5", "gpt-5")}, false},
+		{"url without scheme", Template{URL: "www.dmxapi.cn", Models: models("gpt-5")}, false},
+		{"url with other scheme", Template{URL: "ftp://www.dmxapi.cn", Models: models("gpt-5")}, false},
+		{"url without host", Template{URL: "https://", Models: models("gpt-5")}, false},
+		{"unknown model provider", Template{Models: []ModelEntry{{ID: "gpt-5", Provider: "azure"}}}, false},
+		{"known model provider", Template{Models: []ModelEntry{{ID: "gpt-5", Provider: "OpenAI"}}}, true},
+		{"unknown routing provider", Template{Models: models("gpt-5"), Routing: map[string]string{"gpt-*": "openai-chat"}}, false},
+		{"bad routing pattern", Template{Models: models("gpt-5"), Routing: map[string]string{"gpt-[": "openai"}}, false},
+		{"default model not listed", Template{Models: models("gpt-5"), DefaultModel: "claude-sonnet-4-5"}, false},
+		{"small model not listed", Template{Models: models("gpt-5"), SmallModel: "gpt-5-mini"}, false},
+		{"bad prefix", Template{Models: models("gpt-5"), ProviderPrefix: "Team"}, false},
+		{"name without type", Template{Models: models("gpt-5"), ProviderName: "Team"}, false},
+		{"reserved header", Template{Models: models("gpt-5"), Headers: map[string]string{"Authorization": "Bearer x"}}, false},
+		{"unknown header provider", Template{Models: models("gpt-5"), ProviderHeaders: map[string]map[string]string{"azure": {"X-A": "1"}}}, false},
+		{"bad provider header", Template{Models: models("gpt-5"), ProviderHeaders: map[string]map[string]string{"google": {"X-A": "1\n2"}}}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.t.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%s: Validate = %v, want ok = %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestProviderOf(t *testing.T) {
+	tmpl := &Template{
+		Models: []ModelEntry{{ID: "my-claude", Provider: "anthropic"}, {ID: "my-gemini"}, {ID: "my-gpt"}, {ID: "claude-sonnet-4-5"}},
+		Routing: map[string]string{
+			"my-*":      "openai",
+			"my-gem*":   "google",
+			"my-claude": "openai", // 模型自身的 provider 优先
+		},
+	}
+	tests := []struct {
+		id   string
+		want config.ProviderType
+	}{
+		{"my-claude", config.ProviderAnthropic},
+		{"my-gemini", config.ProviderGoogle}, // 最长的通配规则
+		{"my-gpt", config.ProviderOpenAI},
+		{"claude-sonnet-4-5", config.ProviderAnthropic}, // 按名称判断
+	}
+	for _, tt := range tests {
+		if got := tmpl.ProviderOf(tt.id); got != tt.want {
+			t.Errorf("ProviderOf(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	// 扩展名决定格式：.yml 不会按 JSON 解析
+	yml := filepath.Join(dir, "team.yml")
+	if err := os.WriteFile(yml, []byte(yamlTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if tmpl, err := Load(yml); err != nil || len(tmpl.Models) != 3 {
+		t.Errorf("Load(%s) = %+v, %v", yml, tmpl, err)
+	}
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"models": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(invalid); err == nil || !strings.Contains(err.Error(), invalid) {
+		t.Errorf("Load(%s) error = %v, want one naming the file", invalid, err)
+	}
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Load succeeded for a missing file")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/team.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(jsonTemplate))
+	}))
+	t.Cleanup(srv.Close)
+	if tmpl, err := Load(srv.URL + "/team.json?token=abc"); err != nil || tmpl.ProviderPrefix != "team" {
+		t.Errorf("Load(remote) = %+v, %v", tmpl, err)
+	}
+	if _, err := Load(srv.URL + "/missing.json"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Load(remote 404) error = %v", err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+
+	"dmxapi-config/internal/i18n"
 )
 
 // Version 版本号常量，方便后续修改
@@ -158,82 +160,98 @@ func colorize(color, text string) string {
 func PrintBanner() {
 	// 问题1修复：根据终端能力选择 Unicode Art 或纯 ASCII 横幅
 	if supportsUnicode() {
-		fmt.Print(colorize(ColorBold+ColorCyan, dmxapiASCIIArt))
+		fmt.Fprint(out, colorize(ColorBold+ColorCyan, dmxapiASCIIArt))
 	} else {
-		fmt.Print(dmxapiASCIIFallback)
+		fmt.Fprint(out, dmxapiASCIIFallback)
 	}
-	fmt.Printf(" %s · %s\n", colorize(ColorBold, "OpenCode 配置工具"), colorize(ColorCyan, "让 AI 触手可及"))
-	fmt.Printf(" %s\n", colorize(ColorDim, fmt.Sprintf("v%s / %s/%s", Version, runtime.GOOS, runtime.GOARCH)))
-	fmt.Println()
+	fmt.Fprintf(out, " %s · %s\n", colorize(ColorBold, i18n.T("ui.title")), colorize(ColorCyan, i18n.T("ui.slogan")))
+	fmt.Fprintf(out, " %s\n", colorize(ColorDim, fmt.Sprintf("v%s / %s/%s", Version, runtime.GOOS, runtime.GOARCH)))
+	fmt.Fprintln(out)
 }
 
 // PrintStep 打印步骤信息，格式为 [N/M]
 func PrintStep(step, total int, message string) {
-	fmt.Printf("\n%s %s\n", colorize(ColorCyan, fmt.Sprintf("[%d/%d]", step, total)), message)
+	fmt.Fprintf(out, "\n%s %s\n", colorize(ColorCyan, fmt.Sprintf("[%d/%d]", step, total)), message)
 }
 
 // PrintSuccess 打印成功信息
 func PrintSuccess(message string) {
-	fmt.Println(colorize(ColorGreen, "  "+symbol("✓", "[OK]")+" "+message))
+	fmt.Fprintln(out, colorize(ColorGreen, "  "+symbol("✓", "[OK]")+" "+message))
 }
 
 // PrintError 打印错误信息
 func PrintError(message string) {
-	fmt.Println(colorize(ColorRed, "  "+symbol("✗", "[X]")+" "+message))
+	fmt.Fprintln(out, colorize(ColorRed, "  "+symbol("✗", "[X]")+" "+message))
 }
 
 // PrintInfo 打印提示信息
 func PrintInfo(message string) {
-	fmt.Println(colorize(ColorYellow, "  "+symbol("→", "->")+" "+message))
+	fmt.Fprintln(out, colorize(ColorYellow, "  "+symbol("→", "->")+" "+message))
 }
 
 // PrintWarning 打印警告信息
 func PrintWarning(message string) {
-	fmt.Println(colorize(ColorYellow, "  "+symbol("⚠", "[!]")+" "+message))
+	fmt.Fprintln(out, colorize(ColorYellow, "  "+symbol("⚠", "[!]")+" "+message))
 }
 
 // PrintDivider 打印分隔线
 func PrintDivider() {
-	fmt.Println()
+	fmt.Fprintln(out)
 }
 
 // PrintComplete 打印完成信息
 func PrintComplete() {
-	fmt.Println()
-	fmt.Println(colorize(ColorGreen, "  "+symbol("✓", "[OK]")+" 配置完成！"))
-	fmt.Printf("  %s\n", colorize(ColorDim, "运行 'opencode' 启动程序"))
-	fmt.Println()
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, colorize(ColorGreen, "  "+symbol("✓", "[OK]")+" "+i18n.T("ui.complete")))
+	fmt.Fprintf(out, "  %s\n", colorize(ColorDim, i18n.T("main.run_opencode")))
+	fmt.Fprintln(out)
 }
 
 // PrintConfigModeHeader 打印配置模式选择标题
 func PrintConfigModeHeader() {
-	fmt.Println()
-	fmt.Printf("  %s %s\n", colorize(ColorCyan, symbol("⚙", "[*]")), "请选择配置模式：")
-	fmt.Println()
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "  %s %s\n", colorize(ColorCyan, symbol("⚙", "[*]")), i18n.T("ui.choose_mode"))
+	fmt.Fprintln(out)
 }
 
 // PrintExistingConfigInfo 显示当前配置信息
-func PrintExistingConfigInfo(url, maskedAPIKey string, models []string) {
-	fmt.Println()
-	fmt.Printf("  %s 检测到现有 DMXAPI 配置\n", colorize(ColorCyan, symbol("⚙", "[*]")))
-	fmt.Printf("    %-6s  %s\n", "URL:", url)
-	fmt.Printf("    %-6s  %s\n", "Key:", maskedAPIKey)
-	fmt.Printf("    %-6s  %s\n", "模型:", strings.Join(models, ", "))
-	fmt.Println()
+// balance 为空字符串时不显示余额行
+func PrintExistingConfigInfo(url, maskedAPIKey string, models []string, balance string) {
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "  %s %s\n", colorize(ColorCyan, symbol("⚙", "[*]")), i18n.T("ui.existing_config"))
+	fmt.Fprintf(out, "    %-6s  %s\n", "URL:", url)
+	fmt.Fprintf(out, "    %-6s  %s\n", "Key:", maskedAPIKey)
+	fmt.Fprintf(out, "    %-6s  %s\n", i18n.T("ui.label_models"), strings.Join(models, ", "))
+	if balance != "" {
+		fmt.Fprintf(out, "    %-6s  %s\n", i18n.T("ui.label_balance"), balance)
+	}
+	fmt.Fprintln(out)
 }
 
 // PrintModelOnlyModeInfo 打印仅模型模式提示
 func PrintModelOnlyModeInfo() {
-	fmt.Println()
-	PrintInfo("仅配置模型模式")
+	fmt.Fprintln(out)
+	PrintInfo(i18n.T("ui.model_only_mode"))
 }
 
 // PrintUpdateNotice 打印新版本提示
-func PrintUpdateNotice(latestVersion, dlURL string) {
-	fmt.Printf("  %s 发现新版本 %s（当前 v%s）\n",
+// notes 为更新说明摘要（每行一条），为空时不显示
+func PrintUpdateNotice(latestVersion, dlURL, notes string) {
+	fmt.Fprintf(out, "  %s %s\n",
 		colorize(ColorYellow, symbol("→", "->")),
-		colorize(ColorGreen+ColorBold, "v"+latestVersion),
-		Version,
+		i18n.T("ui.new_version", colorize(ColorGreen+ColorBold, "v"+latestVersion), Version),
 	)
-	fmt.Printf("    %s %s\n\n", colorize(ColorDim, "下载:"), dlURL)
+	PrintReleaseNotes(notes)
+	fmt.Fprintf(out, "    %s %s\n", colorize(ColorDim, i18n.T("ui.label_download")), dlURL)
+	fmt.Fprintf(out, "    %s %s\n\n", colorize(ColorDim, i18n.T("ui.label_update")), i18n.T("ui.run_update"))
+}
+
+// PrintReleaseNotes 打印更新说明摘要（每行一条），为空时不输出
+func PrintReleaseNotes(notes string) {
+	if notes == "" {
+		return
+	}
+	for _, line := range strings.Split(notes, "\n") {
+		fmt.Fprintf(out, "    %s\n", colorize(ColorDim, line))
+	}
 }
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"dmxapi-config/internal/i18n"
+)
+
+// OutputFormat 结果输出格式
+type OutputFormat string
+
+const (
+	OutputText OutputFormat = "text" // 彩色终端文本（默认）
+	OutputJSON OutputFormat = "json" // 单个 JSON 结果文档，供脚本解析
+)
+
+// ParseOutputFormat 解析 --output 参数
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch f := OutputFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case OutputText, OutputJSON:
+		return f, nil
+	}
+	return "", fmt.Errorf(i18n.T("ui.unknown_output"), s)
+}
+
+// out 横幅、步骤和状态提示的输出位置
+var out io.Writer = os.Stdout
+
+// SetQuiet 关闭横幅、步骤、状态提示和下载进度等终端装饰输出
+// JSON 输出模式下使用，保证标准输出只包含结果文档
+func SetQuiet(quiet bool) {
+	if quiet {
+		out = io.Discard
+	} else {
+		out = os.Stdout
+	}
+}
+
+// SetOutput 将横幅、步骤和状态提示改写到 w
+// 命令把结果写到标准输出时使用（如 export -），提示信息改走标准错误
+func SetOutput(w io.Writer) {
+	out = w
+}
@@ -0,0 +1,243 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/i18n"
+)
+
+// checksumsAssetName 发布附件中的 SHA-256 校验文件（sha256sum 输出格式）
+const checksumsAssetName = "checksums.txt"
+
+// AssetName 返回指定平台的发布附件文件名，与发布流水线的命名规则一致：
+// opencode-dmxapi-<tag>-<os>-<arch>[.exe]，其中 darwin 命名为 macos
+func AssetName(tag, goos, goarch string) string {
+	osName := goos
+	if goos == "darwin" {
+		osName = "macos"
+	}
+	name := fmt.Sprintf("opencode-dmxapi-%s-%s-%s", tag, osName, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// SelfUpdate 下载 release 中当前平台的二进制，校验 SHA-256 后替换正在运行的程序
+// 替换失败时自动回滚到原文件，返回被替换的可执行文件路径
+func SelfUpdate(release *Release) (string, error) {
+	assetName := AssetName(release.TagName, runtime.GOOS, runtime.GOARCH)
+	asset := release.FindAsset(assetName)
+	if asset == nil {
+		return "", fmt.Errorf(i18n.T("selfupdate.no_asset"), release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	sumsAsset := release.FindAsset(checksumsAssetName)
+	if sumsAsset == nil {
+		return "", fmt.Errorf(i18n.T("selfupdate.no_checksums"), release.TagName, checksumsAssetName)
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("selfupdate.exe_path_failed"), err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+
+	expected, err := fetchChecksum(release.AssetURL(sumsAsset), assetName)
+	if err != nil {
+		return "", err
+	}
+
+	// 下载到同一目录，保证后续 rename 在同一文件系统内完成（原子替换）
+	newPath := filepath.Join(filepath.Dir(exePath), "."+filepath.Base(exePath)+".new")
+	if err := downloadVerified(release.AssetURL(asset), newPath, asset.Size, expected); err != nil {
+		os.Remove(newPath)
+		return "", err
+	}
+
+	if err := installExecutable(exePath, newPath); err != nil {
+		return "", err
+	}
+	return exePath, nil
+}
+
+// installExecutable 确认 newPath 可以运行后用它替换 exePath
+// 任一步骤失败时删除 newPath，exePath 保持原样
+func installExecutable(exePath, newPath string) error {
+	if err := verifyExecutable(newPath); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+	if err := replaceExecutable(exePath, newPath); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+	return nil
+}
+
+// CleanupOldExecutable 清理上次更新遗留的旧版本文件
+// Windows 无法删除正在运行的程序，只能在下次启动时清理
+func CleanupOldExecutable() {
+	exePath, err := os.Executable()
+	if err != nil {
+		return
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+	os.Remove(oldExecutablePath(exePath))
+}
+
+// oldExecutablePath 返回替换过程中旧版本文件的暂存路径
+func oldExecutablePath(exePath string) string {
+	return filepath.Join(filepath.Dir(exePath), "."+filepath.Base(exePath)+".old")
+}
+
+// fetchChecksum 下载校验文件并返回指定附件的 SHA-256 值
+func fetchChecksum(sumsURL, assetName string) (string, error) {
+	client := &http.Client{Timeout: 30 * time.Second, Transport: debuglog.Transport()}
+	resp, err := client.Get(sumsURL)
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("selfupdate.checksums_failed"), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf(i18n.T("selfupdate.checksums_status"), resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, 1<<20))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum 二进制模式下文件名带 "*" 前缀
+		if strings.TrimPrefix(fields[1], "*") == assetName {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf(i18n.T("selfupdate.checksum_missing"), assetName)
+}
+
+// downloadVerified 下载文件到 dest，边下载边计算 SHA-256 并显示进度
+func downloadVerified(assetURL, dest string, size int64, expected string) error {
+	client := &http.Client{Timeout: 10 * time.Minute, Transport: debuglog.Transport()}
+	resp, err := client.Get(assetURL)
+	if err != nil {
+		return fmt.Errorf(i18n.T("selfupdate.download_failed"), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(i18n.T("selfupdate.download_status"), resp.StatusCode)
+	}
+	if resp.ContentLength > 0 {
+		size = resp.ContentLength
+	}
+
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf(i18n.T("selfupdate.temp_failed"), err)
+	}
+
+	hasher := sha256.New()
+	progress := &progressWriter{total: size}
+	_, copyErr := io.Copy(io.MultiWriter(f, hasher, progress), resp.Body)
+	closeErr := f.Close()
+	progress.finish()
+	if copyErr != nil {
+		return fmt.Errorf(i18n.T("selfupdate.interrupted"), copyErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf(i18n.T("selfupdate.write_failed"), closeErr)
+	}
+
+	if actual := hexSum(hasher); actual != expected {
+		return fmt.Errorf(i18n.T("selfupdate.checksum_mismatch"), expected, actual)
+	}
+	return nil
+}
+
+// hexSum 返回哈希值的十六进制表示
+func hexSum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// verifyExecutable 运行新版本的 --version，确认二进制可以在当前系统上正常启动
+func verifyExecutable(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := exec.CommandContext(ctx, path, "--version").Run(); err != nil {
+		return fmt.Errorf(i18n.T("selfupdate.verify_failed"), err)
+	}
+	return nil
+}
+
+// replaceExecutable 用 newPath 替换 exePath，失败时回滚
+// 先把旧文件改名暂存（Windows 允许重命名正在运行的程序），再把新文件移入原位置
+func replaceExecutable(exePath, newPath string) error {
+	oldPath := oldExecutablePath(exePath)
+	os.Remove(oldPath)
+
+	if err := os.Rename(exePath, oldPath); err != nil {
+		return fmt.Errorf(i18n.T("selfupdate.replace_failed"), err)
+	}
+	if err := os.Rename(newPath, exePath); err != nil {
+		if rbErr := os.Rename(oldPath, exePath); rbErr != nil {
+			return fmt.Errorf(i18n.T("selfupdate.rollback_failed"), oldPath, exePath, err, rbErr)
+		}
+		return fmt.Errorf(i18n.T("selfupdate.rolled_back"), err)
+	}
+
+	// 非 Windows 平台可以直接删除旧文件；Windows 在下次启动时由 CleanupOldExecutable 清理
+	if runtime.GOOS != "windows" {
+		os.Remove(oldPath)
+	}
+	return nil
+}
+
+// progressWriter 在终端同一行显示下载进度
+type progressWriter struct {
+	total   int64
+	written int64
+	last    time.Time
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if time.Since(p.last) >= 200*time.Millisecond {
+		p.last = time.Now()
+		p.print()
+	}
+	return len(b), nil
+}
+
+func (p *progressWriter) print() {
+	const mb = 1024 * 1024
+	if p.total > 0 {
+		fmt.Fprintf(out, "\r  %s %s", symbol("→", "->"),
+			i18n.T("selfupdate.progress", p.written*100/p.total, float64(p.written)/mb, float64(p.total)/mb))
+	} else {
+		fmt.Fprintf(out, "\r  %s %s", symbol("→", "->"), i18n.T("selfupdate.progress_unknown", float64(p.written)/mb))
+	}
+}
+
+// finish 输出最终进度并换行
+func (p *progressWriter) finish() {
+	p.print()
+	fmt.Fprintln(out)
+}
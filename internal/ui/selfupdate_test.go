@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeScript 写入一个以指定退出码结束的 shell 脚本，用作模拟的可执行文件
+func writeScript(t *testing.T, path string, exitCode string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on Windows")
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexit "+exitCode+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestAssetName(t *testing.T) {
+	tests := []struct {
+		goos, goarch, want string
+	}{
+		{"linux", "amd64", "opencode-dmxapi-v1.2.0-linux-amd64"},
+		{"linux", "arm64", "opencode-dmxapi-v1.2.0-linux-arm64"},
+		{"darwin", "arm64", "opencode-dmxapi-v1.2.0-macos-arm64"},
+		{"windows", "amd64", "opencode-dmxapi-v1.2.0-windows-amd64.exe"},
+	}
+	for _, tt := range tests {
+		if got := AssetName("v1.2.0", tt.goos, tt.goarch); got != tt.want {
+			t.Errorf("AssetName(%s, %s) = %q, want %q", tt.goos, tt.goarch, got, tt.want)
+		}
+	}
+}
+
+func TestInstallExecutable(t *testing.T) {
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "opencode-dmxapi")
+	newPath := filepath.Join(dir, ".opencode-dmxapi.new")
+	writeScript(t, exePath, "1")
+	writeScript(t, newPath, "0")
+	want := readFile(t, newPath)
+
+	if err := installExecutable(exePath, newPath); err != nil {
+		t.Fatalf("installExecutable error: %v", err)
+	}
+	if got := readFile(t, exePath); got != want {
+		t.Errorf("executable = %q, want the new version", got)
+	}
+	for _, p := range []string{newPath, oldExecutablePath(exePath)} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s left behind", p)
+		}
+	}
+}
+
+func TestInstallExecutableVerifyFails(t *testing.T) {
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "opencode-dmxapi")
+	newPath := filepath.Join(dir, ".opencode-dmxapi.new")
+	writeScript(t, exePath, "0")
+	writeScript(t, newPath, "3")
+	original := readFile(t, exePath)
+
+	if err := installExecutable(exePath, newPath); err == nil {
+		t.Fatal("installExecutable succeeded with a binary that cannot run")
+	}
+	if got := readFile(t, exePath); got != original {
+		t.Errorf("executable changed to %q", got)
+	}
+	for _, p := range []string{newPath, oldExecutablePath(exePath)} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s left behind", p)
+		}
+	}
+}
+
+func TestReplaceExecutableRollback(t *testing.T) {
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "opencode-dmxapi")
+	if err := os.WriteFile(exePath, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// 新文件不存在，移入原位置失败后应恢复旧文件
+	err := replaceExecutable(exePath, filepath.Join(dir, "missing"))
+	if err == nil {
+		t.Fatal("replaceExecutable succeeded without a new file")
+	}
+	if got := readFile(t, exePath); got != "old" {
+		t.Errorf("executable = %q after rollback, want old", got)
+	}
+	if _, err := os.Stat(oldExecutablePath(exePath)); !os.IsNotExist(err) {
+		t.Error("backup of the old executable left behind after rollback")
+	}
+}
+
+// newReleaseServer 提供二进制附件和校验文件
+func newReleaseServer(t *testing.T, binary, sums string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bin", func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, binary) })
+	mux.HandleFunc("/checksums.txt", func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, sums) })
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestChecksumVerification(t *testing.T) {
+	SetOutput(io.Discard)
+	t.Cleanup(func() { SetOutput(os.Stdout) })
+
+	const binary = "new version"
+	sum := sha256.Sum256([]byte(binary))
+	good := hex.EncodeToString(sum[:])
+	asset := AssetName("v1.2.0", "linux", "amd64")
+
+	tests := []struct {
+		name    string
+		sums    string
+		wantErr string // 错误信息中应包含的内容，为空表示成功
+	}{
+		{"text mode", good + "  " + asset + "\n", ""},
+		{"binary mode upper case", strings.ToUpper(good) + " *" + asset + "\n", ""},
+		{"among other entries", "abc  other-file\n\n" + good + "  " + asset + "\n", ""},
+		{"missing entry", good + "  " + asset + ".exe\n", asset},
+		{"mismatch", strings.Repeat("0", 64) + "  " + asset + "\n", good},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newReleaseServer(t, binary, tt.sums)
+			dest := filepath.Join(t.TempDir(), "new")
+			expected, err := fetchChecksum(srv.URL+"/checksums.txt", asset)
+			if err == nil {
+				err = downloadVerified(srv.URL+"/bin", dest, int64(len(binary)), expected)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("error = %v", err)
+				}
+				if got := readFile(t, dest); got != binary {
+					t.Errorf("downloaded %q", got)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	srv := newReleaseServer(t, binary, "")
+	if _, err := fetchChecksum(srv.URL+"/missing", asset); err == nil {
+		t.Error("fetchChecksum succeeded on HTTP 404")
+	}
+}
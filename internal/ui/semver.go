@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"dmxapi-config/internal/i18n"
+)
+
+// SemVer 语义化版本号（https://semver.org），构建元数据（+xxx）不参与比较
+type SemVer struct {
+	Major int
+	Minor int
+	Patch int
+	Pre   []string // 预发布标识，如 "beta.2" 拆分为 ["beta", "2"]
+}
+
+// ParseVersion 解析版本号，允许带 "v" 前缀，缺省的次版本号和修订号视为 0
+func ParseVersion(s string) (SemVer, error) {
+	var v SemVer
+	raw := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(raw, '+'); i >= 0 {
+		raw = raw[:i]
+	}
+	core := raw
+	if i := strings.IndexByte(raw, '-'); i >= 0 {
+		core = raw[:i]
+		v.Pre = strings.Split(raw[i+1:], ".")
+		// 预发布标识不能为空（如 "1.2.0-" 或 "1.2.0-beta..1"）
+		if slices.Contains(v.Pre, "") {
+			return v, fmt.Errorf(i18n.T("semver.invalid"), s)
+		}
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return v, fmt.Errorf(i18n.T("semver.invalid"), s)
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf(i18n.T("semver.invalid"), s)
+		}
+		*nums[i] = n
+	}
+	return v, nil
+}
+
+// IsPrerelease 是否为预发布版本（如 2.1.0-beta.1）
+func (v SemVer) IsPrerelease() bool {
+	return len(v.Pre) > 0
+}
+
+// String 返回不带 "v" 前缀的版本号
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.IsPrerelease() {
+		s += "-" + strings.Join(v.Pre, ".")
+	}
+	return s
+}
+
+// Compare 比较两个版本号：v < o 返回 -1，相等返回 0，v > o 返回 1
+// 预发布版本低于对应的正式版本（2.1.0-beta < 2.1.0）
+func (v SemVer) Compare(o SemVer) int {
+	if c := compareInt(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	switch {
+	case !v.IsPrerelease() && !o.IsPrerelease():
+		return 0
+	case !v.IsPrerelease():
+		return 1
+	case !o.IsPrerelease():
+		return -1
+	}
+
+	for i := 0; i < len(v.Pre) && i < len(o.Pre); i++ {
+		if c := comparePreIdent(v.Pre[i], o.Pre[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(v.Pre), len(o.Pre))
+}
+
+// comparePreIdent 比较单个预发布标识：数字按数值比较且低于字母标识，字母按字典序比较
+func comparePreIdent(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInt(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
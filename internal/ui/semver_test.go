@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want SemVer
+		ok   bool
+	}{
+		{"1.2.3", SemVer{1, 2, 3, nil}, true},
+		{"v2.0.0", SemVer{2, 0, 0, nil}, true},
+		{" v1.4 ", SemVer{1, 4, 0, nil}, true},
+		{"3", SemVer{3, 0, 0, nil}, true},
+		{"1.2.0-beta.2", SemVer{1, 2, 0, []string{"beta", "2"}}, true},
+		{"1.2.0-rc.1+build.5", SemVer{1, 2, 0, []string{"rc", "1"}}, true},
+		{"1.2.0+build.5", SemVer{1, 2, 0, nil}, true},
+		{"", SemVer{}, false},
+		{"dev", SemVer{}, false},
+		{"1.2.3.4", SemVer{}, false},
+		{"1..3", SemVer{}, false},
+		{"1.-2.3", SemVer{}, false},
+		{"1.2.x", SemVer{}, false},
+		{"1.2.0-", SemVer{}, false},
+		{"1.2.0-beta..1", SemVer{}, false},
+		{"1.2.0-beta.", SemVer{}, false},
+		{"1.2.0-.1", SemVer{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseVersion(%q) error = %v, want ok = %v", tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseVersion(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// 按从低到高排列
+	ordered := []string{
+		"0.9.9",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0",
+		"1.2.0-beta.2",
+		"1.2.0-beta.10",
+		"1.2.0-rc.1",
+		"1.2.0",
+		"1.10.0",
+		"2.0.0",
+	}
+	for i, a := range ordered {
+		va, err := ParseVersion(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, b := range ordered {
+			vb, _ := ParseVersion(b)
+			want := compareInt(i, j)
+			if got := va.Compare(vb); got != want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+
+	// 构建元数据和 "v" 前缀不影响比较
+	a, _ := ParseVersion("v1.2.0+build.1")
+	b, _ := ParseVersion("1.2.0+build.2")
+	if a.Compare(b) != 0 {
+		t.Errorf("Compare(v1.2.0+build.1, 1.2.0+build.2) = %d, want 0", a.Compare(b))
+	}
+}
+
+func TestSelectLatest(t *testing.T) {
+	releases := []Release{
+		{TagName: "v1.3.0-beta.10", Prerelease: true},
+		{TagName: "v1.3.0-beta.2", Prerelease: true},
+		{TagName: "v1.4.0", Draft: true},
+		{TagName: "nightly"},
+		{TagName: "v1.2.0-"},
+		{TagName: "v1.2.1"},
+		{TagName: "v1.2.10-rc.1"}, // 版本号带预发布标识但未标记 prerelease
+		{TagName: "v1.2.9", Prerelease: true},
+		{TagName: "v1.2.0"},
+	}
+	tests := []struct {
+		releases []Release
+		channel  Channel
+		want     string
+	}{
+		{releases, ChannelStable, "v1.2.1"},
+		{releases, ChannelBeta, "v1.3.0-beta.10"},
+		{append(releases, Release{TagName: "v1.3.0"}), ChannelBeta, "v1.3.0"},
+		{releases[:5], ChannelStable, ""},
+		{nil, ChannelBeta, ""},
+	}
+	for _, tt := range tests {
+		got := selectLatest(tt.releases, tt.channel)
+		var tag string
+		if got != nil {
+			tag = got.TagName
+		}
+		if tag != tt.want {
+			t.Errorf("selectLatest(%d releases, %s) = %q, want %q", len(tt.releases), tt.channel, tag, tt.want)
+		}
+	}
+}
@@ -2,66 +2,304 @@ package ui
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/i18n"
 )
 
 const (
 	cnbReleasesAPI = "https://cnb.cool/dmxapi/opencode_dmxapi/-/releases"
-	downloadURL    = "https://cnb.cool/dmxapi/opencode_dmxapi/-/releases"
+
+	// ReleasesPageURL 发布页面地址，用于提示用户手动下载
+	ReleasesPageURL = "https://cnb.cool/dmxapi/opencode_dmxapi/-/releases"
+
+	// updateCacheFile 更新检查结果缓存文件名（位于状态目录）
+	updateCacheFile = "update-check.json"
+	// updateCacheTTL 检查成功时缓存有效期
+	updateCacheTTL = 24 * time.Hour
+	// updateFailureTTL 检查失败（如无网络）时的缓存有效期，避免每次启动都等待超时
+	updateFailureTTL = time.Hour
 )
 
+// Channel 更新通道
+type Channel string
+
+const (
+	ChannelStable Channel = "stable" // 仅正式版本
+	ChannelBeta   Channel = "beta"   // 包含预发布版本
+)
+
+// ParseChannel 解析更新通道名称
+func ParseChannel(s string) (Channel, error) {
+	switch Channel(strings.ToLower(strings.TrimSpace(s))) {
+	case ChannelStable, "":
+		return ChannelStable, nil
+	case ChannelBeta:
+		return ChannelBeta, nil
+	}
+	return "", fmt.Errorf(i18n.T("updater.unknown_channel"), s)
+}
+
 // UpdateResult 存储版本检查结果
 type UpdateResult struct {
-	HasUpdate     bool
-	LatestVersion string
-	DownloadURL   string
+	HasUpdate     bool   `json:"has_update"`
+	LatestVersion string `json:"latest_version,omitempty"`
+	DownloadURL   string `json:"download_url,omitempty"`
+	ReleaseNotes  string `json:"release_notes,omitempty"` // 更新说明摘要，可能为空
+}
+
+// updateCache 更新检查缓存内容
+// 缓存与当前程序版本和更新通道绑定，任一变化即视为失效
+type updateCache struct {
+	CheckedAt time.Time    `json:"checked_at"`
+	Version   string       `json:"version"`
+	Channel   Channel      `json:"channel"`
+	Failed    bool         `json:"failed"`
+	Result    UpdateResult `json:"result"`
+}
+
+// UpdateCheckDisabled 是否通过环境变量 DMXAPI_NO_UPDATE_CHECK 关闭了启动时的更新检查
+func UpdateCheckDisabled() bool {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv("DMXAPI_NO_UPDATE_CHECK")))
+	return v != "" && v != "0" && v != "false"
+}
+
+// Release CNB 发布版本信息
+type Release struct {
+	TagName    string         `json:"tag_name"`
+	Name       string         `json:"name"`
+	Body       string         `json:"body"`
+	Draft      bool           `json:"draft"`
+	Prerelease bool           `json:"prerelease"`
+	Assets     []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset 发布附件
+type ReleaseAsset struct {
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// Version 解析发布标签对应的版本号
+func (r *Release) Version() (SemVer, error) {
+	return ParseVersion(r.TagName)
+}
+
+// NotesExcerpt 提取更新说明中的前 maxItems 条更新条目
+// 发布说明由 git-cliff 生成，条目以 "- " 开头，遇到条目之后的分隔线（---）即停止，
+// 避免把下载表格和相关链接也算作更新内容
+func (r *Release) NotesExcerpt(maxItems int) string {
+	var items []string
+	for _, line := range strings.Split(r.Body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "---" && len(items) > 0 {
+			break
+		}
+		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
+			items = append(items, "- "+strings.TrimSpace(line[2:]))
+			if len(items) >= maxItems {
+				break
+			}
+		}
+	}
+	return strings.Join(items, "\n")
+}
+
+// FindAsset 按文件名查找附件
+func (r *Release) FindAsset(name string) *ReleaseAsset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// AssetURL 返回附件下载地址
+// API 未返回下载地址时，按 CNB 的 /-/releases/download/{tag}/{name} 规则拼接
+func (r *Release) AssetURL(asset *ReleaseAsset) string {
+	if asset.BrowserDownloadURL != "" {
+		return asset.BrowserDownloadURL
+	}
+	return fmt.Sprintf("%s/download/%s/%s", cnbReleasesAPI, r.TagName, asset.Name)
 }
 
 // CheckForUpdateAsync 异步检查 CNB 最新版本，通过 channel 返回结果
-// 失败时发送 UpdateResult{HasUpdate: false}
-func CheckForUpdateAsync() <-chan UpdateResult {
+// 缓存未过期时直接返回缓存结果，不发起网络请求；失败时发送 UpdateResult{HasUpdate: false}
+func CheckForUpdateAsync(channel Channel) <-chan UpdateResult {
 	ch := make(chan UpdateResult, 1)
 	go func() {
-		ch <- checkUpdate()
+		if cached, ok := loadUpdateCache(channel); ok {
+			ch <- cached
+			return
+		}
+		result, err := checkUpdate(channel)
+		saveUpdateCache(channel, result, err != nil)
+		ch <- result
 	}()
 	return ch
 }
 
-func checkUpdate() UpdateResult {
-	client := &http.Client{Timeout: 5 * time.Second}
+// updateCachePath 返回更新检查缓存文件路径
+func updateCachePath() (string, error) {
+	dir, err := config.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, updateCacheFile), nil
+}
+
+// loadUpdateCache 读取未过期的更新检查缓存
+func loadUpdateCache(channel Channel) (UpdateResult, bool) {
+	path, err := updateCachePath()
+	if err != nil {
+		return UpdateResult{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return UpdateResult{}, false
+	}
+	var cache updateCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return UpdateResult{}, false
+	}
+	if cache.Version != Version || cache.Channel != channel {
+		return UpdateResult{}, false
+	}
+	ttl := updateCacheTTL
+	if cache.Failed {
+		ttl = updateFailureTTL
+	}
+	if time.Since(cache.CheckedAt) > ttl {
+		return UpdateResult{}, false
+	}
+	return cache.Result, true
+}
+
+// saveUpdateCache 保存更新检查结果，写入失败时静默忽略（缓存只是优化）
+func saveUpdateCache(channel Channel, result UpdateResult, failed bool) {
+	path, err := updateCachePath()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(updateCache{
+		CheckedAt: time.Now(),
+		Version:   Version,
+		Channel:   channel,
+		Failed:    failed,
+		Result:    result,
+	}, "", "  ")
+	if err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0644)
+}
+
+// selectLatest 从发布列表中选出指定通道的最高版本
+// 跳过草稿和无法解析的标签；stable 通道同时跳过预发布版本（标记为 prerelease 或版本号带预发布标识）
+func selectLatest(releases []Release, channel Channel) *Release {
+	var latest *Release
+	var latestVer SemVer
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft {
+			continue
+		}
+		v, err := r.Version()
+		if err != nil {
+			continue
+		}
+		if channel != ChannelBeta && (r.Prerelease || v.IsPrerelease()) {
+			continue
+		}
+		if latest == nil || v.Compare(latestVer) > 0 {
+			latest, latestVer = r, v
+		}
+	}
+	return latest
+}
+
+// IsNewerThanCurrent 判断发布版本是否高于当前程序版本
+// 当前版本无法解析（如本地开发构建）时始终返回 false，避免误报
+func IsNewerThanCurrent(r *Release) bool {
+	current, err := ParseVersion(Version)
+	if err != nil {
+		return false
+	}
+	v, err := r.Version()
+	if err != nil {
+		return false
+	}
+	return v.Compare(current) > 0
+}
+
+// fetchReleases 从 CNB 获取发布列表（按发布时间倒序）
+func fetchReleases(timeout time.Duration) ([]Release, error) {
+	client := &http.Client{Timeout: timeout, Transport: debuglog.Transport()}
 	req, err := http.NewRequest("GET", cnbReleasesAPI, nil)
 	if err != nil {
-		return UpdateResult{}
+		return nil, fmt.Errorf(i18n.T("common.create_request_failed"), err)
 	}
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return UpdateResult{}
+		return nil, fmt.Errorf(i18n.T("updater.fetch_failed"), err)
 	}
 	defer resp.Body.Close()
 
-	var releases []struct {
-		TagName string `json:"tag_name"`
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(i18n.T("updater.fetch_status"), resp.StatusCode)
 	}
+
+	var releases []Release
 	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
-		return UpdateResult{}
+		return nil, fmt.Errorf(i18n.T("updater.parse_failed"), err)
 	}
+	return releases, nil
+}
+
+// FetchLatestRelease 获取指定通道的最新发布版本
+func FetchLatestRelease(channel Channel) (*Release, error) {
+	releases, err := fetchReleases(15 * time.Second)
+	if err != nil {
+		return nil, err
+	}
+	latest := selectLatest(releases, channel)
+	if latest == nil {
+		return nil, fmt.Errorf(i18n.T("updater.no_release"), channel)
+	}
+	return latest, nil
+}
 
-	if len(releases) == 0 {
-		return UpdateResult{}
+// checkUpdate 请求发布列表并判断是否有新版本，网络或解析失败时返回错误
+func checkUpdate(channel Channel) (UpdateResult, error) {
+	releases, err := fetchReleases(5 * time.Second)
+	if err != nil {
+		return UpdateResult{}, err
 	}
 
-	latestTag := strings.TrimPrefix(releases[0].TagName, "v")
-	if latestTag == "" || latestTag == Version {
-		return UpdateResult{}
+	latest := selectLatest(releases, channel)
+	if latest == nil || !IsNewerThanCurrent(latest) {
+		return UpdateResult{}, nil
 	}
 
+	v, _ := latest.Version()
 	return UpdateResult{
 		HasUpdate:     true,
-		LatestVersion: latestTag,
-		DownloadURL:   downloadURL,
-	}
+		LatestVersion: v.String(),
+		DownloadURL:   ReleasesPageURL,
+		ReleaseNotes:  latest.NotesExcerpt(5),
+	}, nil
 }
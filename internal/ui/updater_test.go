@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setupStateDir 将 HOME 指向临时目录，返回更新检查缓存文件路径
+func setupStateDir(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	path, err := updateCachePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeUpdateCache 直接写入缓存文件，用于构造过期或其他版本的缓存
+func writeUpdateCache(t *testing.T, path string, cache updateCache) {
+	t.Helper()
+	data, err := json.Marshal(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateCacheRoundTrip(t *testing.T) {
+	setupStateDir(t)
+	if _, ok := loadUpdateCache(ChannelStable); ok {
+		t.Fatal("loadUpdateCache without a cache file returned ok")
+	}
+
+	want := UpdateResult{HasUpdate: true, LatestVersion: "v9.9.9", DownloadURL: "https://example.com/dl", ReleaseNotes: "- fix"}
+	saveUpdateCache(ChannelStable, want, false)
+	got, ok := loadUpdateCache(ChannelStable)
+	if !ok || got != want {
+		t.Errorf("loadUpdateCache = %+v, %v, want %+v, true", got, ok, want)
+	}
+
+	// 切换通道后缓存失效
+	if _, ok := loadUpdateCache(ChannelBeta); ok {
+		t.Error("loadUpdateCache(beta) used a cache saved for stable")
+	}
+}
+
+func TestUpdateCacheTTL(t *testing.T) {
+	path := setupStateDir(t)
+	result := UpdateResult{HasUpdate: true, LatestVersion: "v9.9.9"}
+	tests := []struct {
+		name   string
+		age    time.Duration
+		failed bool
+		want   bool
+	}{
+		{"fresh success", time.Minute, false, true},
+		{"success within 24h", 23 * time.Hour, false, true},
+		{"success after 24h", 25 * time.Hour, false, false},
+		{"fresh failure", time.Minute, true, true},
+		{"failure within 1h", 59 * time.Minute, true, true},
+		{"failure after 1h", 61 * time.Minute, true, false},
+	}
+	for _, tt := range tests {
+		writeUpdateCache(t, path, updateCache{
+			CheckedAt: time.Now().Add(-tt.age),
+			Version:   Version,
+			Channel:   ChannelStable,
+			Failed:    tt.failed,
+			Result:    result,
+		})
+		if _, ok := loadUpdateCache(ChannelStable); ok != tt.want {
+			t.Errorf("%s: loadUpdateCache ok = %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+}
+
+func TestUpdateCacheInvalidation(t *testing.T) {
+	path := setupStateDir(t)
+	tests := []struct {
+		name    string
+		version string
+		channel Channel
+	}{
+		{"older program version", "0.0.1", ChannelStable},
+		{"other channel", Version, ChannelBeta},
+		{"missing version", "", ChannelStable},
+	}
+	for _, tt := range tests {
+		writeUpdateCache(t, path, updateCache{
+			CheckedAt: time.Now(),
+			Version:   tt.version,
+			Channel:   tt.channel,
+			Result:    UpdateResult{HasUpdate: true, LatestVersion: "v9.9.9"},
+		})
+		if _, ok := loadUpdateCache(ChannelStable); ok {
+			t.Errorf("%s: loadUpdateCache used a cache for version %q channel %q", tt.name, tt.version, tt.channel)
+		}
+	}
+
+	// 损坏的缓存文件视为没有缓存
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadUpdateCache(ChannelStable); ok {
+		t.Error("loadUpdateCache used a corrupt cache file")
+	}
+}
+
+func TestUpdateCheckDisabled(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", false},
+		{"0", false},
+		{"false", false},
+		{"FALSE", false},
+		{" false ", false},
+		{"1", true},
+		{"true", true},
+		{"yes", true},
+	}
+	for _, tt := range tests {
+		t.Setenv("DMXAPI_NO_UPDATE_CHECK", tt.value)
+		if got := UpdateCheckDisabled(); got != tt.want {
+			t.Errorf("UpdateCheckDisabled with DMXAPI_NO_UPDATE_CHECK=%q = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
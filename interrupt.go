@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/i18n"
+	"dmxapi-config/internal/input"
+	"dmxapi-config/internal/support"
+	"dmxapi-config/internal/ui"
+)
+
+// interruptExitCode 被 Ctrl+C 中断时的退出码（与 shell 约定一致：128 + SIGINT）
+const interruptExitCode = 130
+
+// interruptGrace 收到中断信号后等待主流程自行结束的时间
+// 正在进行的请求会立即取消，主流程通常很快就能走到退出；阻塞在输入等无法取消的操作时超时后直接退出
+const interruptGrace = 500 * time.Millisecond
+
+var (
+	// interruptCtx 配置向导中所有网络请求使用的 context，收到中断信号时取消
+	interruptCtx, cancelInterrupt = context.WithCancel(context.Background())
+
+	// writeMu 写入配置文件期间持有，中断处理会等待写入完成，保证 auth.json 和 opencode.json 不会只写入一部分
+	writeMu sync.Mutex
+
+	// exitingCode 已进入退出流程（等待按键）时的退出码，-1 表示尚未退出
+	exitingCode atomic.Int32
+
+	// configWritten 配置文件是否已全部写入（在 writeMu 保护下修改）
+	configWritten bool
+
+	interruptOnce sync.Once
+)
+
+func init() {
+	exitingCode.Store(-1)
+}
+
+// handleInterrupts 注册 Ctrl+C / SIGTERM 处理：取消进行中的请求，等待配置写入完成后退出
+func handleInterrupts() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		s := <-sig
+		debuglog.Printf("main", "received %s, cancelling in-flight requests", s)
+		// 配置已完成、正在等待按键退出时，中断即正常退出
+		if code := exitingCode.Load(); code >= 0 {
+			os.Exit(int(code))
+		}
+		cancelInterrupt()
+		time.Sleep(interruptGrace)
+		writeMu.Lock()
+		interrupted()
+	}()
+}
+
+// stopIfInterrupted 已收到中断信号时结束程序，在每个可取消的操作之后调用
+func stopIfInterrupted() {
+	if interruptCtx.Err() != nil {
+		interrupted()
+	}
+}
+
+// interrupted 记录中断并以退出码 130 结束程序（不等待按键）
+func interrupted() {
+	interruptOnce.Do(func() {
+		if configWritten {
+			ui.PrintWarning(i18n.T("main.interrupted_written"))
+		} else {
+			ui.PrintWarning(i18n.T("main.interrupted"))
+		}
+		result.addError(codeCancelled, input.ErrUserCancelled)
+		result.finish(interruptExitCode)
+		support.SaveLastRunReport(result)
+		result.emit(interruptExitCode)
+		os.Exit(interruptExitCode)
+	})
+}
+
+// writeConfiguration 在 writeMu 保护下依次执行写入操作，写入开始后的中断会等到写入结束再生效
+// 开始写入前已被中断时不写入任何文件；write 返回错误时视为未写入，
+// 错误原样返回，由调用方在释放 writeMu 之后报告并退出（不能在持有 writeMu 时退出）
+func writeConfiguration(write func() error) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	if interruptCtx.Err() != nil {
+		interrupted()
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	configWritten = true
+	return nil
+}
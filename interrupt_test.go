@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/i18n"
+)
+
+// interruptScenarioEnv 子进程中要运行的中断场景，见 TestInterruptScenario
+const interruptScenarioEnv = "DMXAPI_TEST_INTERRUPT_SCENARIO"
+
+// TestInterruptScenario 在子进程中运行中断场景（中断会结束进程，无法在测试进程中直接验证）
+// 场景完成后写入标记文件，由 TestInterruptOrdering 检查退出码、输出和标记文件
+func TestInterruptScenario(t *testing.T) {
+	scenario := os.Getenv(interruptScenarioEnv)
+	if scenario == "" {
+		t.Skip("only runs as a subprocess of TestInterruptOrdering")
+	}
+	dir := os.Getenv("HOME")
+	mark := func(name string) { os.WriteFile(filepath.Join(dir, name), nil, 0600) }
+	interrupt := func() {
+		p, _ := os.FindProcess(os.Getpid())
+		p.Signal(os.Interrupt)
+	}
+
+	handleInterrupts()
+	switch scenario {
+	case "before":
+		// 写入前已收到中断：不执行写入
+		cancelInterrupt()
+		writeConfiguration(func() error {
+			mark("written")
+			return nil
+		})
+	case "during":
+		// 写入过程中收到中断：等写入完成后再退出
+		writeConfiguration(func() error {
+			interrupt()
+			time.Sleep(3 * interruptGrace)
+			mark("written")
+			return nil
+		})
+	case "failed":
+		// 写入失败：释放 writeMu 后才退出，并报告配置未修改
+		err := writeConfiguration(func() error {
+			interrupt()
+			time.Sleep(3 * interruptGrace)
+			return errors.New("disk full")
+		})
+		if err != nil {
+			mark("error returned")
+		}
+	}
+	time.Sleep(5 * time.Second)
+	mark("not interrupted")
+}
+
+func TestInterruptOrdering(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("os.Interrupt cannot be sent to a process on Windows")
+	}
+	tests := []struct {
+		scenario string
+		message  string
+		marks    []string
+	}{
+		{"before", i18n.T("main.interrupted"), nil},
+		{"during", i18n.T("main.interrupted_written"), []string{"written"}},
+		{"failed", i18n.T("main.interrupted"), []string{"error returned"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scenario, func(t *testing.T) {
+			home := t.TempDir()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestInterruptScenario$")
+			cmd.Env = append(os.Environ(), interruptScenarioEnv+"="+tt.scenario, "HOME="+home, "USERPROFILE="+home)
+			out, err := cmd.Output()
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != interruptExitCode {
+				t.Fatalf("exit = %v, want code %d\n%s", err, interruptExitCode, out)
+			}
+			if !strings.Contains(string(out), tt.message) {
+				t.Errorf("output does not contain %q:\n%s", tt.message, out)
+			}
+			entries, _ := os.ReadDir(home)
+			var marks []string
+			for _, e := range entries {
+				if !e.IsDir() {
+					marks = append(marks, e.Name())
+				}
+			}
+			if strings.Join(marks, ",") != strings.Join(tt.marks, ",") {
+				t.Errorf("marks = %v, want %v", marks, tt.marks)
+			}
+		})
+	}
+}
+
+func TestWriteConfigurationError(t *testing.T) {
+	t.Cleanup(func() { configWritten = false })
+	want := errors.New("disk full")
+	if err := writeConfiguration(func() error { return want }); err != want {
+		t.Fatalf("writeConfiguration = %v, want %v", err, want)
+	}
+	if configWritten {
+		t.Error("configWritten set after a failed write")
+	}
+	if !writeMu.TryLock() {
+		t.Fatal("writeMu still held after writeConfiguration returned")
+	}
+	writeMu.Unlock()
+
+	if err := writeConfiguration(func() error { return nil }); err != nil || !configWritten {
+		t.Errorf("writeConfiguration = %v, configWritten = %v", err, configWritten)
+	}
+}
+
+func TestWriteAuthAndConfigRestoresAuth(t *testing.T) {
+	const key = "sk-interrupttestkey123456"
+	tests := []struct {
+		name  string
+		prior string // 写入前的 auth.json，为空表示不存在
+	}{
+		{"existing auth", `{"openai": {"type": "oauth", "access": "a", "refresh": "r", "expires": 1}}`},
+		{"no auth", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("USERPROFILE", home)
+			config.SetMessageOutput(io.Discard)
+			t.Cleanup(func() { config.SetMessageOutput(os.Stdout) })
+
+			authPath, _ := config.GetAuthPath()
+			if tt.prior != "" {
+				os.MkdirAll(filepath.Dir(authPath), 0755)
+				os.WriteFile(authPath, []byte(tt.prior), 0600)
+			}
+			// opencode.json 所在位置是目录，写入必然失败
+			configPath, _ := config.GetConfigPath()
+			if err := os.MkdirAll(configPath, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := config.NewDMXAPIConfig("https://www.dmxapi.cn", key, []string{"DeepSeek-V3"})
+			_, _, err := writeAuthAndConfig(3, config.NewWriter(), config.GetProviderIDs(cfg), key, cfg)
+			var wf *writeFailure
+			if !errors.As(err, &wf) || wf.code != codeConfigWriteFailed {
+				t.Fatalf("writeAuthAndConfig error = %v, want a %s failure", err, codeConfigWriteFailed)
+			}
+
+			data, readErr := os.ReadFile(authPath)
+			if tt.prior == "" {
+				if !os.IsNotExist(readErr) {
+					t.Errorf("auth.json left behind: %s", data)
+				}
+				return
+			}
+			if string(data) != tt.prior {
+				t.Errorf("auth.json = %s, want the previous contents", data)
+			}
+		})
+	}
+}
@@ -2,40 +2,719 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"time"
 
 	"dmxapi-config/internal/api"
 	"dmxapi-config/internal/auth"
 	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/debuglog"
+	"dmxapi-config/internal/i18n"
 	"dmxapi-config/internal/input"
+	"dmxapi-config/internal/support"
+	"dmxapi-config/internal/template"
 	"dmxapi-config/internal/ui"
 )
 
+// 命令行参数
+var (
+	flagVersion = flag.Bool("version", false, "flag.version")
+	flagChannel = flag.String("channel", "stable", "flag.channel")
+	flagOffline = flag.Bool("offline", false, "flag.offline")
+	flagLang    = flag.String("lang", "", "flag.lang")
+	flagRetries = flag.Int("retries", api.DefaultRetryPolicy().MaxRetries, "flag.retries")
+	flagOutput  = flag.String("output", string(ui.OutputText), "flag.output")
+	flagVerbose = flag.Bool("verbose", false, "flag.verbose")
+	flagLogFile = flag.String("log-file", "", "flag.log_file")
+	flagTmpl    = flag.String("template", "", "flag.template")
+	flagForce   = flag.Bool("force-models", false, "flag.force_models")
+	flagProbe   = flag.Bool("probe-auth", false, "flag.probe_auth")
+	flagImages  = flag.Bool("probe-images", false, "flag.probe_images")
+	flagThink   = flag.Bool("probe-reasoning", false, "flag.probe_reasoning")
+	flagTimeout = flag.Duration("timeout", api.DefaultTimeout, "flag.timeout")
+	flagPrefix  = flag.String("provider-prefix", config.DefaultNamespace.Prefix, "flag.provider_prefix")
+	flagPName   = flag.String("provider-name", "", "flag.provider_name")
+	flagOnConf  = flag.String("on-conflict", "", "flag.on_conflict")
+
+	flagHeaders  listFlag // --header，可重复
+	flagPHeaders listFlag // --provider-header，可重复
+)
+
 func init() {
-	// 在旧版 Windows CMD（不支持 ANSI）中提示切换到 UTF-8 代码页
+	flag.Var(&flagHeaders, "header", "flag.header")
+	flag.Var(&flagPHeaders, "provider-header", "flag.provider_header")
+}
+
+// listFlag 可以重复指定的字符串参数
+type listFlag []string
+
+// String 实现 flag.Value 接口
+func (f *listFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(*f, ", ")
+}
+
+// Set 实现 flag.Value 接口，每次指定追加一项
+func (f *listFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// setupDebugLog 按 --verbose / --log-file 开启调试日志
+// --verbose 输出到标准错误，--log-file 追加写入指定文件，两者可同时使用
+// recordLastRun 为 true 时同时写入状态目录下的 last-run.log，供 support 命令打包
+func setupDebugLog(recordLastRun bool) error {
+	var writers []io.Writer
+	if recordLastRun {
+		// 最近一次运行的日志只是辅助信息，创建失败不影响配置流程
+		if f, err := support.OpenLastRunLog(); err == nil {
+			writers = append(writers, f)
+		}
+	}
+	if *flagVerbose {
+		writers = append(writers, os.Stderr)
+	}
+	if *flagLogFile != "" {
+		f, err := debuglog.OpenFile(*flagLogFile)
+		if err != nil {
+			return fmt.Errorf(i18n.T("main.log_file_failed"), err)
+		}
+		writers = append(writers, f)
+	}
+	if len(writers) == 0 {
+		return nil
+	}
+	debuglog.Enable(io.MultiWriter(writers...))
+	debuglog.Printf("main", "%s %s (%s/%s), args: %s", filepath.Base(os.Args[0]), ui.Version, runtime.GOOS, runtime.GOARCH, strings.Join(os.Args[1:], " "))
+	return nil
+}
+
+// printLegacyCMDHint 在旧版 Windows CMD（不支持 ANSI）中提示切换到 UTF-8 代码页
+// 需在确定界面语言之后调用
+func printLegacyCMDHint() {
 	if ui.IsLegacyWindowsCMD() {
-		fmt.Println("提示: 检测到旧版 Windows CMD，如出现中文或字符乱码，请先运行:")
+		fmt.Println(i18n.T("main.legacy_cmd_hint"))
 		fmt.Println("      chcp 65001")
 		fmt.Println()
-		fmt.Println("建议: 使用 Windows Terminal 可获得完整的颜色和字符显示支持。")
+		fmt.Println(i18n.T("main.legacy_cmd_suggest"))
 		fmt.Println()
 	}
 }
 
+// cliHeaders --header / --provider-header 指定的自定义请求头
+var cliHeaders config.Headers
+
+// customHeaders 写入配置和连接测试使用的自定义请求头：命令行参数与模板或现有配置中的请求头合并，命令行优先
+var customHeaders config.Headers
+
+// onConflict --on-conflict 指定的冲突处理方式，为空时运行时询问
+var onConflict config.ConflictResolution
+
+// pendingUpdate 启动时发起的异步更新检查，结果展示后置为 nil
+var pendingUpdate <-chan ui.UpdateResult
+
+// showPendingUpdate 展示异步更新检查结果
+// wait 为最多等待的时间，0 表示结果尚未返回就直接跳过
+func showPendingUpdate(wait time.Duration) {
+	if pendingUpdate == nil {
+		return
+	}
+	var update ui.UpdateResult
+	if wait > 0 {
+		select {
+		case update = <-pendingUpdate:
+		case <-time.After(wait):
+			return
+		}
+	} else {
+		select {
+		case update = <-pendingUpdate:
+		default:
+			return
+		}
+	}
+	pendingUpdate = nil
+	if update.HasUpdate {
+		ui.PrintUpdateNotice(update.LatestVersion, update.DownloadURL, update.ReleaseNotes)
+		result.Update = &reportUpdate{
+			CurrentVersion: ui.Version,
+			LatestVersion:  update.LatestVersion,
+			HasUpdate:      true,
+			DownloadURL:    update.DownloadURL,
+		}
+	}
+}
+
 // waitForExit 等待用户按任意键退出
+// 退出前补充展示启动时尚未返回的更新提示，避免网络较慢时提示丢失
 func waitForExit() {
 	fmt.Println()
-	fmt.Print("按 Enter 键退出...")
+	showPendingUpdate(time.Second)
+	fmt.Print(i18n.T("main.press_enter"))
 	bufio.NewReader(os.Stdin).ReadBytes('\n')
 }
 
+// exit 结束程序：文本模式等待用户按键后退出，JSON 输出模式输出结果文档后立即退出
+// 同时保存本次结果到状态目录，供 support 命令打包
+func exit(code int) {
+	stopIfInterrupted()
+	result.finish(code)
+	support.SaveLastRunReport(result)
+	if jsonOutput {
+		showPendingUpdate(0)
+		result.emit(code)
+		os.Exit(code)
+	}
+	exitingCode.Store(int32(code))
+	waitForExit()
+	os.Exit(code)
+}
+
+// fail 打印错误、记录到结果文档并以退出码 1 结束程序
+func fail(code, message string, err error) {
+	ui.PrintError(message)
+	result.addError(code, err)
+	exit(1)
+}
+
+// warn 打印警告并记录到结果文档
+func warn(message string) {
+	ui.PrintWarning(message)
+	result.addWarning(message)
+}
+
+// newTester 按命令行参数创建 API 测试器，重试时在界面上给出提示
+func newTester(url, apiKey string) *api.Tester {
+	tester := api.NewTester(url, apiKey)
+	tester.SetTimeout(*flagTimeout)
+	policy := api.DefaultRetryPolicy()
+	policy.MaxRetries = *flagRetries
+	tester.SetRetryPolicy(policy)
+	tester.SetHeaders(customHeaders)
+	tester.SetRetryNotifier(func(attempt int, delay time.Duration, reason string) {
+		ui.PrintWarning(i18n.T("main.retrying", reason, delay.Seconds(), attempt))
+	})
+	return tester
+}
+
+// parseHeaderFlags 解析 --header "Name: Value" 和 --provider-header "TYPE=Name: Value"
+func parseHeaderFlags() (config.Headers, error) {
+	var h config.Headers
+	for _, spec := range flagHeaders {
+		name, value, err := config.ParseHeader(spec)
+		if err != nil {
+			return h, err
+		}
+		h.Set(nil, name, value)
+	}
+	for _, spec := range flagPHeaders {
+		typeName, header, ok := strings.Cut(spec, "=")
+		pType, known := config.ParseProviderType(strings.TrimSpace(typeName))
+		if !ok || !known {
+			return h, fmt.Errorf(i18n.T("headers.bad_provider"), spec)
+		}
+		name, value, err := config.ParseHeader(header)
+		if err != nil {
+			return h, err
+		}
+		h.Set(&pType, name, value)
+	}
+	return h, nil
+}
+
+// printHeaders 提示本次写入的自定义请求头名称（取值可能包含令牌，不显示）
+func printHeaders() {
+	if !customHeaders.IsEmpty() {
+		ui.PrintInfo(i18n.T("main.headers", strings.Join(customHeaders.Names(), ", ")))
+	}
+}
+
+// selectNamespace 按 --provider-prefix / --provider-name 确定 provider 的命名空间
+// 使用模板时，命令行没有显式指定的部分采用模板中的 provider_prefix / provider_name
+func selectNamespace(tpl *template.Template) (config.Namespace, error) {
+	ns := config.Namespace{Prefix: *flagPrefix, Name: *flagPName}
+	if tpl != nil {
+		set := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if tpl.ProviderPrefix != "" && !set["provider-prefix"] {
+			ns.Prefix = tpl.ProviderPrefix
+		}
+		if tpl.ProviderName != "" && !set["provider-name"] {
+			ns.Name = tpl.ProviderName
+		}
+	}
+	return ns, ns.Validate()
+}
+
+// useNamespace 设置本次运行使用的命名空间，非默认命名空间记录到结果文档
+func useNamespace(ns config.Namespace) {
+	config.UseNamespace(ns)
+	result.Namespace = ""
+	if !ns.IsDefault() {
+		result.Namespace = ns.Prefix
+	}
+}
+
+// printNamespace 使用非默认命名空间时提示 provider 的 ID 和显示名称
+func printNamespace() {
+	if ns := config.CurrentNamespace(); !ns.IsDefault() {
+		ui.PrintInfo(i18n.T("main.namespace", ns.Prefix, ns.ProviderID(config.ProviderAnthropic), ns.ProviderName(config.ProviderAnthropic)))
+	}
+}
+
+// printOtherNamespaces 提示配置文件中还有其他命名空间（其他 DMXAPI 账号）的配置，本次运行不会修改它们
+func printOtherNamespaces(reader *config.Reader) {
+	current := config.CurrentNamespace()
+	for _, u := range reader.ReadNamespaces() {
+		if u.Namespace.Prefix != current.Prefix {
+			ui.PrintInfo(i18n.T("main.namespace_other", u.Namespace.Prefix, u.Models, u.Namespace.Prefix))
+		}
+	}
+}
+
+// resolveConflicts 写入前检查与用户自定义 provider、模型和认证条目的冲突，
+// 按 --on-conflict 或用户的选择处理（可能修改 cfg），返回需要写入 auth.json 的 provider ID
+func resolveConflicts(collector *input.Collector, writer *config.Writer, cfg *config.OpenCodeConfig) []string {
+	conflicts := writer.DetectConflicts(cfg)
+	if len(conflicts) == 0 {
+		return config.GetProviderIDs(cfg)
+	}
+	ui.PrintWarning(i18n.T("main.conflicts", len(conflicts)))
+	for _, c := range conflicts {
+		ui.PrintInfo("  " + c.Message())
+	}
+
+	resolution := onConflict
+	if resolution == "" {
+		var err error
+		if resolution, err = collector.CollectConflictResolution(); err != nil {
+			fail(codeInputFailed, i18n.T("main.conflict_failed", err), err)
+		}
+	}
+	result.setConflicts(conflicts, resolution)
+	if resolution == config.ResolveCancel {
+		err := i18n.Error("main.conflict_cancelled")
+		fail(codeConflict, err.Error(), err)
+	}
+
+	skipAuth := writer.ResolveConflicts(cfg, conflicts, resolution)
+	if len(cfg.Provider) == 0 {
+		err := i18n.Error("main.conflict_nothing_left")
+		fail(codeConflict, err.Error(), err)
+	}
+	ui.PrintInfo(i18n.T("main.conflict_resolved", i18n.T("conflict.resolution_"+string(resolution))))
+	ui.PrintDivider()
+	return slices.DeleteFunc(config.GetProviderIDs(cfg), func(id string) bool {
+		return slices.Contains(skipAuth, id)
+	})
+}
+
+// collectBaseURL 收集并检查 URL，检查未通过时可以重新输入
+func collectBaseURL(collector *input.Collector) string {
+	for {
+		raw, err := collector.CollectURL()
+		if err != nil {
+			fail(codeInputFailed, i18n.T("main.url_failed", err), err)
+		}
+		url, err := checkBaseURL(raw)
+		if err == nil {
+			return url
+		}
+		result.addWarning(i18n.T("main.url_unreachable", err))
+		reenter, err := collector.ConfirmReenterURL()
+		if err != nil {
+			fail(codeInputFailed, i18n.T("main.url_failed", err), err)
+		}
+		if !reenter {
+			return url
+		}
+	}
+}
+
+// checkBaseURL 整理用户输入的 URL（去除误粘贴的接口路径等）并检查地址能否访问
+// 返回整理后的基础 URL；地址无法访问时打印原因并返回检查错误
+func checkBaseURL(raw string) (string, error) {
+	info := config.AnalyzeBaseURL(raw)
+	if info.Stripped != "" {
+		ui.PrintInfo(i18n.T("main.url_stripped", info.Stripped, info.URL))
+	}
+	if info.Subpath != "" {
+		ui.PrintInfo(i18n.T("main.url_subpath", info.Subpath))
+	}
+	if info.Insecure {
+		warn(i18n.T("main.url_insecure"))
+	}
+
+	if *flagOffline {
+		// 离线模式不做额外的可达性检查，地址问题在连接测试时报告
+		return info.URL, nil
+	}
+	ui.PrintInfo(i18n.T("main.url_checking"))
+	err := newTester(info.URL, "").CheckReachable(interruptCtx)
+	stopIfInterrupted()
+	if err != nil {
+		ui.PrintError(i18n.T("main.url_unreachable", err))
+		if te, ok := api.AsTestError(err); ok {
+			ui.PrintInfo(te.Hint())
+		}
+		return info.URL, err
+	}
+	return info.URL, nil
+}
+
+// loadModelCatalog 准备输入模型时浏览和校验使用的模型目录：优先使用网关 /v1/models 刷新目录并缓存，
+// 获取失败或离线模式下使用上次刷新的缓存或内置目录。指定 --force-models 时不访问网关、不校验模型名称
+func loadModelCatalog(collector *input.Collector, url, apiKey string) {
+	catalog := config.LoadCatalog(url)
+	if *flagForce {
+		config.UseCatalog(catalog)
+		collector.SetModelBrowser(catalog)
+		return
+	}
+	var models []string
+	var err error
+	if !*flagOffline {
+		models, err = newTester(url, apiKey).ListModels(interruptCtx)
+		stopIfInterrupted()
+	}
+	switch {
+	case *flagOffline && catalog.IsRefreshed():
+		ui.PrintInfo(i18n.T("main.catalog_offline_cached", catalog.RefreshedAt.Format("2006-01-02 15:04")))
+	case *flagOffline:
+		ui.PrintInfo(i18n.T("main.catalog_offline_builtin"))
+	case err == nil:
+		catalog = config.RefreshCatalog(models, url)
+		if err := config.SaveCatalog(catalog); err != nil {
+			debuglog.Printf("config", "catalog: failed to save cache: %v", err)
+		}
+		ui.PrintInfo(i18n.T("main.catalog_gateway", len(models)))
+	case catalog.IsRefreshed():
+		warn(i18n.T("main.catalog_cached", err, catalog.RefreshedAt.Format("2006-01-02 15:04")))
+	default:
+		warn(i18n.T("main.catalog_builtin", err))
+	}
+	config.UseCatalog(catalog)
+	collector.SetModelCatalog(catalog.IDs())
+	collector.SetModelBrowser(catalog)
+}
+
+// printTestResult 打印连接测试成功信息，区分一次成功和重试后成功
+func printTestResult(result *api.TestResult) {
+	if !result.Retried() {
+		ui.PrintSuccess(i18n.T("main.test_ok"))
+		return
+	}
+	ui.PrintSuccess(i18n.T("main.test_ok_retried", result.Attempts-1, result.Duration.Seconds()))
+	warn(i18n.T("main.gateway_unstable"))
+}
+
+// probeAuth 分别用 SDK 原生认证方式和 Bearer 方式测试模型，显示网关接受哪种方式
+func probeAuth(tester *api.Tester, model string, pType config.ProviderType) {
+	ui.PrintInfo(i18n.T("main.auth_probing"))
+	probes := tester.ProbeAuth(interruptCtx, model, pType)
+	stopIfInterrupted()
+	result.setAuthProbes(probes)
+
+	var native *api.AuthProbe
+	otherAccepted := false
+	for i, p := range probes {
+		label := p.Style.String()
+		if p.Native {
+			native = &probes[i]
+			label = i18n.T("main.auth_style_native", label)
+		}
+		if p.Accepted() {
+			ui.PrintSuccess(i18n.T("main.auth_style_ok", label))
+			otherAccepted = otherAccepted || !p.Native
+		} else {
+			ui.PrintError(i18n.T("main.auth_style_rejected", label, p.Err))
+		}
+	}
+	if native != nil && !native.Accepted() && otherAccepted {
+		warn(i18n.T("main.auth_native_rejected", config.GetProviderInfo(pType).NPM, native.Style))
+	}
+}
+
+// probeImages 逐个测试模型能否接收图片输入（--probe-images），providerOf 返回模型路由到的 provider 类型
+func probeImages(tester *api.Tester, models []string, providerOf func(string) config.ProviderType) []api.ImageProbe {
+	ui.PrintInfo(i18n.T("main.image_probing"))
+	probes := make([]api.ImageProbe, 0, len(models))
+	for _, model := range models {
+		p := tester.ProbeImageInput(interruptCtx, model, providerOf(model))
+		stopIfInterrupted()
+		result.addImageProbe(p)
+		switch p.Support {
+		case api.ImageSupported:
+			ui.PrintSuccess(i18n.T("main.image_supported", model))
+		case api.ImageUnsupported:
+			ui.PrintInfo(i18n.T("main.image_unsupported", model, p.Err))
+		default:
+			warn(i18n.T("main.image_unknown", model, p.Err))
+		}
+		probes = append(probes, p)
+	}
+	return probes
+}
+
+// applyImageProbes 将图片输入探测结果写入模型的 modalities，无法判断的模型保持不变
+func applyImageProbes(cfg *config.OpenCodeConfig, probes []api.ImageProbe) {
+	for _, p := range probes {
+		if input := p.InputModalities(); input != nil {
+			cfg.SetInputModalities(p.Model, input)
+		}
+	}
+}
+
+// probeReasoning 逐个测试模型是否返回思考内容（--probe-reasoning），providerOf 返回模型路由到的 provider 类型
+func probeReasoning(tester *api.Tester, models []string, providerOf func(string) config.ProviderType) []api.ReasoningProbe {
+	ui.PrintInfo(i18n.T("main.reasoning_probing"))
+	probes := make([]api.ReasoningProbe, 0, len(models))
+	for _, model := range models {
+		p := tester.ProbeReasoning(interruptCtx, model, providerOf(model))
+		stopIfInterrupted()
+		result.addReasoningProbe(p)
+		switch {
+		case p.Support == api.ReasoningSupported && p.Tokens > 0:
+			ui.PrintSuccess(i18n.T("main.reasoning_tokens", model, p.Tokens))
+		case p.Support == api.ReasoningSupported:
+			ui.PrintSuccess(i18n.T("main.reasoning_supported", model))
+		case p.Support == api.ReasoningNone && p.Err != nil:
+			ui.PrintInfo(i18n.T("main.reasoning_rejected", model, p.Err))
+		case p.Support == api.ReasoningNone:
+			ui.PrintInfo(i18n.T("main.reasoning_none", model))
+		default:
+			warn(i18n.T("main.reasoning_unknown", model, p.Err))
+		}
+		probes = append(probes, p)
+	}
+	return probes
+}
+
+// applyReasoningProbes 将推理输出探测结果写入模型的 reasoning 字段，无法判断的模型保持不变
+func applyReasoningProbes(cfg *config.OpenCodeConfig, probes []api.ReasoningProbe) {
+	for _, p := range probes {
+		if reasoning, known := p.Reasoning(); known {
+			cfg.SetReasoning(p.Model, reasoning)
+		}
+	}
+}
+
+// queryBalance 查询 API Key 的额度信息，网关不支持或查询失败时返回 nil（不影响配置流程）
+// 离线模式下不查询；请求携带与连接测试相同的自定义请求头，可被中断取消
+func queryBalance(url, apiKey string) *api.Balance {
+	if *flagOffline {
+		return nil
+	}
+	balance, err := newTester(url, apiKey).AccountClient().QueryBalance(interruptCtx)
+	stopIfInterrupted()
+	if err != nil {
+		return nil
+	}
+	return balance
+}
+
+// formatBalance 格式化额度信息用于展示，未获取到时返回空字符串
+func formatBalance(balance *api.Balance) string {
+	if balance == nil {
+		return ""
+	}
+	return balance.String()
+}
+
+// printSummary 打印配置摘要
+// defaultModel 为空时不显示默认模型行
+func printSummary(url string, models []string, defaultModel, configPath, authPath string, balance *api.Balance) {
+	fmt.Println("  " + i18n.T("summary.title"))
+	fmt.Printf("    %-8s%s\n", "URL", config.NormalizeBaseURL(url))
+	fmt.Printf("    %-8s%s\n", i18n.T("summary.models"), strings.Join(models, ", "))
+	if defaultModel != "" {
+		fmt.Printf("    %-8s%s\n", i18n.T("summary.default_model"), defaultModel)
+	}
+	fmt.Printf("    %-8s%s\n", i18n.T("summary.config"), configPath)
+	fmt.Printf("    %-8s%s\n", i18n.T("summary.auth"), authPath)
+	if balance != nil {
+		fmt.Printf("    %-8s%s\n", i18n.T("summary.balance"), balance.String())
+	} else if !*flagOffline {
+		fmt.Printf("    %-8s%s\n", i18n.T("summary.balance"), i18n.T("summary.balance_unavailable"))
+	}
+	fmt.Println()
+	fmt.Println("  " + i18n.T("main.run_opencode"))
+}
+
+// printTestError 打印连接测试失败信息，可归类的错误附带处理建议和错误代码
+func printTestError(result *api.TestResult, err error) {
+	ui.PrintError(i18n.T("main.test_failed", err))
+	if result != nil && result.Retried() {
+		ui.PrintInfo(i18n.T("main.retried_failed", result.Attempts-1))
+	}
+	if te, ok := api.AsTestError(err); ok {
+		ui.PrintInfo(te.Hint())
+		ui.PrintInfo(i18n.T("main.error_code", te.Code))
+	}
+}
+
+// printUsage 打印命令行用法
+// 参数说明以消息 ID 形式登记在 flag 中，这里按当前语言翻译后输出
+func printUsage() {
+	// -h 在解析阶段触发，此时已解析到的 --lang 尚未生效
+	i18n.SetLocale(i18n.Detect(*flagLang))
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, i18n.T("usage.header", filepath.Base(os.Args[0])))
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, i18n.T("usage.commands"))
+	fmt.Fprintf(out, "  %-10s%s\n", i18n.T("usage.cmd_none"), i18n.T("usage.cmd_wizard"))
+	fmt.Fprintf(out, "  %-10s%s\n", "update", i18n.T("usage.cmd_update"))
+	fmt.Fprintf(out, "  %-10s%s\n", "support", i18n.T("usage.cmd_support"))
+	fmt.Fprintf(out, "  %-10s%s\n", "export", i18n.T("usage.cmd_export"))
+	fmt.Fprintf(out, "  %-10s%s\n", "dashboard", i18n.T("usage.cmd_dashboard"))
+	fmt.Fprintf(out, "  %-10s%s\n", "migrate", i18n.T("usage.cmd_migrate"))
+	fmt.Fprintf(out, "  %-10s%s\n", "mock-server", i18n.T("usage.cmd_mock_server"))
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, i18n.T("usage.flags"))
+	flag.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(out, "  --%s\n", f.Name)
+		desc := i18n.T(f.Usage)
+		if f.DefValue != "" && f.DefValue != "false" {
+			desc += " " + i18n.T("usage.default", f.DefValue)
+		}
+		fmt.Fprintf(out, "    \t%s\n", desc)
+	})
+}
+
 func main() {
+	flag.Usage = printUsage
+	flag.Parse()
+	i18n.SetLocale(i18n.Detect(*flagLang))
+
+	format, err := ui.ParseOutputFormat(*flagOutput)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if format == ui.OutputJSON {
+		// 标准输出只保留结果文档：关闭终端装饰，交互提示和备份信息改写到标准错误
+		jsonOutput = true
+		ui.SetQuiet(true)
+		config.SetMessageOutput(os.Stderr)
+	}
+
+	if err := setupDebugLog(!*flagVersion && flag.Arg(0) == ""); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *flagVersion {
+		if jsonOutput {
+			result.Command = "version"
+			result.emit(0)
+		} else {
+			fmt.Println(ui.Version)
+		}
+		return
+	}
+
+	if !jsonOutput {
+		printLegacyCMDHint()
+	}
+
+	// 清理上次自更新遗留的旧版本文件（Windows 无法在更新时直接删除）
+	ui.CleanupOldExecutable()
+
+	channel, err := ui.ParseChannel(*flagChannel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		result.addError(codeUsage, err)
+		result.emit(2)
+		os.Exit(2)
+	}
+
+	ns, err := selectNamespace(nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		result.addError(codeUsage, err)
+		result.emit(2)
+		os.Exit(2)
+	}
+	useNamespace(ns)
+
+	if onConflict, err = config.ParseConflictResolution(*flagOnConf); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		result.addError(codeUsage, err)
+		result.emit(2)
+		os.Exit(2)
+	}
+
+	if cliHeaders, err = parseHeaderFlags(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		result.addError(codeUsage, err)
+		result.emit(2)
+		os.Exit(2)
+	}
+	customHeaders = cliHeaders
+
+	switch cmd := flag.Arg(0); cmd {
+	case "":
+		// 默认运行配置向导
+	case "update":
+		result.Command = "update"
+		code := runUpdate(channel)
+		result.emit(code)
+		os.Exit(code)
+	case "support":
+		result.Command = "support"
+		code := runSupport(flag.Arg(1))
+		result.emit(code)
+		os.Exit(code)
+	case "export":
+		result.Command = "export"
+		code := runExport(flag.Arg(1))
+		result.emit(code)
+		os.Exit(code)
+	case "dashboard":
+		result.Command = "dashboard"
+		code := runDashboard()
+		result.emit(code)
+		os.Exit(code)
+	case "migrate":
+		result.Command = "migrate"
+		code := runMigrate()
+		result.emit(code)
+		os.Exit(code)
+	case "mock-server":
+		result.Command = "mock-server"
+		code := runMockServer(flag.Args()[1:])
+		result.emit(code)
+		os.Exit(code)
+	default:
+		result.Command = cmd
+		fmt.Fprintln(os.Stderr, i18n.T("main.unknown_command", cmd))
+		fmt.Fprintln(os.Stderr)
+		printUsage()
+		result.addError(codeUsage, errors.New(i18n.T("main.unknown_command", cmd)))
+		result.emit(2)
+		os.Exit(2)
+	}
+
 	ui.PrintBanner()
 
+	// Ctrl+C 时取消进行中的请求，并保证不会只写入一部分配置
+	handleInterrupts()
+
 	// 启动异步检查更新（在后续操作耗时期间并行进行 HTTP 请求）
-	updateCh := ui.CheckForUpdateAsync()
+	// 离线模式或设置了 DMXAPI_NO_UPDATE_CHECK 时不检查
+	if !*flagOffline && !ui.UpdateCheckDisabled() {
+		pendingUpdate = ui.CheckForUpdateAsync(channel)
+	}
 
 	ui.PrintDivider()
 
@@ -43,199 +722,323 @@ func main() {
 	installed, version := ui.CheckOpencode()
 	if installed {
 		if version != "" {
-			ui.PrintSuccess(fmt.Sprintf("已检测到 opencode 已安装（版本：%s）", version))
+			ui.PrintSuccess(i18n.T("main.opencode_found_version", version))
 		} else {
-			ui.PrintSuccess("已检测到 opencode 已安装")
+			ui.PrintSuccess(i18n.T("main.opencode_found"))
 		}
-		fmt.Println()
+		ui.PrintDivider()
 	} else {
-		ui.PrintWarning("未检测到 opencode，请先安装后再使用本工具")
-		ui.PrintInfo("官网地址：https://opencode.ai")
-		waitForExit()
-		os.Exit(1)
+		ui.PrintWarning(i18n.T("main.opencode_missing"))
+		ui.PrintInfo(i18n.T("main.opencode_site"))
+		result.addError(codeOpencodeMissing, errors.New(i18n.T("main.opencode_missing")))
+		exit(1)
+	}
+
+	// 展示更新提示（非阻塞：已有结果就显示，网络慢则留到退出前再展示）
+	showPendingUpdate(0)
+
+	collector := input.NewCollector()
+	if jsonOutput {
+		collector.SetOutput(os.Stderr)
 	}
 
-	// 展示更新提示（非阻塞：已有结果就显示，网络慢则跳过）
-	select {
-	case result := <-updateCh:
-		if result.HasUpdate {
-			ui.PrintUpdateNotice(result.LatestVersion, result.DownloadURL)
+	// 使用团队模板时只需输入 API Key，其余配置全部来自模板
+	if *flagTmpl != "" {
+		tpl, err := template.Load(*flagTmpl)
+		if err != nil {
+			fail(codeTemplateInvalid, i18n.T("main.template_failed", err), err)
 		}
-	default:
-		// 结果尚未返回，继续不等待
+		result.Template = *flagTmpl
+		ui.PrintSuccess(i18n.T("main.template_loaded", *flagTmpl, len(tpl.Models)))
+		ns, err := selectNamespace(tpl)
+		if err != nil {
+			fail(codeTemplateInvalid, i18n.T("main.template_failed", err), err)
+		}
+		useNamespace(ns)
+		customHeaders = tpl.CustomHeaders().Merge(cliHeaders)
+		printNamespace()
+		offerMigration(collector, config.NewReader())
+		runFullConfiguration(collector, tpl)
+		exit(0)
 	}
 
-	collector := input.NewCollector()
+	printNamespace()
 	reader := config.NewReader()
+	offerMigration(collector, reader)
 	existingConfig := reader.ReadExistingConfig()
+	printOtherNamespaces(reader)
 
 	if existingConfig != nil {
-		ui.PrintExistingConfigInfo(existingConfig.URL, config.MaskAPIKey(existingConfig.APIKey), existingConfig.Models)
+		debuglog.AddSecret(existingConfig.APIKey)
+		balance := queryBalance(existingConfig.URL, existingConfig.APIKey)
+		result.setBalance(balance)
+		ui.PrintExistingConfigInfo(existingConfig.URL, config.MaskAPIKey(existingConfig.APIKey), existingConfig.Models, formatBalance(balance))
+		if balance != nil && balance.IsLow() {
+			warn(i18n.T("main.low_balance"))
+		}
 		ui.PrintConfigModeHeader()
 
 		mode, err := collector.CollectConfigMode()
 		if err != nil {
-			ui.PrintError(fmt.Sprintf("选择配置模式失败: %v", err))
-			waitForExit()
-			os.Exit(1)
+			fail(codeInputFailed, i18n.T("main.mode_failed", err), err)
 		}
 
 		ui.PrintDivider()
 
 		if mode == input.ConfigModeModelOnly {
-			runModelOnlyConfiguration(collector, existingConfig)
+			runModelOnlyConfiguration(collector, existingConfig, balance)
 		} else {
-			runFullConfiguration(collector)
+			runFullConfiguration(collector, nil)
 		}
 	} else {
-		runFullConfiguration(collector)
+		runFullConfiguration(collector, nil)
 	}
 
-	waitForExit()
+	exit(0)
 }
 
 // runFullConfiguration 运行完整配置流程（6步）
-func runFullConfiguration(collector *input.Collector) {
+// tpl 不为 nil 时 URL（模板指定时）和模型来自模板，只需输入 API Key；
+// 模板中的地址同样检查能否访问，模型同样按网关模型列表校验（--force-models 时不校验）
+func runFullConfiguration(collector *input.Collector, tpl *template.Template) {
 	// [1/6] 配置URL
-	ui.PrintStep(1, 6, "配置 DMXAPI URL")
-	url, err := collector.CollectURL()
-	if err != nil {
-		ui.PrintError(fmt.Sprintf("读取URL失败: %v", err))
-		waitForExit()
-		os.Exit(1)
+	ui.PrintStep(1, 6, i18n.T("step.url"))
+	var url string
+	var err error
+	if tpl != nil && tpl.URL != "" {
+		// 模板中的地址无法在此修改，检查未通过时直接结束
+		if url, err = checkBaseURL(tpl.URL); err != nil {
+			result.addError("", err)
+			exit(1)
+		}
+	} else {
+		url = collectBaseURL(collector)
 	}
-	ui.PrintSuccess(fmt.Sprintf("URL 已设置: %s", url))
-	fmt.Println()
+	ui.PrintSuccess(i18n.T("main.url_set", url))
+	ui.PrintDivider()
 
 	// [2/6] 配置API Key
-	ui.PrintStep(2, 6, "配置 API Key")
+	ui.PrintStep(2, 6, i18n.T("step.api_key"))
 	apiKey, err := collector.CollectAPIKey()
 	if err != nil {
-		ui.PrintError(fmt.Sprintf("读取API Key失败: %v", err))
-		waitForExit()
-		os.Exit(1)
+		fail(codeInputFailed, i18n.T("main.api_key_failed", err), err)
 	}
-	ui.PrintSuccess("API Key 已设置")
-	fmt.Println()
+	debuglog.AddSecret(apiKey)
+	ui.PrintSuccess(i18n.T("main.api_key_set"))
+	ui.PrintDivider()
 
 	// [3/6] 配置模型
-	ui.PrintStep(3, 6, "配置模型")
-	models, err := collector.CollectModels()
-	if err != nil {
-		ui.PrintError(fmt.Sprintf("读取模型失败: %v", err))
-		waitForExit()
-		os.Exit(1)
+	ui.PrintStep(3, 6, i18n.T("step.models"))
+	var models []string
+	loadModelCatalog(collector, url, apiKey)
+	if tpl != nil {
+		models = tpl.ModelIDs()
+		if err := collector.ValidateModels(models); err != nil {
+			fail(codeTemplateInvalid, i18n.T("main.template_models", err), err)
+		}
+	} else if models, err = collector.CollectModels(); err != nil {
+		fail(codeInputFailed, i18n.T("main.models_failed", err), err)
 	}
-	ui.PrintSuccess(fmt.Sprintf("已添加 %d 个模型", len(models)))
-	fmt.Println()
+	ui.PrintSuccess(i18n.T("main.models_added", len(models)))
+	ui.PrintDivider()
 
 	// [4/6] 测试API连接
-	ui.PrintStep(4, 6, "测试 API 连接")
-	ui.PrintInfo("正在测试连接...")
-	tester := api.NewTester(url, apiKey)
-	if err := tester.TestConnection(models[0]); err != nil {
-		ui.PrintError(fmt.Sprintf("API 连接测试失败: %v", err))
-		waitForExit()
-		os.Exit(1)
+	ui.PrintStep(4, 6, i18n.T("step.test"))
+	ui.PrintInfo(i18n.T("main.testing"))
+	tester := newTester(url, apiKey)
+	pType := config.ClassifyModel(models[0])
+	if tpl != nil {
+		pType = tpl.ProviderOf(models[0])
 	}
-	ui.PrintSuccess("API 连接测试成功！")
-	fmt.Println()
+	testResult, err := tester.TestConnectionAs(interruptCtx, models[0], pType)
+	result.addTest(models[0], testResult, err)
+	stopIfInterrupted()
+	if err != nil {
+		printTestError(testResult, err)
+		// 认证失败时检查网关是否只接受其他认证方式，便于区分 Key 无效和请求头不兼容
+		if te, ok := api.AsTestError(err); *flagProbe || (ok && te.Code == api.ErrCodeAuthInvalid) {
+			probeAuth(tester, models[0], pType)
+		}
+		result.addError("", err)
+		exit(1)
+	}
+	printTestResult(testResult)
+	if *flagProbe {
+		probeAuth(tester, models[0], pType)
+	}
+	providerOf := config.ClassifyModel
+	if tpl != nil {
+		providerOf = tpl.ProviderOf
+	}
+	var imageProbes []api.ImageProbe
+	if *flagImages {
+		imageProbes = probeImages(tester, models, providerOf)
+	}
+	var reasoningProbes []api.ReasoningProbe
+	if *flagThink {
+		reasoningProbes = probeReasoning(tester, models, providerOf)
+	}
+
+	// 连接成功后查询额度，余额不足是连接失败之外最常见的问题
+	balance := queryBalance(url, apiKey)
+	result.setBalance(balance)
+	if balance != nil {
+		ui.PrintInfo(i18n.T("main.balance", balance.String()))
+		if balance.IsLow() {
+			warn(i18n.T("main.low_balance"))
+		}
+	}
+	ui.PrintDivider()
 
 	ui.PrintDivider()
-	ui.PrintInfo("正在写入配置文件...")
-	fmt.Println()
+	ui.PrintInfo(i18n.T("main.writing"))
+	ui.PrintDivider()
 
 	// [5/6] 配置认证信息
-	ui.PrintStep(5, 6, "配置认证信息")
+	ui.PrintStep(5, 6, i18n.T("step.auth"))
 	cfg := config.NewDMXAPIConfig(url, apiKey, models)
-	providerIDs := config.GetProviderIDs(cfg)
-	authMgr := auth.NewAuthManager(providerIDs, apiKey)
-	authPath, err := authMgr.Login()
-	if err != nil {
-		ui.PrintError(fmt.Sprintf("认证配置失败: %v", err))
-		waitForExit()
-		os.Exit(1)
+	if tpl != nil {
+		cfg = tpl.Config(url, apiKey)
 	}
-	ui.PrintSuccess(fmt.Sprintf("认证配置完成: %s", authPath))
-	fmt.Println()
-
-	// [6/6] 生成配置文件
-	ui.PrintStep(6, 6, "生成配置文件")
+	cfg.ApplyHeaders(customHeaders)
+	printHeaders()
+	applyImageProbes(cfg, imageProbes)
+	applyReasoningProbes(cfg, reasoningProbes)
+	result.Mode = "full"
+	result.URL = config.NormalizeBaseURL(url)
 	writer := config.NewWriter()
-	configPath, err := writer.WriteConfig(cfg)
+	providerIDs := resolveConflicts(collector, writer, cfg)
+	result.setProviders(cfg)
+	var authPath, configPath string
+	err = writeConfiguration(func() (err error) {
+		// [6/6] 生成配置文件
+		authPath, configPath, err = writeAuthAndConfig(6, writer, providerIDs, apiKey, cfg)
+		return err
+	})
 	if err != nil {
-		ui.PrintError(fmt.Sprintf("写入配置失败: %v", err))
-		waitForExit()
-		os.Exit(1)
+		failWrite(err)
 	}
-	ui.PrintSuccess(fmt.Sprintf("配置文件已生成: %s", configPath))
-	fmt.Println()
 
 	ui.PrintDivider()
 	ui.PrintComplete()
 
-	fmt.Println("  配置摘要:")
-	fmt.Printf("    URL     %s\n", config.NormalizeBaseURL(url))
-	fmt.Printf("    模型    %s\n", strings.Join(models, ", "))
-	fmt.Printf("    配置    %s\n", configPath)
-	fmt.Printf("    认证    %s\n", authPath)
-	fmt.Println()
-	fmt.Println("  运行 'opencode' 启动程序")
+	result.ConfigPath = configPath
+	if !jsonOutput {
+		printSummary(url, models, cfg.Model, configPath, authPath, balance)
+	}
+}
+
+// writeFailure 写入配置文件失败的原因，由 failWrite 在 writeConfiguration 返回（释放 writeMu）之后报告
+type writeFailure struct {
+	code    string
+	message string
+	err     error
+}
+
+func (f *writeFailure) Error() string { return f.message }
+func (f *writeFailure) Unwrap() error { return f.err }
+
+// writeAuthAndConfig 依次写入 auth.json 和 opencode.json，需在 writeConfiguration 中调用
+// step 为生成配置文件的步骤编号（即总步骤数）；opencode.json 写入失败时把 auth.json 恢复为写入前的内容，保证两个文件一致
+func writeAuthAndConfig(step int, writer *config.Writer, providerIDs []string, apiKey string, cfg *config.OpenCodeConfig) (authPath, configPath string, err error) {
+	authFile, err := config.GetAuthPath()
+	if err != nil {
+		return "", "", &writeFailure{codeAuthWriteFailed, i18n.T("main.auth_failed", err), err}
+	}
+	snapshot, err := config.SnapshotFile(authFile)
+	if err != nil {
+		return "", "", &writeFailure{codeAuthWriteFailed, i18n.T("main.auth_failed", err), err}
+	}
+	authPath, err = auth.NewAuthManager(providerIDs, apiKey).Login()
+	if err != nil {
+		return "", "", &writeFailure{codeAuthWriteFailed, i18n.T("main.auth_failed", err), err}
+	}
+	ui.PrintSuccess(i18n.T("main.auth_done", authPath))
+	result.AuthPath = authPath
+	ui.PrintDivider()
+
+	ui.PrintStep(step, step, i18n.T("step.config"))
+	configPath, err = writer.WriteConfig(cfg)
+	if err != nil {
+		if rbErr := snapshot.Restore(); rbErr != nil {
+			warn(i18n.T("main.auth_restore_failed", rbErr))
+		} else {
+			ui.PrintInfo(i18n.T("main.auth_restored"))
+			result.AuthPath = ""
+		}
+		return authPath, "", &writeFailure{codeConfigWriteFailed, i18n.T("main.config_failed", err), err}
+	}
+	ui.PrintSuccess(i18n.T("main.config_done", configPath))
+	ui.PrintDivider()
+	return authPath, configPath, nil
+}
+
+// failWrite 报告 writeConfiguration 返回的写入错误并退出
+func failWrite(err error) {
+	var wf *writeFailure
+	if errors.As(err, &wf) {
+		fail(wf.code, wf.message, wf.err)
+	}
+	fail(codeConfigWriteFailed, i18n.T("main.config_failed", err), err)
 }
 
 // runModelOnlyConfiguration 运行仅配置模型流程（3步）
-func runModelOnlyConfiguration(collector *input.Collector, existing *config.ExistingConfig) {
+// balance 为启动时查询到的额度信息（可能为 nil）
+func runModelOnlyConfiguration(collector *input.Collector, existing *config.ExistingConfig, balance *api.Balance) {
 	ui.PrintModelOnlyModeInfo()
+	// 保留现有配置中的自定义请求头
+	customHeaders = existing.Headers.Merge(cliHeaders)
 
 	// [1/3] 配置模型
-	ui.PrintStep(1, 3, "配置模型")
+	ui.PrintStep(1, 3, i18n.T("step.models"))
+	loadModelCatalog(collector, existing.URL, existing.APIKey)
 	models, err := collector.CollectModels()
 	if err != nil {
-		ui.PrintError(fmt.Sprintf("读取模型失败: %v", err))
-		waitForExit()
-		os.Exit(1)
+		fail(codeInputFailed, i18n.T("main.models_failed", err), err)
 	}
-	ui.PrintSuccess(fmt.Sprintf("已添加 %d 个模型", len(models)))
-	fmt.Println()
+	ui.PrintSuccess(i18n.T("main.models_added", len(models)))
+	tester := newTester(existing.URL, existing.APIKey)
+	var imageProbes []api.ImageProbe
+	if *flagImages {
+		imageProbes = probeImages(tester, models, config.ClassifyModel)
+	}
+	var reasoningProbes []api.ReasoningProbe
+	if *flagThink {
+		reasoningProbes = probeReasoning(tester, models, config.ClassifyModel)
+	}
+	ui.PrintDivider()
 
 	ui.PrintDivider()
-	ui.PrintInfo("正在写入配置文件...")
-	fmt.Println()
+	ui.PrintInfo(i18n.T("main.writing"))
+	ui.PrintDivider()
 
 	// [2/3] 更新认证信息
-	ui.PrintStep(2, 3, "更新认证信息")
+	ui.PrintStep(2, 3, i18n.T("step.auth_update"))
 	cfg := config.NewDMXAPIConfig(existing.URL, existing.APIKey, models)
-	providerIDs := config.GetProviderIDs(cfg)
-	authMgr := auth.NewAuthManager(providerIDs, existing.APIKey)
-	authPath, err := authMgr.Login()
-	if err != nil {
-		ui.PrintError(fmt.Sprintf("认证配置失败: %v", err))
-		waitForExit()
-		os.Exit(1)
-	}
-	ui.PrintSuccess(fmt.Sprintf("认证配置完成: %s", authPath))
-	fmt.Println()
-
-	// [3/3] 生成配置文件
-	ui.PrintStep(3, 3, "生成配置文件")
+	cfg.ApplyHeaders(customHeaders)
+	printHeaders()
+	applyImageProbes(cfg, imageProbes)
+	applyReasoningProbes(cfg, reasoningProbes)
+	result.Mode = "model_only"
+	result.URL = config.NormalizeBaseURL(existing.URL)
 	writer := config.NewWriter()
-	configPath, err := writer.WriteConfig(cfg)
+	providerIDs := resolveConflicts(collector, writer, cfg)
+	result.setProviders(cfg)
+	var authPath, configPath string
+	err = writeConfiguration(func() (err error) {
+		// [3/3] 生成配置文件
+		authPath, configPath, err = writeAuthAndConfig(3, writer, providerIDs, existing.APIKey, cfg)
+		return err
+	})
 	if err != nil {
-		ui.PrintError(fmt.Sprintf("写入配置失败: %v", err))
-		waitForExit()
-		os.Exit(1)
+		failWrite(err)
 	}
-	ui.PrintSuccess(fmt.Sprintf("配置文件已生成: %s", configPath))
-	fmt.Println()
 
 	ui.PrintDivider()
 	ui.PrintComplete()
 
-	fmt.Println("  配置摘要:")
-	fmt.Printf("    URL     %s\n", config.NormalizeBaseURL(existing.URL))
-	fmt.Printf("    模型    %s\n", strings.Join(models, ", "))
-	fmt.Printf("    配置    %s\n", configPath)
-	fmt.Printf("    认证    %s\n", authPath)
-	fmt.Println()
-	fmt.Println("  运行 'opencode' 启动程序")
+	result.ConfigPath = configPath
+	if !jsonOutput {
+		printSummary(existing.URL, models, cfg.Model, configPath, authPath, balance)
+	}
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/i18n"
+	"dmxapi-config/internal/input"
+	"dmxapi-config/internal/ui"
+)
+
+// runMigrate 执行 migrate 命令：把旧版本的单一 dmxapi provider 迁移为按类型拆分的 provider，返回进程退出码
+func runMigrate() int {
+	ui.PrintBanner()
+
+	m := config.NewReader().DetectLegacy()
+	if m == nil {
+		ui.PrintSuccess(i18n.T("migrate.none"))
+		return 0
+	}
+	printMigration(m)
+
+	collector := input.NewCollector()
+	if jsonOutput {
+		collector.SetOutput(os.Stderr)
+	}
+	ok, err := collector.ConfirmMigration(true)
+	if err != nil {
+		code := codeInputFailed
+		if errors.Is(err, input.ErrUserCancelled) {
+			code = codeCancelled
+		}
+		ui.PrintError(i18n.T("migrate.failed", err))
+		result.addError(code, err)
+		return 1
+	}
+	if !ok {
+		ui.PrintInfo(i18n.T("migrate.skipped"))
+		return 0
+	}
+	if err := applyMigration(m); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// offerMigration 配置向导开始前检测旧版本的 dmxapi provider，确认后先完成迁移
+// 非交互模式下不询问，只提示可以运行 migrate 命令
+func offerMigration(collector *input.Collector, reader *config.Reader) {
+	m := reader.DetectLegacy()
+	if m == nil {
+		return
+	}
+	printMigration(m)
+	ok, err := collector.ConfirmMigration(false)
+	if err != nil {
+		fail(codeInputFailed, i18n.T("migrate.failed", err), err)
+	}
+	if !ok {
+		warn(i18n.T("migrate.skipped"))
+		ui.PrintDivider()
+		return
+	}
+	if err := applyMigration(m); err != nil {
+		exit(1)
+	}
+	ui.PrintDivider()
+}
+
+// printMigration 展示迁移将进行的修改
+func printMigration(m *config.Migration) {
+	ui.PrintWarning(i18n.T("migrate.detected"))
+	ui.PrintInfo(i18n.T("migrate.plan"))
+	for _, mv := range m.Moves {
+		if mv.Duplicate {
+			ui.PrintInfo("  " + i18n.T("migrate.duplicate", mv.Model, mv.To))
+		} else {
+			ui.PrintInfo("  " + i18n.T("migrate.move", mv.Model, mv.To))
+		}
+	}
+	if m.Config != nil && m.Config.Model != "" {
+		ui.PrintInfo("  " + i18n.T("migrate.default_model", m.Config.Model))
+	}
+	if m.Config != nil && m.Config.SmallModel != "" {
+		ui.PrintInfo("  " + i18n.T("migrate.small_model", m.Config.SmallModel))
+	}
+	if m.LegacyProvider {
+		ui.PrintInfo("  " + i18n.T("migrate.remove_provider"))
+	}
+	if m.LegacyAuth {
+		ui.PrintInfo("  " + i18n.T("migrate.remove_auth"))
+	}
+	ui.PrintInfo(i18n.T("migrate.backup_note"))
+}
+
+// applyMigration 执行迁移并记录到结果文档，失败时已打印错误
+func applyMigration(m *config.Migration) error {
+	writer := config.NewWriter()
+	var configPath, authPath string
+	var err error
+	err = writeConfiguration(func() (err error) {
+		configPath, authPath, err = writer.Migrate(m)
+		return err
+	})
+	result.setMigration(m, writer.Backups())
+	if err != nil {
+		ui.PrintError(i18n.T("migrate.failed", err))
+		result.addError(codeMigrateFailed, err)
+		return err
+	}
+	if m.Config != nil {
+		result.setProviders(m.Config)
+	}
+	result.ConfigPath = configPath
+	result.AuthPath = authPath
+	ui.PrintSuccess(i18n.T("migrate.done", len(m.Moves), strings.Join(m.ProviderIDs(), ", ")))
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"dmxapi-config/internal/i18n"
+	"dmxapi-config/internal/mockserver"
+	"dmxapi-config/internal/ui"
+)
+
+// runMockServer 执行 mock-server 命令：在本地启动模拟 DMXAPI 网关，用于离线开发和演示
+// args 为命令名之后的参数（--addr、--key、--fault 等），按 Ctrl+C 停止，返回进程退出码
+func runMockServer(args []string) int {
+	fs := flag.NewFlagSet("mock-server", flag.ContinueOnError)
+	addr := fs.String("addr", "127.0.0.1:8089", "mock.flag_addr")
+	key := fs.String("key", "", "mock.flag_key")
+	models := fs.String("models", "", "mock.flag_models")
+	textOnly := fs.String("text-only", "", "mock.flag_text_only")
+	reasoning := fs.String("reasoning", "", "mock.flag_reasoning")
+	faultName := fs.String("fault", "", "mock.flag_fault")
+	faultCount := fs.Int("fault-count", 0, "mock.flag_fault_count")
+	delay := fs.Duration("delay", 5*time.Second, "mock.flag_delay")
+	var headers listFlag
+	fs.Var(&headers, "header", "mock.flag_header")
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintln(out, i18n.T("mock.usage"))
+		fs.VisitAll(func(f *flag.Flag) {
+			desc := i18n.T(f.Usage)
+			if f.Name == "fault" {
+				desc = i18n.T(f.Usage, strings.Join(mockserver.FaultNames(), ", "))
+			}
+			if f.DefValue != "" && f.DefValue != "0" {
+				desc += " " + i18n.T("usage.default", f.DefValue)
+			}
+			fmt.Fprintf(out, "  --%-13s%s\n", f.Name, desc)
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		result.addError(codeUsage, err)
+		return 2
+	}
+
+	fault, ok := mockserver.ParseFault(*faultName)
+	if !ok {
+		err := fmt.Errorf(i18n.T("mock.unknown_fault"), *faultName, strings.Join(mockserver.FaultNames(), ", "))
+		ui.PrintError(err.Error())
+		result.addError(codeUsage, err)
+		return 2
+	}
+	var required map[string]string
+	for _, spec := range headers {
+		name, value, ok := strings.Cut(spec, ":")
+		if !ok {
+			err := fmt.Errorf(i18n.T("headers.bad_format"), spec)
+			ui.PrintError(err.Error())
+			result.addError(codeUsage, err)
+			return 2
+		}
+		if required == nil {
+			required = make(map[string]string)
+		}
+		required[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	gw := mockserver.New(mockserver.Options{
+		APIKey:     *key,
+		Models:     splitList(*models),
+		TextOnly:   splitList(*textOnly),
+		Reasoning:  splitList(*reasoning),
+		Fault:      fault,
+		FaultCount: *faultCount,
+		Delay:      *delay,
+
+		RequireHeaders: required,
+	})
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		ui.PrintError(i18n.T("mock.listen_failed", *addr, err))
+		result.addError(codeMockServerFailed, err)
+		return 1
+	}
+
+	ui.PrintBanner()
+	url := "http://" + ln.Addr().String()
+	ui.PrintSuccess(i18n.T("mock.listening", url, len(gw.Models())))
+	if *key != "" {
+		ui.PrintInfo(i18n.T("mock.key_required", *key))
+	}
+	if fault != mockserver.FaultNone {
+		ui.PrintWarning(i18n.T("mock.fault_enabled", fault, *faultCount))
+	}
+	ui.PrintInfo(i18n.T("mock.try", url))
+	ui.PrintInfo(i18n.T("mock.stop"))
+
+	srv := &http.Server{Handler: gw, ReadHeaderTimeout: 10 * time.Second}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}()
+
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		ui.PrintError(i18n.T("mock.listen_failed", *addr, err))
+		result.addError(codeMockServerFailed, err)
+		return 1
+	}
+	ui.PrintInfo(i18n.T("mock.stopped", gw.Requests()))
+	return 0
+}
+
+// splitList 拆分逗号分隔的列表，忽略空项
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
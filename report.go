@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"dmxapi-config/internal/api"
+	"dmxapi-config/internal/config"
+	"dmxapi-config/internal/input"
+	"dmxapi-config/internal/ui"
+)
+
+// 非 API 测试类错误的错误代码（API 测试错误沿用 api.ErrorCode）
+const (
+	codeUsage             = "usage_error"
+	codeCancelled         = "cancelled"
+	codeInputFailed       = "input_failed"
+	codeOpencodeMissing   = "opencode_missing"
+	codeAuthWriteFailed   = "auth_write_failed"
+	codeConfigWriteFailed = "config_write_failed"
+	codeUpdateCheckFailed = "update_check_failed"
+	codeUpdateFailed      = "update_failed"
+	codeSupportFailed     = "support_failed"
+	codeTemplateInvalid   = "template_invalid"
+	codeExportFailed      = "export_failed"
+	codeMockServerFailed  = "mock_server_failed"
+	codeDashboardFailed   = "dashboard_failed"
+	codeMigrateFailed     = "migrate_failed"
+	codeConflict          = "conflict_unresolved"
+)
+
+// report JSON 输出模式下的结果文档，程序结束时一次性输出到标准输出
+type report struct {
+	Command    string           `json:"command"`
+	Version    string           `json:"version"`
+	Success    bool             `json:"success"`
+	Mode       string           `json:"mode,omitempty"`
+	Template   string           `json:"template,omitempty"`
+	Namespace  string           `json:"namespace,omitempty"` // 非默认命名空间时为 provider ID 前缀
+	URL        string           `json:"url,omitempty"`
+	ConfigPath string           `json:"config_path,omitempty"`
+	AuthPath   string           `json:"auth_path,omitempty"`
+	BundlePath string           `json:"bundle_path,omitempty"`
+	Providers  []reportProvider `json:"providers,omitempty"`
+	Default    string           `json:"default_model,omitempty"`
+	Small      string           `json:"small_model,omitempty"`
+	Tests      []reportTest     `json:"tests,omitempty"`
+	Images     []reportImage    `json:"image_probes,omitempty"`
+	Reasoning  []reportThinking `json:"reasoning_probes,omitempty"`
+	Balance    *reportBalance   `json:"balance,omitempty"`
+	Migration  *reportMigration `json:"migration,omitempty"`
+	Conflicts  *reportConflicts `json:"conflicts,omitempty"`
+	Update     *reportUpdate    `json:"update,omitempty"`
+	Warnings   []string         `json:"warnings"`
+	Errors     []reportError    `json:"errors"`
+}
+
+// reportProvider 写入配置的 provider 分组
+type reportProvider struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	NPM     string   `json:"npm"`
+	BaseURL string   `json:"base_url"`
+	Models  []string `json:"models"`
+	Headers []string `json:"headers,omitempty"` // 自定义请求头名称（取值可能包含令牌，不输出）
+}
+
+// reportTest 单个模型的连接测试结果
+type reportTest struct {
+	Model      string            `json:"model"`
+	Provider   string            `json:"provider"`
+	Success    bool              `json:"success"`
+	Auth       string            `json:"auth,omitempty"`
+	Attempts   int               `json:"attempts"`
+	DurationMS int64             `json:"duration_ms"`
+	Error      *reportError      `json:"error,omitempty"`
+	AuthProbes []reportAuthProbe `json:"auth_probes,omitempty"`
+}
+
+// reportAuthProbe 一种认证方式的测试结果（--probe-auth 或认证失败时）
+type reportAuthProbe struct {
+	Auth     string `json:"auth"`
+	Native   bool   `json:"native"`
+	Accepted bool   `json:"accepted"`
+}
+
+// reportImage 单个模型的图片输入探测结果（--probe-images）
+type reportImage struct {
+	Model    string       `json:"model"`
+	Provider string       `json:"provider"`
+	Image    string       `json:"image"`            // supported / unsupported / unknown
+	Reason   string       `json:"reason,omitempty"` // 网关拒绝图片的原因（unsupported）
+	Error    *reportError `json:"error,omitempty"`  // 探测失败的原因（unknown）
+}
+
+// reportThinking 单个模型的推理输出探测结果（--probe-reasoning）
+type reportThinking struct {
+	Model     string       `json:"model"`
+	Provider  string       `json:"provider"`
+	Reasoning string       `json:"reasoning"`        // supported / none / unknown
+	Tokens    int          `json:"tokens,omitempty"` // 响应中报告的推理 token 数
+	Reason    string       `json:"reason,omitempty"` // 网关拒绝思考参数的原因（none）
+	Error     *reportError `json:"error,omitempty"`  // 探测失败的原因（unknown）
+}
+
+// reportMigration 旧版本单一 dmxapi provider 的迁移结果
+type reportMigration struct {
+	Moves       []reportMove `json:"moves"`
+	RemovedAuth bool         `json:"removed_auth"`      // 是否删除了 auth.json 中的旧认证条目
+	Backups     []string     `json:"backups,omitempty"` // 修改前创建的备份文件
+}
+
+// reportMove 迁移时一个模型的去向
+type reportMove struct {
+	Model     string `json:"model"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Duplicate bool   `json:"duplicate,omitempty"` // 目标 provider 中已有该模型，只删除旧条目
+}
+
+// reportConflicts 写入前发现的与现有配置的冲突及处理方式
+type reportConflicts struct {
+	Resolution string           `json:"resolution"` // keep / dmxapi / existing / cancel
+	Items      []reportConflict `json:"items"`
+}
+
+// reportConflict 一处冲突
+type reportConflict struct {
+	Kind     string `json:"kind"` // model / provider / auth
+	ID       string `json:"id"`
+	Provider string `json:"provider"`
+	Other    string `json:"other"`
+}
+
+// reportBalance 账户额度
+type reportBalance struct {
+	TotalUSD     float64    `json:"total_usd"`
+	UsedUSD      float64    `json:"used_usd"`
+	RemainingUSD float64    `json:"remaining_usd"`
+	Unlimited    bool       `json:"unlimited"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+}
+
+// reportUpdate 版本更新信息
+type reportUpdate struct {
+	CurrentVersion string `json:"current_version"`
+	LatestVersion  string `json:"latest_version,omitempty"`
+	HasUpdate      bool   `json:"has_update"`
+	Updated        bool   `json:"updated,omitempty"`
+	Path           string `json:"path,omitempty"`
+	DownloadURL    string `json:"download_url,omitempty"`
+}
+
+// reportError 带错误代码的错误信息
+type reportError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// jsonOutput 是否为 JSON 输出模式
+var jsonOutput bool
+
+// result 本次运行的结果文档（文本模式下同样记录，但不输出）
+var result = &report{
+	Command:  "configure",
+	Version:  ui.Version,
+	Warnings: []string{},
+	Errors:   []reportError{},
+}
+
+// newReportError 将错误转换为结果文档中的错误条目
+// API 测试错误使用其自身的错误代码和处理建议，用户取消统一记为 cancelled；
+// 其余错误使用 code，code 为空时记为 unknown
+func newReportError(code string, err error) *reportError {
+	e := &reportError{Code: code, Message: err.Error()}
+	switch te, ok := api.AsTestError(err); {
+	case ok:
+		e.Code = string(te.Code)
+		e.Hint = te.Hint()
+	case errors.Is(err, input.ErrUserCancelled):
+		e.Code = codeCancelled
+	case code == "":
+		e.Code = string(api.ErrCodeUnknown)
+	}
+	return e
+}
+
+// addError 记录错误
+func (r *report) addError(code string, err error) {
+	r.Errors = append(r.Errors, *newReportError(code, err))
+}
+
+// addWarning 记录警告
+func (r *report) addWarning(message string) {
+	r.Warnings = append(r.Warnings, message)
+}
+
+// setProviders 按 provider ID 排序记录配置中的 provider 分组
+func (r *report) setProviders(cfg *config.OpenCodeConfig) {
+	r.Providers = nil
+	for id, p := range cfg.Provider {
+		models := make([]string, 0, len(p.Models))
+		for m := range p.Models {
+			models = append(models, m)
+		}
+		sort.Strings(models)
+		r.Providers = append(r.Providers, reportProvider{
+			ID:      id,
+			Name:    p.Name,
+			NPM:     p.NPM,
+			BaseURL: p.Options.BaseURL,
+			Models:  models,
+			Headers: slices.Sorted(maps.Keys(p.Options.Headers)),
+		})
+	}
+	sort.Slice(r.Providers, func(i, j int) bool { return r.Providers[i].ID < r.Providers[j].ID })
+	r.Default = cfg.Model
+	r.Small = cfg.SmallModel
+}
+
+// addTest 记录一次连接测试结果，tr 可能为 nil（测试未能发出请求）
+func (r *report) addTest(model string, tr *api.TestResult, err error) {
+	t := reportTest{
+		Model:    model,
+		Provider: config.GetProviderInfo(config.ClassifyModel(model)).ID,
+		Success:  err == nil,
+	}
+	if tr != nil {
+		t.Provider = config.GetProviderInfo(tr.Provider).ID
+		t.Auth = tr.Auth.String()
+		t.Attempts = tr.Attempts
+		t.DurationMS = tr.Duration.Milliseconds()
+	}
+	if err != nil {
+		t.Error = newReportError("", err)
+	}
+	r.Tests = append(r.Tests, t)
+}
+
+// setAuthProbes 将认证方式测试结果附加到最近一次连接测试
+func (r *report) setAuthProbes(probes []api.AuthProbe) {
+	if len(r.Tests) == 0 {
+		return
+	}
+	t := &r.Tests[len(r.Tests)-1]
+	for _, p := range probes {
+		t.AuthProbes = append(t.AuthProbes, reportAuthProbe{Auth: p.Style.String(), Native: p.Native, Accepted: p.Accepted()})
+	}
+}
+
+// addImageProbe 记录图片输入探测结果
+func (r *report) addImageProbe(p api.ImageProbe) {
+	img := reportImage{
+		Model:    p.Model,
+		Provider: config.GetProviderInfo(p.Provider).ID,
+		Image:    p.Support.String(),
+	}
+	switch {
+	case p.Support == api.ImageUnsupported:
+		img.Reason = p.Err.Error()
+	case p.Err != nil:
+		img.Error = newReportError("", p.Err)
+	}
+	r.Images = append(r.Images, img)
+}
+
+// addReasoningProbe 记录推理输出探测结果
+func (r *report) addReasoningProbe(p api.ReasoningProbe) {
+	t := reportThinking{
+		Model:     p.Model,
+		Provider:  config.GetProviderInfo(p.Provider).ID,
+		Reasoning: p.Support.String(),
+		Tokens:    p.Tokens,
+	}
+	switch {
+	case p.Support == api.ReasoningNone && p.Err != nil:
+		t.Reason = p.Err.Error()
+	case p.Err != nil:
+		t.Error = newReportError("", p.Err)
+	}
+	r.Reasoning = append(r.Reasoning, t)
+}
+
+// setBalance 记录账户额度，balance 为 nil 时不记录
+func (r *report) setBalance(balance *api.Balance) {
+	if balance == nil {
+		return
+	}
+	r.Balance = &reportBalance{
+		TotalUSD:     balance.TotalUSD,
+		UsedUSD:      balance.UsedUSD,
+		RemainingUSD: balance.RemainingUSD,
+		Unlimited:    balance.Unlimited,
+	}
+	if !balance.ExpiresAt.IsZero() {
+		expires := balance.ExpiresAt
+		r.Balance.ExpiresAt = &expires
+	}
+}
+
+// setMigration 记录迁移结果
+func (r *report) setMigration(m *config.Migration, backups []string) {
+	r.Migration = &reportMigration{Moves: []reportMove{}, RemovedAuth: m.LegacyAuth, Backups: backups}
+	for _, mv := range m.Moves {
+		r.Migration.Moves = append(r.Migration.Moves, reportMove{Model: mv.Model, From: config.LegacyProviderID(), To: mv.To, Duplicate: mv.Duplicate})
+	}
+}
+
+// setConflicts 记录写入前发现的冲突和选择的处理方式
+func (r *report) setConflicts(conflicts []config.Conflict, resolution config.ConflictResolution) {
+	r.Conflicts = &reportConflicts{Resolution: string(resolution)}
+	for _, c := range conflicts {
+		r.Conflicts.Items = append(r.Conflicts.Items, reportConflict{Kind: string(c.Kind), ID: c.ID, Provider: c.Provider, Other: c.Other})
+	}
+}
+
+// finish 根据退出码和已记录的错误确定整体结果
+func (r *report) finish(exitCode int) {
+	r.Success = exitCode == 0 && len(r.Errors) == 0
+}
+
+// emit 在 JSON 输出模式下将结果文档写到标准输出
+func (r *report) emit(exitCode int) {
+	if !jsonOutput {
+		return
+	}
+	r.finish(exitCode)
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	enc.Encode(r)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"dmxapi-config/internal/api"
+	"dmxapi-config/internal/input"
+	"dmxapi-config/internal/mockserver"
+)
+
+const (
+	// mainArgsEnv 子进程中传给 main 的命令行参数（以换行分隔），见 TestMainProcess
+	mainArgsEnv = "DMXAPI_TEST_MAIN_ARGS"
+	// testKey 模拟网关接受的 API Key
+	testKey = "sk-reporttestkey1234567890"
+)
+
+func TestNewReportError(t *testing.T) {
+	authErr := &api.TestError{Code: api.ErrCodeAuthInvalid, StatusCode: 401, Message: "invalid api key"}
+	tests := []struct {
+		name     string
+		code     string
+		err      error
+		wantCode string
+		wantHint bool
+	}{
+		{"test error", codeConfigWriteFailed, authErr, string(api.ErrCodeAuthInvalid), true},
+		{"wrapped test error", "", fmt.Errorf("step 4: %w", authErr), string(api.ErrCodeAuthInvalid), true},
+		{"cancelled", codeInputFailed, input.ErrUserCancelled, codeCancelled, false},
+		{"wrapped cancel", codeInputFailed, fmt.Errorf("api key: %w", input.ErrUserCancelled), codeCancelled, false},
+		{"own code", codeAuthWriteFailed, errors.New("permission denied"), codeAuthWriteFailed, false},
+		{"no code", "", errors.New("boom"), string(api.ErrCodeUnknown), false},
+	}
+	for _, tt := range tests {
+		e := newReportError(tt.code, tt.err)
+		if e.Code != tt.wantCode || e.Message != tt.err.Error() || (e.Hint != "") != tt.wantHint {
+			t.Errorf("%s: newReportError = %+v, want code %q, message %q, hint %v", tt.name, e, tt.wantCode, tt.err.Error(), tt.wantHint)
+		}
+	}
+}
+
+// jsonKeys 返回 JSON 对象的键（按名称排序）
+func jsonKeys(t *testing.T, data []byte) []string {
+	t.Helper()
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("not a JSON object: %v\n%s", err, data)
+	}
+	return slices.Sorted(maps.Keys(m))
+}
+
+func TestReportFieldNames(t *testing.T) {
+	// 空结果文档只有必填字段，warnings 和 errors 输出为空数组而不是 null
+	r := &report{Command: "configure", Version: "1.0.0", Warnings: []string{}, Errors: []reportError{}}
+	data, _ := json.Marshal(r)
+	if got, want := jsonKeys(t, data), []string{"command", "errors", "success", "version", "warnings"}; !slices.Equal(got, want) {
+		t.Errorf("empty report keys = %v, want %v", got, want)
+	}
+	if !bytes.Contains(data, []byte(`"warnings":[]`)) || !bytes.Contains(data, []byte(`"errors":[]`)) {
+		t.Errorf("empty report = %s, want empty warnings and errors arrays", data)
+	}
+
+	testErr := &api.TestError{Code: api.ErrCodeModelNotFound, StatusCode: 404, Message: "no such model"}
+	r.addTest("gpt-5", &api.TestResult{Attempts: 1}, testErr)
+	r.addError("", testErr)
+	data, _ = json.Marshal(r.Tests[0])
+	if got, want := jsonKeys(t, data), []string{"attempts", "auth", "duration_ms", "error", "model", "provider", "success"}; !slices.Equal(got, want) {
+		t.Errorf("test entry keys = %v, want %v", got, want)
+	}
+	data, _ = json.Marshal(r.Errors[0])
+	if got, want := jsonKeys(t, data), []string{"code", "hint", "message"}; !slices.Equal(got, want) {
+		t.Errorf("error entry keys = %v, want %v", got, want)
+	}
+	if r.Errors[0].Code != string(api.ErrCodeModelNotFound) {
+		t.Errorf("error code = %q, want %q", r.Errors[0].Code, api.ErrCodeModelNotFound)
+	}
+}
+
+// TestMainProcess 在子进程中运行 main（main 通过 os.Exit 结束进程），参数来自 mainArgsEnv
+func TestMainProcess(t *testing.T) {
+	args := os.Getenv(mainArgsEnv)
+	if args == "" {
+		t.Skip("only runs as a subprocess of TestJSONOutput")
+	}
+	os.Args = append([]string{os.Args[0]}, strings.Split(args, "\n")...)
+	main()
+}
+
+// runMain 在子进程中以指定参数和标准输入运行 main，返回退出码、标准输出和标准错误
+func runMain(t *testing.T, home, stdin string, args ...string) (int, []byte, []byte) {
+	t.Helper()
+	// 模拟已安装的 opencode
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "opencode"), []byte("#!/bin/sh\necho 1.0.0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMainProcess$")
+	cmd.Env = append(os.Environ(),
+		mainArgsEnv+"="+strings.Join(args, "\n"),
+		"HOME="+home, "USERPROFILE="+home,
+		"PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"DMXAPI_NO_UPDATE_CHECK=1",
+	)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		return exitErr.ExitCode(), stdout.Bytes(), stderr.Bytes()
+	case err != nil:
+		t.Fatal(err)
+	}
+	return 0, stdout.Bytes(), stderr.Bytes()
+}
+
+// decodeReport 解析标准输出，要求其中只有一个 JSON 结果文档（前后不能有空行等其他输出）
+func decodeReport(t *testing.T, stdout []byte) report {
+	t.Helper()
+	if !bytes.HasPrefix(stdout, []byte("{")) {
+		t.Fatalf("stdout has output before the report:\n%s", stdout)
+	}
+	dec := json.NewDecoder(bytes.NewReader(stdout))
+	dec.DisallowUnknownFields()
+	var r report
+	if err := dec.Decode(&r); err != nil {
+		t.Fatalf("stdout is not a report: %v\n%s", err, stdout)
+	}
+	if rest := string(stdout[dec.InputOffset():]); rest != "\n" {
+		t.Fatalf("stdout has output after the report: %q", rest)
+	}
+	return r
+}
+
+func TestJSONOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake opencode executable is a shell script")
+	}
+	srv := httptest.NewServer(mockserver.New(mockserver.Options{APIKey: testKey}))
+	t.Cleanup(srv.Close)
+	tplPath := filepath.Join(t.TempDir(), "team.json")
+	tpl := fmt.Sprintf(`{"url": %q, "models": ["gpt-5", "claude-sonnet-4-5"], "default_model": "gpt-5"}`, srv.URL)
+	if err := os.WriteFile(tplPath, []byte(tpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		stdin    string
+		exitCode int
+		success  bool
+		errCode  string
+	}{
+		{"success", testKey + "\n", 0, true, ""},
+		{"auth failure", "sk-wrongkey1234567890abcd\n", 1, false, string(api.ErrCodeAuthInvalid)},
+		{"no input", "", 1, false, codeInputFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			code, stdout, stderr := runMain(t, home, tt.stdin, "--output", "json", "--template", tplPath)
+			if code != tt.exitCode {
+				t.Fatalf("exit code = %d, want %d\nstderr:\n%s", code, tt.exitCode, stderr)
+			}
+			r := decodeReport(t, stdout)
+			if r.Success != tt.success || r.Command != "configure" || r.Template != tplPath {
+				t.Errorf("report = success %v, command %q, template %q", r.Success, r.Command, r.Template)
+			}
+			if tt.errCode == "" {
+				if len(r.Errors) != 0 || len(r.Providers) != 2 || r.Default == "" || r.ConfigPath == "" {
+					t.Errorf("report = %+v, want two providers, a default model and no errors", r)
+				}
+				return
+			}
+			if len(r.Errors) == 0 || r.Errors[0].Code != tt.errCode {
+				t.Errorf("errors = %+v, want code %q", r.Errors, tt.errCode)
+			}
+		})
+	}
+}
+
+func TestTemplateChecks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake opencode executable is a shell script")
+	}
+	srv := httptest.NewServer(mockserver.New(mockserver.Options{APIKey: testKey}))
+	t.Cleanup(srv.Close)
+	closed := httptest.NewServer(mockserver.New(mockserver.Options{}))
+	closed.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		models  string
+		flags   []string
+		errCode string
+	}{
+		{"known models", srv.URL, `["gpt-5", "claude-sonnet-4-5"]`, nil, ""},
+		{"unknown model", srv.URL, `["gpt-5", "gpt-5x"]`, nil, codeTemplateInvalid},
+		{"unknown model forced", srv.URL, `["gpt-5", "gpt-5x"]`, []string{"--force-models"}, ""},
+		{"unknown model offline", srv.URL, `["gpt-5", "gpt-5x"]`, []string{"--offline"}, codeTemplateInvalid},
+		{"unreachable url", closed.URL, `["gpt-5"]`, nil, string(api.ErrCodeNetwork)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tplPath := filepath.Join(t.TempDir(), "team.json")
+			tpl := fmt.Sprintf(`{"url": %q, "models": %s}`, tt.url, tt.models)
+			if err := os.WriteFile(tplPath, []byte(tpl), 0644); err != nil {
+				t.Fatal(err)
+			}
+			args := append([]string{"--output", "json", "--template", tplPath}, tt.flags...)
+			code, stdout, stderr := runMain(t, t.TempDir(), testKey+"\n", args...)
+			r := decodeReport(t, stdout)
+			if tt.errCode == "" {
+				if code != 0 || !r.Success {
+					t.Errorf("exit code = %d, errors = %+v, want success\nstderr:\n%s", code, r.Errors, stderr)
+				}
+				return
+			}
+			if code != 1 || len(r.Errors) == 0 || r.Errors[0].Code != tt.errCode {
+				t.Errorf("exit code = %d, errors = %+v, want exit 1 with code %q", code, r.Errors, tt.errCode)
+			}
+			if r.ConfigPath != "" {
+				t.Errorf("config written to %s despite a failed check", r.ConfigPath)
+			}
+		})
+	}
+}
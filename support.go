@@ -0,0 +1,34 @@
+package main
+
+import (
+	"path/filepath"
+	"time"
+
+	"dmxapi-config/internal/i18n"
+	"dmxapi-config/internal/support"
+	"dmxapi-config/internal/ui"
+)
+
+// runSupport 执行 support 命令：将诊断信息打包为 zip 归档，便于附在工单中
+// dest 为空时在当前目录生成带时间戳的文件名，返回进程退出码
+func runSupport(dest string) int {
+	ui.PrintBanner()
+	if dest == "" {
+		dest = support.DefaultBundleName(time.Now())
+	}
+	if abs, err := filepath.Abs(dest); err == nil {
+		dest = abs
+	}
+
+	ui.PrintInfo(i18n.T("support.collecting"))
+	if err := support.CreateBundle(dest); err != nil {
+		ui.PrintError(i18n.T("support.failed", err))
+		result.addError(codeSupportFailed, err)
+		return 1
+	}
+
+	result.BundlePath = dest
+	ui.PrintSuccess(i18n.T("support.done", dest))
+	ui.PrintInfo(i18n.T("support.keys_masked"))
+	return 0
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"dmxapi-config/internal/i18n"
+	"dmxapi-config/internal/ui"
+)
+
+// runUpdate 执行 update 命令：下载当前平台的最新版本并替换正在运行的程序
+// 返回进程退出码
+func runUpdate(channel ui.Channel) int {
+	ui.PrintBanner()
+	ui.PrintInfo(i18n.T("update.checking", channel))
+
+	release, err := ui.FetchLatestRelease(channel)
+	if err != nil {
+		ui.PrintError(i18n.T("update.check_failed", err))
+		result.addError(codeUpdateCheckFailed, err)
+		return 1
+	}
+
+	v, _ := release.Version()
+	latest := v.String()
+	result.Update = &reportUpdate{CurrentVersion: ui.Version, LatestVersion: latest}
+	if !ui.IsNewerThanCurrent(release) {
+		ui.PrintSuccess(i18n.T("update.up_to_date", ui.Version))
+		return 0
+	}
+
+	result.Update.HasUpdate = true
+	ui.PrintInfo(i18n.T("update.downloading", latest, ui.Version))
+	ui.PrintReleaseNotes(release.NotesExcerpt(5))
+	exePath, err := ui.SelfUpdate(release)
+	if err != nil {
+		ui.PrintError(i18n.T("update.failed", err))
+		ui.PrintInfo(i18n.T("update.manual", ui.ReleasesPageURL))
+		result.addError(codeUpdateFailed, err)
+		result.Update.DownloadURL = ui.ReleasesPageURL
+		return 1
+	}
+
+	ui.PrintSuccess(i18n.T("update.done", latest, exePath))
+	result.Update.Updated = true
+	result.Update.Path = exePath
+	return 0
+}